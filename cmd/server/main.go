@@ -1,82 +1,222 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
-	"github.com/joho/godotenv"
 	"github.com/julienschmidt/httprouter"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 	"github.com/wndisra/galactic-svc/docs"
-	"github.com/wndisra/galactic-svc/internal/entity"
+	"github.com/wndisra/galactic-svc/internal/config"
+	"github.com/wndisra/galactic-svc/internal/health"
+	"github.com/wndisra/galactic-svc/internal/helpers"
+	"github.com/wndisra/galactic-svc/internal/logging"
+	"github.com/wndisra/galactic-svc/internal/metrics"
+	"github.com/wndisra/galactic-svc/internal/migrate"
 	"github.com/wndisra/galactic-svc/internal/repository/database"
 	"github.com/wndisra/galactic-svc/internal/spaceship"
+	"github.com/wndisra/galactic-svc/internal/tracing"
+	"github.com/wndisra/galactic-svc/internal/weapon"
 )
 
 // @title Galactic Service APIs
 // @description The server APIs documentation for Galactic.
 func main() {
-	// Init logger
-	var logger log.Logger
-	logger = log.NewJSONLogger(os.Stdout)
-	logger = log.With(logger, "ts", log.DefaultTimestampUTC)
-	logger = log.With(logger, "caller", log.DefaultCaller)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
 
-	// Load env
-	err := godotenv.Load()
+	// Load config
+	cfg, err := config.Load(os.Args[1:])
 	if err != nil {
-		level.Error(logger).Log("msg", "failed to load env")
+		log.NewJSONLogger(os.Stderr).Log("level", "error", "msg", "invalid config", "err", err)
 		os.Exit(1)
 	}
 
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
+	// Init logger
+	logger := newLogger(cfg.Log)
+	level.Info(logger).Log(append([]interface{}{"msg", "config loaded"}, cfg.Redacted()...)...)
 
-	// Init DB
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local", dbUser, dbPassword, dbHost, dbPort, dbName)
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	// Tracing, continuing the traces of the callers
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
 	if err != nil {
-		level.Error(logger).Log("msg", "failed to connect to database")
+		level.Error(logger).Log("msg", "failed to set tracing up", "err", err)
+		os.Exit(1)
+	}
+
+	// Init DB, waiting for it to come up
+	db, err := database.Open(context.Background(), cfg.DB, logger)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	dbHealth := database.NewHealth(db, cfg.DB.PingTimeout)
+
+	if err := db.Use(tracing.GormPlugin()); err != nil {
+		level.Error(logger).Log("msg", "failed to trace database queries", "err", err)
 		os.Exit(1)
 	}
 
-	// Migrate database
-	db.AutoMigrate(&entity.SpaceShip{}, &entity.Armament{})
+	// Metrics of the endpoints, queries and connection pool
+	var svcMetrics *metrics.Metrics
+	if cfg.Features.Metrics {
+		svcMetrics = metrics.New()
+		svcMetrics.RegisterPool(dbHealth.Stats)
+		if err := db.Use(svcMetrics.GormPlugin()); err != nil {
+			level.Error(logger).Log("msg", "failed to instrument database queries", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	// Migrate database, replicas taking turns
+	migrator := migrate.New(db, logger)
+	if cfg.DB.MigrateOnStart {
+		if err := migrator.Up(context.Background()); err != nil {
+			os.Exit(1)
+		}
+	}
 
-	dbRepo := database.NewRepository(db, logger)
-	spaceShipSvc := spaceship.NewService(dbRepo, logger)
+	// Repository calls are bounded by their request and by a query timeout
+	timeouts := database.NewQueryTimeouts(cfg.DB)
+	dbRepo := database.NewRepository(db, timeouts, logger)
+	armamentRepo := database.NewArmamentRepository(db, timeouts, logger)
+	weaponRepo := database.NewWeaponRepository(db, timeouts, logger)
+	spaceShipSvc := spaceship.NewTracingService(spaceship.NewService(dbRepo, armamentRepo, weaponRepo, logger))
+	weaponSvc := weapon.NewTracingService(weapon.NewService(weaponRepo, logger))
+
+	// Responses to requests bearing an Idempotency-Key are replayed for
+	// IdempotencyTTL, unless the feature is off. Their keys are reserved for
+	// as long as a response may take, WriteTimeout.
+	var idempotency *helpers.Idempotency
+	if cfg.Features.Idempotency {
+		idempotencyRepo := database.NewIdempotencyRepository(db, timeouts, logger)
+		idempotency = helpers.NewIdempotency(idempotencyRepo, cfg.Features.IdempotencyTTL, cfg.HTTP.WriteTimeout)
+	}
 
 	// Init router
 	router := httprouter.New()
 	docs.SwaggerInfo.BasePath = "/"
 
-	// Register routes
-	router.GET("/", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-		fmt.Fprintf(w, "You're alive!")
-	})
-	router.GET("/ping", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-		fmt.Fprintf(w, "Pong!")
-	})
+	// Health probes
+	checker := health.NewChecker(cfg.HTTP.ReadinessTimeout)
+	checker.Register("database", dbHealth.Ready)
+	checker.Register("migrations", migrator.Check)
+
+	router.Handler(http.MethodGet, "/healthz", checker.LivenessHandler())
+	router.Handler(http.MethodGet, "/readyz", checker.ReadinessHandler())
+
+	if svcMetrics != nil {
+		router.Handler(http.MethodGet, "/metrics", svcMetrics.Handler())
+	}
 
 	// Spaceships routes
-	spaceship.RegisterRoutes(router, spaceShipSvc)
+	spaceship.RegisterRoutes(router, spaceShipSvc, cfg.Auth.AdminToken, idempotency, svcMetrics)
+
+	// Weapons routes
+	weapon.RegisterRoutes(router, weaponSvc)
 
-	// Swagger documentation
-	// TODO: enable for development env, disable for production env
-	router.GET("/swagger/*any", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-		httpSwagger.WrapHandler(w, r)
-	})
+	// Swagger documentation, off in production through FEATURE_SWAGGER
+	if cfg.Features.Swagger {
+		router.GET("/swagger/*any", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+			httpSwagger.WrapHandler(w, r)
+		})
+	}
+
+	// Init server, logging every request with its X-Request-ID
+	server := newServer(cfg.HTTP, logging.Handler(router, logger, cfg.Log))
 
 	// Listen & serve request
-	level.Info(logger).Log("msg", "server started successfully")
-	http.ListenAndServe(":3000", router)
+	serverErr := make(chan error, 1)
+	go func() {
+		level.Info(logger).Log("msg", "server started successfully", "addr", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	exitCode := 0
+	select {
+	case err := <-serverErr:
+		level.Error(logger).Log("msg", "failed to serve requests", "err", err)
+		exitCode = 1
+	case sig := <-stop:
+		level.Info(logger).Log("msg", "shutting down", "signal", sig.String())
+
+		// Readiness fails first, giving load balancers ShutdownDelay to stop
+		// routing new requests here.
+		checker.Shutdown()
+		time.Sleep(cfg.HTTP.ShutdownDelay)
+
+		// In-flight requests are drained, up to the shutdown timeout.
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(ctx); err != nil {
+			level.Error(logger).Log("msg", "failed to drain in-flight requests", "err", err)
+			exitCode = 1
+		}
+	}
+
+	// Close DB
+	stats := dbHealth.Stats()
+	level.Info(logger).Log("msg", "database pool stats", "open", stats.OpenConnections, "in_use", stats.InUse, "idle", stats.Idle, "wait_count", stats.WaitCount, "wait_duration", stats.WaitDuration)
+
+	sqlDB, err := db.DB()
+	if err == nil {
+		err = sqlDB.Close()
+	}
+
+	if err != nil {
+		level.Error(logger).Log("msg", "failed to close database connections", "err", err)
+		exitCode = 1
+	}
+
+	// Flush the pending spans
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+
+	if err := shutdownTracing(ctx); err != nil {
+		level.Error(logger).Log("msg", "failed to flush traces", "err", err)
+		exitCode = 1
+	}
+
+	level.Info(logger).Log("msg", "server stopped")
+	os.Exit(exitCode)
+}
+
+// newLogger builds the service logger, dropping the entries below cfg.Level.
+func newLogger(cfg config.LogConfig) log.Logger {
+	var logger log.Logger
+	if cfg.Format == "logfmt" {
+		logger = log.NewLogfmtLogger(os.Stdout)
+	} else {
+		logger = log.NewJSONLogger(os.Stdout)
+	}
+
+	logger = level.NewFilter(logger, level.Allow(level.ParseDefault(cfg.Level, level.InfoValue())))
+	logger = log.With(logger, "ts", log.DefaultTimestampUTC)
+	logger = log.With(logger, "caller", log.DefaultCaller)
+
+	return logger
+}
+
+// newServer sets an HTTP server up from cfg.
+func newServer(cfg config.HTTPConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
+	"github.com/wndisra/galactic-svc/internal/config"
+	"github.com/wndisra/galactic-svc/internal/migrate"
+	"github.com/wndisra/galactic-svc/internal/repository/database"
+)
+
+const migrateUsage = "usage: galactic-svc migrate up|down [steps]|status [flags]"
+
+// runMigrate runs the migrate subcommand and returns its exit code:
+//
+//	galactic-svc migrate up           applies every pending migration
+//	galactic-svc migrate down [steps] rolls the last steps migrations back, 1 by default
+//	galactic-svc migrate status       lists the migrations and whether they are applied
+//
+// The config flags of the server follow.
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	action, args := args[0], args[1:]
+
+	steps := 1
+	if action == "down" && len(args) > 0 {
+		if n, err := strconv.Atoi(args[0]); err == nil {
+			steps, args = n, args[1:]
+		}
+	}
+
+	if (action != "up" && action != "down" && action != "status") || steps < 1 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	cfg, err := config.Load(args)
+	if err != nil {
+		log.NewJSONLogger(os.Stderr).Log("level", "error", "msg", "invalid config", "err", err)
+		return 1
+	}
+
+	logger := newLogger(cfg.Log)
+	ctx := context.Background()
+
+	db, err := database.Open(ctx, cfg.DB, logger)
+	if err != nil {
+		return 1
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	migrator := migrate.New(db, logger)
+
+	switch action {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx, steps)
+	case "status":
+		err = printStatus(ctx, migrator)
+	}
+
+	if err != nil {
+		level.Error(logger).Log("msg", "failed to migrate", "action", action, "err", err)
+		return 1
+	}
+
+	return 0
+}
+
+func printStatus(ctx context.Context, migrator *migrate.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range statuses {
+		name, appliedAt := s.Name, "pending"
+		if name == "" {
+			name = "(unknown to this build)"
+		}
+
+		if s.Applied {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, name, appliedAt)
+	}
+
+	return w.Flush()
+}
@@ -25,9 +25,852 @@ const docTemplate = `{
                 "tags": [
                     "Spaceship"
                 ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Partial match on the spaceship name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exact class, comma separated for several classes",
+                        "name": "class",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exact status, comma separated for several statuses",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum crew (inclusive)",
+                        "name": "min_crew",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum crew (inclusive)",
+                        "name": "max_crew",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum value (inclusive)",
+                        "name": "min_value",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum value (inclusive)",
+                        "name": "max_value",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields to sort by, prefixed with - for descending order (id, name, class, crew, value, status, created_at, updated_at)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 20 by default and 100 at most",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of spaceships to skip, cannot be combined with cursor",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor taken from the next_cursor of a previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count every spaceship matching the filters",
+                        "name": "include_total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also list soft deleted spaceships",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/spaceship.getAllResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Create new spaceship, returned as fetched by ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaceship"
+                ],
+                "parameters": [
+                    {
+                        "description": "Request body (JSON)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/spaceship.createRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the response to an earlier request with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the spaceship"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "Path of the spaceship"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/spaceship/bulk": {
+            "post": {
+                "description": "Create, patch and delete many spaceships at once. Creations are inserted in batches.\nIn atomic mode, the default, nothing is written unless every item succeeds.\nIn best_effort mode, each item is applied on its own.\nEach item gets a result with its status, and a problem detail when it failed.\nAn invalid item rejects the whole request with 422, whatever the mode.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaceship"
+                ],
+                "parameters": [
+                    {
+                        "description": "Request body (JSON)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/spaceship.bulkRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the response to an earlier request with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Every item succeeded",
+                        "schema": {
+                            "$ref": "#/definitions/spaceship.bulkResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Some items failed",
+                        "schema": {
+                            "$ref": "#/definitions/spaceship.bulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/spaceship/export": {
+            "get": {
+                "description": "Export every spaceship, armaments included, as CSV or NDJSON, streamed ordered by ID.\nCSV lists armaments in a single column, as weapon_id:qty pairs separated by semicolons.\nA failure midway aborts the stream, leaving it unterminated.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Spaceship"
+                ],
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Export format, ndjson by default",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One per line",
+                        "schema": {
+                            "$ref": "#/definitions/spaceship.exportRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/spaceship/import": {
+            "post": {
+                "description": "Create spaceships from a CSV or NDJSON file, in the export format. IDs are ignored.\nThe format is given by the format query param, or else by the Content-Type.\nEach line is applied on its own, the errors are reported with their line number.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaceship"
+                ],
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Import format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "description": "CSV or NDJSON file",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the response to an earlier request with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Every line was imported",
+                        "schema": {
+                            "$ref": "#/definitions/spaceship.importResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Some lines failed",
+                        "schema": {
+                            "$ref": "#/definitions/spaceship.importResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/spaceship/{id}": {
+            "get": {
+                "description": "Fetch existing spaceship by a specific ID.\nThe ETag header holds its version, to be sent back in If-Match when changing it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaceship"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spaceship ID (integer)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the spaceship"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace existing spaceship by a specific ID, armaments included.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaceship"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spaceship ID (integer)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETags of the spaceship, or *, the request fails unless one of them is current",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Request body (JSON)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/spaceship.createRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the response to an earlier request with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the spaceship"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "412": {
+                        "description": "Precondition Failed"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete existing spaceship by a specific ID, along with its armaments.\nThe spaceship is soft deleted and can be restored, unless purged. Purging requires the admin token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaceship"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spaceship ID (integer)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Remove the spaceship for good, even a soft deleted one",
+                        "name": "purge",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETags of the spaceship, or *, the request fails unless one of them is current",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer admin token, required to purge",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the response to an earlier request with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "412": {
+                        "description": "Precondition Failed"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "patch": {
+                "description": "Partially update existing spaceship by a specific ID, following JSON merge patch (RFC 7396) semantics.\nOnly the fields present in the body are changed, and sending \"armament\" replaces every armament.\nA null field is reset to its zero value.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaceship"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spaceship ID (integer)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETags of the spaceship, or *, the request fails unless one of them is current",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Request body (JSON)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/spaceship.updateRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the response to an earlier request with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the spaceship"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "412": {
+                        "description": "Precondition Failed"
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/spaceship/{id}/armaments": {
+            "get": {
+                "description": "Get every armament of an existing spaceship.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Armament"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spaceship ID (integer)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/spaceship.armamentResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Add an armament to an existing spaceship.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Armament"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spaceship ID (integer)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body (JSON)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/spaceship.armamentReq"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the response to an earlier request with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/spaceship.armamentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/spaceship/{id}/armaments/{armamentId}": {
+            "delete": {
+                "description": "Delete an armament of an existing spaceship.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Armament"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spaceship ID (integer)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Armament ID (integer)",
+                        "name": "armamentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the response to an earlier request with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "patch": {
+                "description": "Partially update an armament of an existing spaceship, following JSON merge patch (RFC 7396) semantics.\nThe armament keeps its ID.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Armament"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spaceship ID (integer)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Armament ID (integer)",
+                        "name": "armamentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body (JSON)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/spaceship.updateArmamentRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the response to an earlier request with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/spaceship.armamentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/spaceship/{id}/restore": {
+            "post": {
+                "description": "Restore a soft deleted spaceship by a specific ID, along with the armaments deleted with it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaceship"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spaceship ID (integer)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the response to an earlier request with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/weapon": {
+            "get": {
+                "description": "Get the whole weapon catalog, sorted by name.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Weapon"
+                ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/weapon.weaponResponse"
+                            }
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error"
@@ -35,7 +878,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create new spaceship.",
+                "description": "Add a weapon to the catalog. Names must be unique once normalized, ignoring case, spaces and punctuation.",
                 "consumes": [
                     "application/json"
                 ],
@@ -43,7 +886,7 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Spaceship"
+                    "Weapon"
                 ],
                 "parameters": [
                     {
@@ -52,13 +895,25 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/spaceship.createRequest"
+                            "$ref": "#/definitions/weapon.createRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Created"
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/weapon.weaponResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity"
                     },
                     "500": {
                         "description": "Internal Server Error"
@@ -66,19 +921,19 @@ const docTemplate = `{
                 }
             }
         },
-        "/spaceship/{id}": {
+        "/weapon/{id}": {
             "get": {
-                "description": "Fetch existing spaceship by a specific ID.",
+                "description": "Fetch a weapon of the catalog by a specific ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Spaceship"
+                    "Weapon"
                 ],
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Spaceship ID (integer)",
+                        "description": "Weapon ID (integer)",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -86,28 +941,34 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/weapon.weaponResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             },
             "delete": {
-                "description": "Delete existing spaceship by a specific ID.",
+                "description": "Remove a weapon from the catalog, as long as no armament uses it.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Spaceship"
+                    "Weapon"
                 ],
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Spaceship ID (integer)",
+                        "description": "Weapon ID (integer)",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -120,26 +981,33 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request"
                     },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             },
             "patch": {
-                "description": "Update existing spaceship by a specific ID.",
+                "description": "Partially update a weapon of the catalog, following JSON merge patch (RFC 7396) semantics.\nArmaments referring to the weapon see the change.",
                 "consumes": [
+                    "application/merge-patch+json",
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Spaceship"
+                    "Weapon"
                 ],
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Spaceship ID (integer)",
+                        "description": "Weapon ID (integer)",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -150,17 +1018,32 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/spaceship.updateRequest"
+                            "$ref": "#/definitions/weapon.updateRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/weapon.weaponResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
@@ -169,14 +1052,133 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "helpers.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "helpers.Problem": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/helpers.FieldError"
+                    }
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
         "spaceship.armamentReq": {
             "type": "object",
             "properties": {
                 "qty": {
                     "type": "integer"
                 },
-                "title": {
+                "weapon_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "spaceship.armamentResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "qty": {
+                    "type": "integer"
+                },
+                "weapon": {
+                    "type": "string"
+                },
+                "weapon_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "spaceship.bulkRequest": {
+            "type": "object",
+            "properties": {
+                "create": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/spaceship.createRequest"
+                    }
+                },
+                "delete": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ]
+                },
+                "update": {
+                    "description": "of bulkUpdateRequest",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                }
+            }
+        },
+        "spaceship.bulkResponse": {
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "type": "boolean"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/spaceship.bulkResultResponse"
+                    }
+                }
+            }
+        },
+        "spaceship.bulkResultResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/helpers.Problem"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "op": {
                     "type": "string"
+                },
+                "status": {
+                    "type": "integer"
                 }
             }
         },
@@ -209,7 +1211,7 @@ const docTemplate = `{
                 }
             }
         },
-        "spaceship.updateRequest": {
+        "spaceship.exportRecord": {
             "type": "object",
             "properties": {
                 "armament": {
@@ -240,6 +1242,165 @@ const docTemplate = `{
                     "type": "number"
                 }
             }
+        },
+        "spaceship.getAllResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/spaceship.spaceShipResponse"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "spaceship.importErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/helpers.Problem"
+                },
+                "line": {
+                    "type": "integer"
+                }
+            }
+        },
+        "spaceship.importResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/spaceship.importErrorResponse"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                }
+            }
+        },
+        "spaceship.spaceShipResponse": {
+            "type": "object",
+            "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "spaceship.updateArmamentRequest": {
+            "type": "object",
+            "properties": {
+                "qty": {
+                    "type": "integer"
+                },
+                "weapon_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "spaceship.updateRequest": {
+            "type": "object",
+            "properties": {
+                "armament": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/spaceship.armamentReq"
+                    }
+                },
+                "class": {
+                    "type": "string"
+                },
+                "crew": {
+                    "type": "integer"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "weapon.createRequest": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "type": "number"
+                },
+                "damage": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "range": {
+                    "type": "number"
+                }
+            }
+        },
+        "weapon.updateRequest": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "type": "number"
+                },
+                "damage": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "range": {
+                    "type": "number"
+                }
+            }
+        },
+        "weapon.weaponResponse": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "type": "number"
+                },
+                "damage": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "range": {
+                    "type": "number"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,407 @@
+// Package config loads the service configuration. Each setting is layered,
+// the last source setting it winning: defaults, then a YAML or TOML file, then
+// env variables, then command line flags.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the whole service configuration. Every setting has a file key,
+// made of the section and setting yaml tags, an env variable and a flag,
+// which is the file key with dashes, such as -http-read-timeout.
+type Config struct {
+	HTTP     HTTPConfig     `yaml:"http" toml:"http"`
+	DB       DBConfig       `yaml:"db" toml:"db"`
+	Log      LogConfig      `yaml:"log" toml:"log"`
+	Auth     AuthConfig     `yaml:"auth" toml:"auth"`
+	Tracing  TracingConfig  `yaml:"tracing" toml:"tracing"`
+	Features FeaturesConfig `yaml:"features" toml:"features"`
+}
+
+type HTTPConfig struct {
+	Addr              string        `yaml:"addr" toml:"addr" env:"HTTP_ADDR"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" toml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" toml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" toml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"` // bounds whole responses, exports included
+	IdleTimeout       time.Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" toml:"max_header_bytes" env:"HTTP_MAX_HEADER_BYTES"`
+	ShutdownDelay     time.Duration `yaml:"shutdown_delay" toml:"shutdown_delay" env:"HTTP_SHUTDOWN_DELAY"`          // /readyz fails for that long before draining starts
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT"`    // given to in-flight requests
+	ReadinessTimeout  time.Duration `yaml:"readiness_timeout" toml:"readiness_timeout" env:"HTTP_READINESS_TIMEOUT"` // given to each /readyz check
+}
+
+type DBConfig struct {
+	Host     string `yaml:"host" toml:"host" env:"DB_HOST"`
+	Port     int    `yaml:"port" toml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" toml:"user" env:"DB_USER"`
+	Password string `yaml:"password" toml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name     string `yaml:"name" toml:"name" env:"DB_NAME"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"` // 0 is unlimited
+	MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"` // 0 keeps connections forever
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+
+	ConnectAttempts int           `yaml:"connect_attempts" toml:"connect_attempts" env:"DB_CONNECT_ATTEMPTS"`
+	ConnectBackoff  time.Duration `yaml:"connect_backoff" toml:"connect_backoff" env:"DB_CONNECT_BACKOFF"`    // doubled after every failed attempt
+	MigrateOnStart  bool          `yaml:"migrate_on_start" toml:"migrate_on_start" env:"DB_MIGRATE_ON_START"` // else run "galactic-svc migrate up" before starting
+	PingTimeout     time.Duration `yaml:"ping_timeout" toml:"ping_timeout" env:"DB_PING_TIMEOUT"`             // bounds readiness checks
+
+	// Query timeouts bound each repository call by kind, 0 leaving it bounded
+	// by its request alone.
+	ReadQueryTimeout   time.Duration `yaml:"read_query_timeout" toml:"read_query_timeout" env:"DB_READ_QUERY_TIMEOUT"`
+	WriteQueryTimeout  time.Duration `yaml:"write_query_timeout" toml:"write_query_timeout" env:"DB_WRITE_QUERY_TIMEOUT"`
+	BatchQueryTimeout  time.Duration `yaml:"batch_query_timeout" toml:"batch_query_timeout" env:"DB_BATCH_QUERY_TIMEOUT"`    // bulk inserts
+	ExportQueryTimeout time.Duration `yaml:"export_query_timeout" toml:"export_query_timeout" env:"DB_EXPORT_QUERY_TIMEOUT"` // streamed exports
+}
+
+// DSN is the MySQL data source name of the database.
+func (c DBConfig) DSN() string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local", c.User, c.Password, c.Host, c.Port, c.Name)
+}
+
+type LogConfig struct {
+	Level              string  `yaml:"level" toml:"level" env:"LOG_LEVEL"`                                              // debug, info, warn or error
+	Format             string  `yaml:"format" toml:"format" env:"LOG_FORMAT"`                                           // json or logfmt
+	RequestLevel       string  `yaml:"request_level" toml:"request_level" env:"LOG_REQUEST_LEVEL"`                      // debug or info, server errors being logged as errors
+	RequestSampleRatio float64 `yaml:"request_sample_ratio" toml:"request_sample_ratio" env:"LOG_REQUEST_SAMPLE_RATIO"` // of the requests logged, server errors all being logged
+}
+
+type AuthConfig struct {
+	AdminToken string `yaml:"admin_token" toml:"admin_token" env:"ADMIN_TOKEN" secret:"true"` // empty disables the admin access
+}
+
+type TracingConfig struct {
+	Exporter     string  `yaml:"exporter" toml:"exporter" env:"TRACING_EXPORTER"`                // none, stdout or otlp
+	OTLPEndpoint string  `yaml:"otlp_endpoint" toml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"` // host:port of an OTLP/HTTP collector
+	OTLPInsecure bool    `yaml:"otlp_insecure" toml:"otlp_insecure" env:"TRACING_OTLP_INSECURE"` // plain HTTP instead of HTTPS
+	SampleRatio  float64 `yaml:"sample_ratio" toml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`    // of the traces started here, sampled parents being followed
+}
+
+type FeaturesConfig struct {
+	Swagger        bool          `yaml:"swagger" toml:"swagger" env:"FEATURE_SWAGGER"`
+	Idempotency    bool          `yaml:"idempotency" toml:"idempotency" env:"FEATURE_IDEMPOTENCY"`
+	Metrics        bool          `yaml:"metrics" toml:"metrics" env:"FEATURE_METRICS"` // serves /metrics
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl" toml:"idempotency_ttl" env:"IDEMPOTENCY_TTL"`
+}
+
+// Default returns the settings used when no source sets them.
+func Default() Config {
+	return Config{
+		HTTP: HTTPConfig{
+			Addr:              ":3000",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			ShutdownTimeout:   30 * time.Second,
+			ReadinessTimeout:  3 * time.Second,
+		},
+		DB: DBConfig{
+			Host:            "127.0.0.1",
+			Port:            3306,
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 5 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			ConnectAttempts: 10,
+			ConnectBackoff:  500 * time.Millisecond,
+			MigrateOnStart:  true,
+			PingTimeout:     2 * time.Second,
+
+			ReadQueryTimeout:  5 * time.Second,
+			WriteQueryTimeout: 10 * time.Second,
+			BatchQueryTimeout: 30 * time.Second,
+		},
+		Log: LogConfig{
+			Level:              "info",
+			Format:             "json",
+			RequestLevel:       "info",
+			RequestSampleRatio: 1,
+		},
+		Tracing: TracingConfig{
+			Exporter:     "none",
+			OTLPEndpoint: "localhost:4318",
+			SampleRatio:  1,
+		},
+		Features: FeaturesConfig{
+			Swagger:        true,
+			Idempotency:    true,
+			Metrics:        true,
+			IdempotencyTTL: 24 * time.Hour,
+		},
+	}
+}
+
+// Load reads the configuration from its sources and validates it. args are
+// the command line arguments, without the program name. The file is named by
+// the -config flag, or else by the CONFIG_FILE env variable. Env variables
+// may also come from a .env file, which is optional and never overrides the
+// real ones.
+func Load(args []string) (Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Config{}, fmt.Errorf("config.Load(): failed to read .env: %w", err)
+	}
+
+	cfg := Default()
+	settings := cfg.settings()
+
+	flags := flag.NewFlagSet("galactic-svc", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	file := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file")
+
+	values := map[string]*string{}
+	for _, s := range settings {
+		values[s.flag] = flags.String(s.flag, "", "overrides "+s.env)
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return Config{}, fmt.Errorf("config.Load(): %w", err)
+	}
+
+	if *file != "" {
+		if err := decodeFile(*file, &cfg); err != nil {
+			return Config{}, fmt.Errorf("config.Load(): %w", err)
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok && value != "" {
+			if err := s.set(value); err != nil {
+				return Config{}, fmt.Errorf("config.Load(): invalid %s: %w", s.env, err)
+			}
+		}
+	}
+
+	var err error
+	flags.Visit(func(f *flag.Flag) {
+		if value, ok := values[f.Name]; ok && err == nil {
+			if setErr := settingByFlag(settings, f.Name).set(*value); setErr != nil {
+				err = fmt.Errorf("config.Load(): invalid -%s: %w", f.Name, setErr)
+			}
+		}
+	})
+	if err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// decodeFile reads a config file, as YAML or TOML after its extension. Keys
+// which are not settings are rejected, to catch typos.
+func decodeFile(path string, cfg *Config) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(f)
+		decoder.KnownFields(true)
+
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.NewDecoder(f).Decode(cfg)
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("invalid config file %s: unknown key %s", path, undecoded[0])
+		}
+	default:
+		return fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.HTTP.Addr != "", "http.addr is required")
+	check(c.HTTP.ReadHeaderTimeout > 0, "http.read_header_timeout must be positive")
+	check(c.HTTP.ReadTimeout >= 0, "http.read_timeout must not be negative")
+	check(c.HTTP.WriteTimeout >= 0, "http.write_timeout must not be negative")
+	check(c.HTTP.IdleTimeout >= 0, "http.idle_timeout must not be negative")
+	check(c.HTTP.MaxHeaderBytes > 0, "http.max_header_bytes must be positive")
+	check(c.HTTP.ShutdownDelay >= 0, "http.shutdown_delay must not be negative")
+	check(c.HTTP.ShutdownTimeout > 0, "http.shutdown_timeout must be positive")
+	check(c.HTTP.ReadinessTimeout > 0, "http.readiness_timeout must be positive")
+
+	check(c.DB.Host != "", "db.host is required")
+	check(c.DB.Port > 0 && c.DB.Port <= 65535, "db.port must be between 1 and 65535")
+	check(c.DB.User != "", "db.user is required")
+	check(c.DB.Name != "", "db.name is required")
+	check(c.DB.MaxOpenConns >= 0, "db.max_open_conns must not be negative")
+	check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns must not be negative")
+	check(c.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime must not be negative")
+	check(c.DB.ConnMaxIdleTime >= 0, "db.conn_max_idle_time must not be negative")
+	check(c.DB.ConnectAttempts > 0, "db.connect_attempts must be positive")
+	check(c.DB.ConnectBackoff > 0, "db.connect_backoff must be positive")
+	check(c.DB.PingTimeout > 0, "db.ping_timeout must be positive")
+	check(c.DB.ReadQueryTimeout >= 0, "db.read_query_timeout must not be negative")
+	check(c.DB.WriteQueryTimeout >= 0, "db.write_query_timeout must not be negative")
+	check(c.DB.BatchQueryTimeout >= 0, "db.batch_query_timeout must not be negative")
+	check(c.DB.ExportQueryTimeout >= 0, "db.export_query_timeout must not be negative")
+
+	check(oneOf(c.Log.Level, "debug", "info", "warn", "error"), "log.level must be one of: debug, info, warn, error")
+	check(oneOf(c.Log.Format, "json", "logfmt"), "log.format must be one of: json, logfmt")
+	check(oneOf(c.Log.RequestLevel, "debug", "info"), "log.request_level must be one of: debug, info")
+	check(c.Log.RequestSampleRatio >= 0 && c.Log.RequestSampleRatio <= 1, "log.request_sample_ratio must be between 0 and 1")
+
+	check(oneOf(c.Tracing.Exporter, "none", "stdout", "otlp"), "tracing.exporter must be one of: none, stdout, otlp")
+	check(c.Tracing.Exporter != "otlp" || c.Tracing.OTLPEndpoint != "", "tracing.otlp_endpoint is required")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+
+	check(!c.Features.Idempotency || c.Features.IdempotencyTTL > 0, "features.idempotency_ttl must be positive")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("config.Validate(): %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func oneOf(value string, values ...string) bool {
+	for _, v := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Redacted lists the settings as alternating keys and values, ready for a
+// go-kit logger. Secrets which are set show as "[REDACTED]".
+func (c Config) Redacted() []interface{} {
+	var keyvals []interface{}
+	for _, s := range c.settings() {
+		var value interface{} = s.value.Interface()
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+
+		if s.secret && !s.value.IsZero() {
+			value = "[REDACTED]"
+		}
+
+		keyvals = append(keyvals, s.key, value)
+	}
+
+	return keyvals
+}
+
+// setting is a single field of Config, with the names it goes by.
+type setting struct {
+	key    string // in files, such as http.read_timeout
+	env    string
+	flag   string
+	secret bool
+	value  reflect.Value
+}
+
+// settings walks the sections of c. The returned settings point into c.
+func (c *Config) settings() []setting {
+	var settings []setting
+
+	root := reflect.ValueOf(c).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		section := root.Type().Field(i)
+		for j := 0; j < section.Type.NumField(); j++ {
+			field := section.Type.Field(j)
+			key := section.Tag.Get("yaml") + "." + field.Tag.Get("yaml")
+
+			settings = append(settings, setting{
+				key:    key,
+				env:    field.Tag.Get("env"),
+				flag:   strings.NewReplacer(".", "-", "_", "-").Replace(key),
+				secret: field.Tag.Get("secret") == "true",
+				value:  root.Field(i).Field(j),
+			})
+		}
+	}
+
+	return settings
+}
+
+func settingByFlag(settings []setting, name string) setting {
+	for _, s := range settings {
+		if s.flag == name {
+			return s
+		}
+	}
+
+	return setting{}
+}
+
+// set parses raw after the type of the setting.
+func (s setting) set(raw string) error {
+	switch s.value.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+
+		s.value.SetInt(int64(d))
+	case string:
+		s.value.SetString(raw)
+	case int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+
+		s.value.SetInt(int64(n))
+	case float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+
+		s.value.SetFloat(f)
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+
+		s.value.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.value.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// clearEnv blanks the env variables of every setting for the test, blank
+// being taken as unset.
+func clearEnv(t *testing.T) {
+	cfg := Default()
+	for _, s := range cfg.settings() {
+		t.Setenv(s.env, "")
+	}
+
+	t.Setenv("CONFIG_FILE", "")
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	yamlFile := `
+http:
+  addr: ":8080"
+  read_timeout: 10s
+db:
+  user: file
+  name: galactic
+  port: 3307
+`
+	tomlFile := `
+[http]
+addr = ":8080"
+read_timeout = "10s"
+
+[db]
+user = "file"
+name = "galactic"
+port = 3307
+`
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		args    []string
+		want    func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name: "Given only env variables, should layer them over the defaults",
+			env:  map[string]string{"DB_USER": "env", "DB_NAME": "galactic", "DB_PASSWORD": "secret"},
+			want: func(cfg *Config) {
+				cfg.DB.User = "env"
+				cfg.DB.Name = "galactic"
+				cfg.DB.Password = "secret"
+			},
+		},
+		{
+			name:    "Given a YAML file, should layer it over the defaults",
+			file:    "config.yaml",
+			content: yamlFile,
+			want: func(cfg *Config) {
+				cfg.HTTP.Addr = ":8080"
+				cfg.HTTP.ReadTimeout = 10 * time.Second
+				cfg.DB.User = "file"
+				cfg.DB.Name = "galactic"
+				cfg.DB.Port = 3307
+			},
+		},
+		{
+			name:    "Given a TOML file, should layer it over the defaults",
+			file:    "config.toml",
+			content: tomlFile,
+			want: func(cfg *Config) {
+				cfg.HTTP.Addr = ":8080"
+				cfg.HTTP.ReadTimeout = 10 * time.Second
+				cfg.DB.User = "file"
+				cfg.DB.Name = "galactic"
+				cfg.DB.Port = 3307
+			},
+		},
+		{
+			name:    "Given a file, env variables and flags, should let flags win over env and env over the file",
+			file:    "config.yaml",
+			content: yamlFile,
+			env:     map[string]string{"HTTP_ADDR": ":9090", "DB_USER": "env", "FEATURE_SWAGGER": "false", "TRACING_SAMPLE_RATIO": "0.5"},
+			args:    []string{"-db-user", "flag", "-http-idle-timeout=1m", "-tracing-exporter", "stdout"},
+			want: func(cfg *Config) {
+				cfg.HTTP.Addr = ":9090"
+				cfg.HTTP.ReadTimeout = 10 * time.Second
+				cfg.HTTP.IdleTimeout = time.Minute
+				cfg.DB.User = "flag"
+				cfg.DB.Name = "galactic"
+				cfg.DB.Port = 3307
+				cfg.Features.Swagger = false
+				cfg.Tracing.SampleRatio = 0.5
+				cfg.Tracing.Exporter = "stdout"
+			},
+		},
+		{
+			name:    "Given an unknown key in the file, should return non-nil error",
+			file:    "config.yaml",
+			content: "db:\n  usr: file\n",
+			wantErr: "field usr not found",
+		},
+		{
+			name:    "Given an unknown key in a TOML file, should return non-nil error",
+			file:    "config.toml",
+			content: "[db]\nusr = \"file\"\n",
+			wantErr: "unknown key db.usr",
+		},
+		{
+			name:    "Given a file of another format, should return non-nil error",
+			file:    "config.json",
+			content: "{}",
+			wantErr: "must be .yaml, .yml or .toml",
+		},
+		{
+			name:    "Given a malformed env variable, should return non-nil error",
+			env:     map[string]string{"DB_USER": "env", "DB_NAME": "galactic", "DB_PORT": "mysql"},
+			wantErr: "invalid DB_PORT",
+		},
+		{
+			name:    "Given a malformed flag, should return non-nil error",
+			env:     map[string]string{"DB_USER": "env", "DB_NAME": "galactic"},
+			args:    []string{"-http-read-timeout", "soon"},
+			wantErr: "invalid -http-read-timeout",
+		},
+		{
+			name:    "Given an unknown flag, should return non-nil error",
+			args:    []string{"-db-usr", "flag"},
+			wantErr: "flag provided but not defined: -db-usr",
+		},
+		{
+			name:    "Given invalid settings, should report every one of them",
+			env:     map[string]string{"LOG_LEVEL": "verbose", "DB_PORT": "70000"},
+			wantErr: "config.Validate(): db.port must be between 1 and 65535\ndb.user is required\ndb.name is required\nlog.level must be one of: debug, info, warn, error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, tt.file, tt.content)}, args...)
+			}
+
+			got, err := Load(args)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			want := Default()
+			tt.want(&want)
+
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestLoad_ConfigFileEnv(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yml", "db:\n  user: file\n  name: galactic\n"))
+
+	got, err := Load(nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "file", got.DB.User)
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := Default()
+	cfg.DB.User = "admin"
+	cfg.DB.Password = "secret"
+
+	got := map[interface{}]interface{}{}
+	keyvals := cfg.Redacted()
+	for i := 0; i < len(keyvals); i += 2 {
+		got[keyvals[i]] = keyvals[i+1]
+	}
+
+	assert.Equal(t, "admin", got["db.user"])
+	assert.Equal(t, "[REDACTED]", got["db.password"])
+	assert.Equal(t, "", got["auth.admin_token"])
+	assert.Equal(t, "30s", got["http.read_timeout"])
+	assert.Equal(t, 3306, got["db.port"])
+}
+
+func TestDBConfig_DSN(t *testing.T) {
+	cfg := DBConfig{Host: "db", Port: 3306, User: "admin", Password: "secret", Name: "galactic"}
+
+	assert.Equal(t, "admin:secret@tcp(db:3306)/galactic?charset=utf8mb4&parseTime=True&loc=Local", cfg.DSN())
+}
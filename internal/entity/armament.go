@@ -2,9 +2,11 @@ package entity
 
 import "gorm.io/gorm"
 
+// Armament tells how many weapons of the catalog a spaceship carries.
 type Armament struct {
 	gorm.Model
-	Title       string
-	Qty         int
 	SpaceShipID uint
+	WeaponID    uint
+	Weapon      Weapon
+	Qty         int
 }
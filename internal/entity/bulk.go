@@ -0,0 +1,41 @@
+package entity
+
+// BulkModes lists how the items of a bulk request may be applied: all of them
+// or none, or each one on its own.
+var BulkModes = []string{
+	"atomic",
+	"best_effort",
+}
+
+// SpaceShipUpdate is the patch of a single spaceship within a bulk request. A
+// zero Version means the client has no expectation about the stored one.
+type SpaceShipUpdate struct {
+	ID      int64
+	Version VersionMatch
+	Patch   SpaceShipPatch
+}
+
+// SpaceShipBulk groups spaceships to create, patch and delete at once.
+type SpaceShipBulk struct {
+	Create     []SpaceShip
+	Update     []SpaceShipUpdate
+	Delete     []int64
+	BestEffort bool
+}
+
+// BulkResult is the outcome of one item of a bulk request: the spaceship it
+// applies to, if known, or why it failed.
+type BulkResult struct {
+	ID  uint
+	Err error
+}
+
+// SpaceShipBulkResult holds the outcome of every item of a SpaceShipBulk, in
+// the same order. Committed tells whether the request was applied: in best
+// effort mode, unless every one of its items failed.
+type SpaceShipBulkResult struct {
+	Create    []BulkResult
+	Update    []BulkResult
+	Delete    []BulkResult
+	Committed bool
+}
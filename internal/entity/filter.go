@@ -0,0 +1,15 @@
+package entity
+
+// SpaceShipFilter narrows down the spaceships returned by a listing. Fields
+// left at their zero value are not applied.
+type SpaceShipFilter struct {
+	Name     string   // partial match on the name
+	Classes  []string // exact match on any of the given classes
+	Statuses []string // exact match on any of the given statuses
+	MinCrew  *int64
+	MaxCrew  *int64
+	MinValue *float64
+	MaxValue *float64
+
+	IncludeDeleted bool // also return soft deleted spaceships
+}
@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdempotencyKey is the response to a request bearing an Idempotency-Key
+// header, replayed to the retries of that request until it expires.
+type IdempotencyKey struct {
+	Key         string      `gorm:"primaryKey;size:255"`
+	RequestHash string      `gorm:"size:64"` // SHA-256 of the method, URI and body
+	Status      int         // zero while the request is in progress
+	Header      http.Header `gorm:"type:text;serializer:json"`
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time `gorm:"index"`
+}
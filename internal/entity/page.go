@@ -0,0 +1,30 @@
+package entity
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// SortField orders a listing by a single field.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ListOptions controls the order and the window of a listing. When Cursor is
+// set, Offset is ignored and the listing resumes right after the cursor.
+type ListOptions struct {
+	Sort      []SortField
+	Limit     int
+	Offset    int
+	Cursor    string
+	WithTotal bool
+}
+
+// SpaceShipPage is a single window of a spaceship listing.
+type SpaceShipPage struct {
+	SpaceShips []SpaceShip
+	NextCursor string
+	HasMore    bool
+	Total      *int64
+}
@@ -0,0 +1,98 @@
+package entity
+
+// SpaceShipPatch holds the fields changed by a partial update. Nil fields are
+// left untouched, while a non-nil Armaments replaces every armament.
+type SpaceShipPatch struct {
+	Name      *string
+	Class     *string
+	Crew      *int64
+	Image     *string
+	Value     *float64
+	Status    *string
+	Armaments *[]Armament
+}
+
+// Apply returns s with the patched fields replaced.
+func (p SpaceShipPatch) Apply(s SpaceShip) SpaceShip {
+	if p.Name != nil {
+		s.Name = *p.Name
+	}
+
+	if p.Class != nil {
+		s.Class = *p.Class
+	}
+
+	if p.Crew != nil {
+		s.Crew = *p.Crew
+	}
+
+	if p.Image != nil {
+		s.Image = *p.Image
+	}
+
+	if p.Value != nil {
+		s.Value = *p.Value
+	}
+
+	if p.Status != nil {
+		s.Status = *p.Status
+	}
+
+	if p.Armaments != nil {
+		s.Armaments = *p.Armaments
+	}
+
+	return s
+}
+
+// ArmamentPatch holds the fields changed by a partial update of an armament.
+// Nil fields are left untouched.
+type ArmamentPatch struct {
+	WeaponID *uint
+	Qty      *int
+}
+
+// Apply returns a with the patched fields replaced.
+func (p ArmamentPatch) Apply(a Armament) Armament {
+	if p.WeaponID != nil && *p.WeaponID != a.WeaponID {
+		a.WeaponID = *p.WeaponID
+		a.Weapon = Weapon{}
+	}
+
+	if p.Qty != nil {
+		a.Qty = *p.Qty
+	}
+
+	return a
+}
+
+// WeaponPatch holds the fields changed by a partial update of a weapon. Nil
+// fields are left untouched.
+type WeaponPatch struct {
+	Name   *string
+	Damage *int64
+	Range  *float64
+	Cost   *float64
+}
+
+// Apply returns w with the patched fields replaced.
+func (p WeaponPatch) Apply(w Weapon) Weapon {
+	if p.Name != nil {
+		w.Name = *p.Name
+		w.NormalizedName = NormalizeWeaponName(*p.Name)
+	}
+
+	if p.Damage != nil {
+		w.Damage = *p.Damage
+	}
+
+	if p.Range != nil {
+		w.Range = *p.Range
+	}
+
+	if p.Cost != nil {
+		w.Cost = *p.Cost
+	}
+
+	return w
+}
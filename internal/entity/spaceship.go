@@ -2,6 +2,28 @@ package entity
 
 import "gorm.io/gorm"
 
+// SpaceShipClasses lists the classes a spaceship can belong to.
+var SpaceShipClasses = []string{
+	"Star Destroyer",
+	"Dreadnought",
+	"Cruiser",
+	"Frigate",
+	"Corvette",
+	"Freighter",
+	"Transport",
+	"Starfighter",
+	"Shuttle",
+}
+
+// SpaceShipStatuses lists the statuses a spaceship can be in.
+var SpaceShipStatuses = []string{
+	"Operational",
+	"Damaged",
+	"Under Repair",
+	"Destroyed",
+	"Decommissioned",
+}
+
 type SpaceShip struct {
 	gorm.Model
 	Name      string
@@ -11,4 +33,5 @@ type SpaceShip struct {
 	Image     string
 	Value     float64
 	Status    string
+	Version   uint `gorm:"not null;default:1"` // bumped on every update, for optimistic locking
 }
@@ -0,0 +1,32 @@
+package entity
+
+import "slices"
+
+// VersionMatch is the precondition a change puts on the version of a
+// spaceship, after the entity tags of an If-Match header. The zero
+// VersionMatch puts none.
+type VersionMatch struct {
+	// Any only requires the spaceship to exist, as "*" does.
+	Any bool
+	// Versions lists the versions the stored one must be among.
+	Versions []uint
+}
+
+// MatchVersion requires the spaceship to be at version, unless it is zero.
+func MatchVersion(version uint) VersionMatch {
+	if version == 0 {
+		return VersionMatch{}
+	}
+
+	return VersionMatch{Versions: []uint{version}}
+}
+
+// IsZero tells whether m puts no precondition at all.
+func (m VersionMatch) IsZero() bool {
+	return !m.Any && len(m.Versions) == 0
+}
+
+// Matches tells whether an existing spaceship at version meets m.
+func (m VersionMatch) Matches(version uint) bool {
+	return m.IsZero() || m.Any || slices.Contains(m.Versions, version)
+}
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// Weapon is an entry of the weapon catalog, which armaments refer to.
+type Weapon struct {
+	gorm.Model
+	Name           string
+	NormalizedName string `gorm:"size:100;uniqueIndex"` // see NormalizeWeaponName
+	Damage         int64
+	Range          float64 // in kilometers
+	Cost           float64
+}
+
+// NormalizeWeaponName reduces a weapon name to its lower case letters and
+// digits, so that "Turbo Laser", "turbolaser" and "Turbo-laser" are the same
+// weapon.
+func NormalizeWeaponName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_ReadinessHandler(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name         string
+		checks       map[string]Check
+		shutdown     bool
+		wantStatus   int
+		wantReport   string
+		wantFailures map[string]string
+	}{
+		{
+			name:       "Given no check, should be ready",
+			wantStatus: http.StatusOK,
+			wantReport: StatusOK,
+		},
+		{
+			name:       "Given passing checks, should be ready",
+			checks:     map[string]Check{"database": ok, "migrations": ok},
+			wantStatus: http.StatusOK,
+			wantReport: StatusOK,
+		},
+		{
+			name:         "Given a failing check, should not be ready",
+			checks:       map[string]Check{"database": down, "migrations": ok},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantReport:   StatusFailing,
+			wantFailures: map[string]string{"database": "connection refused"},
+		},
+		{
+			name:         "Given a check outlasting the timeout, should not be ready",
+			checks:       map[string]Check{"database": slow},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantReport:   StatusFailing,
+			wantFailures: map[string]string{"database": context.DeadlineExceeded.Error()},
+		},
+		{
+			name:       "Given a shutting down process, should not be ready",
+			checks:     map[string]Check{"database": ok},
+			shutdown:   true,
+			wantStatus: http.StatusServiceUnavailable,
+			wantReport: StatusShuttingDown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker(10 * time.Millisecond)
+			for name, check := range tt.checks {
+				c.Register(name, check)
+			}
+
+			if tt.shutdown {
+				c.Shutdown()
+			}
+
+			w := httptest.NewRecorder()
+			c.ReadinessHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			var report Report
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantReport, report.Status)
+
+			if tt.shutdown {
+				assert.Empty(t, report.Checks)
+				return
+			}
+
+			assert.Len(t, report.Checks, len(tt.checks))
+			for name, result := range report.Checks {
+				if msg, ok := tt.wantFailures[name]; ok {
+					assert.Equal(t, StatusFailing, result.Status)
+					assert.Equal(t, msg, result.Error)
+				} else {
+					assert.Equal(t, StatusOK, result.Status)
+					assert.Empty(t, result.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestChecker_LivenessHandler(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.Register("database", func(ctx context.Context) error { return errors.New("connection refused") })
+	c.Shutdown()
+
+	w := httptest.NewRecorder()
+	c.LivenessHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
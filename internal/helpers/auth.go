@@ -0,0 +1,29 @@
+package helpers
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+type adminKey struct{}
+
+// AdminAuth flags the requests bearing the admin token as a bearer token, to
+// be checked later on with IsAdmin. It fits as a go-kit ServerBefore function.
+// An empty token disables the admin access altogether.
+func AdminAuth(token string) func(ctx context.Context, r *http.Request) context.Context {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		bearer, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		admin := token != "" && found && subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1
+
+		return context.WithValue(ctx, adminKey{}, admin)
+	}
+}
+
+// IsAdmin tells whether the request carried by ctx was made with the admin
+// token.
+func IsAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminKey{}).(bool)
+	return admin
+}
@@ -0,0 +1,54 @@
+package helpers
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminAuth(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		want          bool
+	}{
+		{
+			name:          "Given the admin token, should flag the request as admin",
+			token:         "s3cret",
+			authorization: "Bearer s3cret",
+			want:          true,
+		},
+		{
+			name:          "Given another token, should not flag the request as admin",
+			token:         "s3cret",
+			authorization: "Bearer guess",
+			want:          false,
+		},
+		{
+			name:          "Given no bearer token, should not flag the request as admin",
+			token:         "s3cret",
+			authorization: "s3cret",
+			want:          false,
+		},
+		{
+			name:          "Given admin access disabled, should never flag the request as admin",
+			token:         "",
+			authorization: "Bearer ",
+			want:          false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("DELETE", "/spaceship/1?purge=true", nil)
+			r.Header.Set("Authorization", tt.authorization)
+
+			ctx := AdminAuth(tt.token)(context.Background(), r)
+
+			assert.Equal(t, tt.want, IsAdmin(ctx))
+		})
+	}
+}
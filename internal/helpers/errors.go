@@ -8,21 +8,167 @@ import (
 )
 
 var ErrInvalidPathParam = errors.New("invalid path param")
+var ErrInvalidQueryParam = errors.New("invalid query param")
 var ErrBadRequest = errors.New("invalid request")
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+var ErrRequestTooLarge = errors.New("request entity too large")
 
-func EncodeError(_ context.Context, err error, w http.ResponseWriter) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+// Error kinds, to be matched with errors.Is. Each kind maps to one HTTP status.
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrValidation         = errors.New("validation failed")
+	ErrConflict           = errors.New("conflict")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrInternal           = errors.New("internal error")
+)
+
+// FieldError tells why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error. Kind is one of the error kinds above, Message is
+// safe to show to clients and Err is the underlying cause, if any.
+type Error struct {
+	Kind    error
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
 
-	switch err {
-	case ErrBadRequest:
-		w.WriteHeader(http.StatusBadRequest)
-	case ErrInvalidPathParam:
-		w.WriteHeader(http.StatusBadRequest)
+	return []error{e.Kind}
+}
+
+func NewBadRequestError(message string) error {
+	return &Error{Kind: ErrBadRequest, Message: message}
+}
+
+func NewNotFoundError(message string) error {
+	return &Error{Kind: ErrNotFound, Message: message}
+}
+
+func NewValidationError(message string, fields ...FieldError) error {
+	return &Error{Kind: ErrValidation, Message: message, Fields: fields}
+}
+
+func NewConflictError(message string) error {
+	return &Error{Kind: ErrConflict, Message: message}
+}
+
+func NewPreconditionFailedError(message string) error {
+	return &Error{Kind: ErrPreconditionFailed, Message: message}
+}
+
+func NewUnauthorizedError(message string) error {
+	return &Error{Kind: ErrUnauthorized, Message: message}
+}
+
+func NewInternalError(err error) error {
+	return &Error{Kind: ErrInternal, Message: "internal error", Err: err}
+}
+
+// StatusClientClosedRequest is the non standard status, after nginx, of the
+// requests cancelled as their client went away. It is only ever logged.
+const StatusClientClosedRequest = 499
+
+// Problem is an RFC 7807 problem details body.
+type Problem struct {
+	Type   string       `json:"type"`
+	Title  string       `json:"title"`
+	Status int          `json:"status"`
+	Detail string       `json:"detail,omitempty"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// StatusCode returns the HTTP status matching the kind of err.
+func StatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrBadRequest),
+		errors.Is(err, ErrInvalidPathParam),
+		errors.Is(err, ErrInvalidQueryParam):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, ErrRequestTooLarge), errors.As(err, new(*http.MaxBytesError)):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrValidation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
 	default:
-		w.WriteHeader(http.StatusInternalServerError)
+		return http.StatusInternalServerError
+	}
+}
+
+// NewProblem describes err as problem details. The detail of internal errors
+// is left out so that no database or driver message leaks to clients.
+func NewProblem(err error) Problem {
+	status := StatusCode(err)
+	problem := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
 	}
 
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": err.Error(),
-	})
+	switch status {
+	case http.StatusInternalServerError:
+		return problem
+	case StatusClientClosedRequest:
+		problem.Title = "Client Closed Request"
+		problem.Detail = "request cancelled"
+		return problem
+	case http.StatusGatewayTimeout:
+		problem.Detail = "request timed out"
+		return problem
+	}
+
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		problem.Detail = domainErr.Message
+		problem.Errors = domainErr.Fields
+		return problem
+	}
+
+	for _, kind := range []error{ErrInvalidPathParam, ErrInvalidQueryParam, ErrBadRequest} {
+		if errors.Is(err, kind) {
+			problem.Detail = kind.Error()
+			break
+		}
+	}
+
+	return problem
+}
+
+func EncodeError(_ context.Context, err error, w http.ResponseWriter) {
+	problem := NewProblem(err)
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+
+	json.NewEncoder(w).Encode(problem)
 }
@@ -0,0 +1,162 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		want       Problem
+	}{
+		{
+			name:       "Given wrapped not found error, should return 404 with its message",
+			err:        fmt.Errorf("MakeEndpointGetByID(): %w", NewNotFoundError("spaceship not found")),
+			wantStatus: http.StatusNotFound,
+			want: Problem{
+				Type:   "about:blank",
+				Title:  "Not Found",
+				Status: http.StatusNotFound,
+				Detail: "spaceship not found",
+			},
+		},
+		{
+			name: "Given validation error, should return 422 with every field error",
+			err: NewValidationError("invalid spaceship",
+				FieldError{Field: "name", Message: "is required"},
+				FieldError{Field: "crew", Message: "must be 0 or greater"},
+			),
+			wantStatus: http.StatusUnprocessableEntity,
+			want: Problem{
+				Type:   "about:blank",
+				Title:  "Unprocessable Entity",
+				Status: http.StatusUnprocessableEntity,
+				Detail: "invalid spaceship",
+				Errors: []FieldError{
+					{Field: "name", Message: "is required"},
+					{Field: "crew", Message: "must be 0 or greater"},
+				},
+			},
+		},
+		{
+			name:       "Given conflict error, should return 409",
+			err:        NewConflictError("spaceship already exists"),
+			wantStatus: http.StatusConflict,
+			want: Problem{
+				Type:   "about:blank",
+				Title:  "Conflict",
+				Status: http.StatusConflict,
+				Detail: "spaceship already exists",
+			},
+		},
+		{
+			name:       "Given precondition failed error, should return 412",
+			err:        NewPreconditionFailedError("spaceship has been modified since it was fetched"),
+			wantStatus: http.StatusPreconditionFailed,
+			want: Problem{
+				Type:   "about:blank",
+				Title:  "Precondition Failed",
+				Status: http.StatusPreconditionFailed,
+				Detail: "spaceship has been modified since it was fetched",
+			},
+		},
+		{
+			name:       "Given unauthorized error, should return 401",
+			err:        NewUnauthorizedError("admin token required"),
+			wantStatus: http.StatusUnauthorized,
+			want: Problem{
+				Type:   "about:blank",
+				Title:  "Unauthorized",
+				Status: http.StatusUnauthorized,
+				Detail: "admin token required",
+			},
+		},
+		{
+			name:       "Given invalid path param sentinel, should return 400",
+			err:        ErrInvalidPathParam,
+			wantStatus: http.StatusBadRequest,
+			want: Problem{
+				Type:   "about:blank",
+				Title:  "Bad Request",
+				Status: http.StatusBadRequest,
+				Detail: "invalid path param",
+			},
+		},
+		{
+			name:       "Given internal error, should return 500 without leaking its cause",
+			err:        NewInternalError(assert.AnError),
+			wantStatus: http.StatusInternalServerError,
+			want: Problem{
+				Type:   "about:blank",
+				Title:  "Internal Server Error",
+				Status: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:       "Given a query cancelled by the client, should return 499 without leaking its cause",
+			err:        NewInternalError(fmt.Errorf("%w: %w", context.Canceled, assert.AnError)),
+			wantStatus: StatusClientClosedRequest,
+			want: Problem{
+				Type:   "about:blank",
+				Title:  "Client Closed Request",
+				Status: StatusClientClosedRequest,
+				Detail: "request cancelled",
+			},
+		},
+		{
+			name:       "Given a query past its deadline, should return 504",
+			err:        fmt.Errorf("database.GetAll(): %w", context.DeadlineExceeded),
+			wantStatus: http.StatusGatewayTimeout,
+			want: Problem{
+				Type:   "about:blank",
+				Title:  "Gateway Timeout",
+				Status: http.StatusGatewayTimeout,
+				Detail: "request timed out",
+			},
+		},
+		{
+			name:       "Given unknown error, should return 500",
+			err:        fmt.Errorf("MakeEndpointCreate(): %w", assert.AnError),
+			wantStatus: http.StatusInternalServerError,
+			want: Problem{
+				Type:   "about:blank",
+				Title:  "Internal Server Error",
+				Status: http.StatusInternalServerError,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			EncodeError(context.Background(), tt.err, w)
+
+			var got Problem
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestError_Is(t *testing.T) {
+	err := fmt.Errorf("service.Update(): %w", NewInternalError(assert.AnError))
+
+	var domainErr *Error
+	assert.True(t, errors.As(err, &domainErr))
+	assert.ErrorIs(t, err, ErrInternal)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,241 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	ht "github.com/go-kit/kit/transport/http"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+)
+
+const maxIdempotencyKeyLength = 255
+
+var (
+	errIdempotencyKeyReused     = NewConflictError("Idempotency-Key was already used for another request")
+	errIdempotencyKeyInProgress = NewConflictError("a request with this Idempotency-Key is still in progress")
+)
+
+// IdempotencyStore keeps the responses to the requests bearing an
+// Idempotency-Key header.
+type IdempotencyStore interface {
+	// Reserve claims record.Key for a request in progress until
+	// record.ExpiresAt. When the key is already claimed, it returns false
+	// along with the stored record.
+	Reserve(ctx context.Context, record entity.IdempotencyKey) (entity.IdempotencyKey, bool, error)
+	// Save stores the response to the request which reserved record.Key,
+	// kept until record.ExpiresAt.
+	Save(ctx context.Context, record entity.IdempotencyKey) error
+	Release(ctx context.Context, key string) error
+}
+
+// Idempotency replays the response to a request bearing an Idempotency-Key
+// header when it is retried with the same key, for ttl. A key reused for
+// another request is a conflict. Failed requests are not stored, so their
+// retries run again.
+//
+// A key is only reserved for lease while its request runs, which bounds the
+// request as well: a key left in progress by a request that never finished is
+// taken over by its retries once the lease is over.
+//
+// It takes three parts on a go-kit server: Before as a ServerBefore function,
+// Middleware around the endpoint and Encode around the response encoder.
+type Idempotency struct {
+	store IdempotencyStore
+	ttl   time.Duration
+	lease time.Duration
+}
+
+func NewIdempotency(store IdempotencyStore, ttl time.Duration, lease time.Duration) *Idempotency {
+	return &Idempotency{
+		store: store,
+		ttl:   ttl,
+		lease: lease,
+	}
+}
+
+type idempotencyKey struct{}
+
+// idempotentRequest is the state of a request bearing an Idempotency-Key,
+// shared by the parts of Idempotency through the request context.
+type idempotentRequest struct {
+	key      string
+	hash     string
+	err      error
+	reserved bool
+}
+
+// replayedResponse is the stored response Middleware hands over to Encode in
+// place of the endpoint response.
+type replayedResponse struct {
+	record entity.IdempotencyKey
+}
+
+// Before reads the Idempotency-Key header and hashes the request it is sent
+// with, leaving the body to be read again. A nil Idempotency ignores the
+// header, which turns the feature off.
+func (i *Idempotency) Before(ctx context.Context, r *http.Request) context.Context {
+	key := r.Header.Get("Idempotency-Key")
+	if i == nil || key == "" {
+		return ctx
+	}
+
+	req := &idempotentRequest{key: key}
+	if len(key) > maxIdempotencyKeyLength {
+		req.err = NewBadRequestError("Idempotency-Key must be at most 255 characters")
+		return context.WithValue(ctx, idempotencyKey{}, req)
+	}
+
+	body, err := io.ReadAll(limitBody(r.Body))
+	if err != nil {
+		// The decoder reads the body next, and must not take what is left
+		// of it for the whole.
+		r.Body = failedBody{err: err}
+		req.err = readError(err)
+		return context.WithValue(ctx, idempotencyKey{}, req)
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+	hash.Write(body)
+	req.hash = hex.EncodeToString(hash.Sum(nil))
+
+	return context.WithValue(ctx, idempotencyKey{}, req)
+}
+
+// failedBody fails every read with the error the body first failed with.
+type failedBody struct {
+	err error
+}
+
+func (b failedBody) Read(p []byte) (int, error) {
+	return 0, b.err
+}
+
+func (b failedBody) Close() error {
+	return nil
+}
+
+// Middleware claims the key of the request before running it, or hands the
+// stored response over to Encode when the request was already served.
+func (i *Idempotency) Middleware(next endpoint.Endpoint) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req, ok := ctx.Value(idempotencyKey{}).(*idempotentRequest)
+		if !ok {
+			return next(ctx, request)
+		}
+
+		if req.err != nil {
+			return nil, req.err
+		}
+
+		record, reserved, err := i.store.Reserve(ctx, entity.IdempotencyKey{
+			Key:         req.key,
+			RequestHash: req.hash,
+			ExpiresAt:   time.Now().Add(i.lease),
+		})
+		if err != nil {
+			return nil, NewInternalError(err)
+		}
+
+		if !reserved {
+			switch {
+			case record.RequestHash != req.hash:
+				return nil, errIdempotencyKeyReused
+			case record.Status == 0:
+				return nil, errIdempotencyKeyInProgress
+			default:
+				return replayedResponse{record: record}, nil
+			}
+		}
+
+		// The request must not outlive its reservation, lest a retry taking
+		// the key over runs it twice.
+		leased, cancel := context.WithTimeout(ctx, i.lease)
+		defer cancel()
+
+		response, err = next(leased, request)
+		if err != nil {
+			i.store.Release(ctx, req.key)
+			return nil, err
+		}
+
+		req.reserved = true
+		return response, nil
+	}
+}
+
+// Encode wraps enc to store the response to a request which reserved its
+// key, and to replay stored responses.
+func (i *Idempotency) Encode(enc ht.EncodeResponseFunc) ht.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		if replayed, ok := response.(replayedResponse); ok {
+			for name, values := range replayed.record.Header {
+				w.Header()[name] = values
+			}
+
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(replayed.record.Status)
+			_, err := w.Write(replayed.record.Body)
+
+			return err
+		}
+
+		req, ok := ctx.Value(idempotencyKey{}).(*idempotentRequest)
+		if !ok || !req.reserved {
+			return enc(ctx, w, response)
+		}
+
+		recorder := &responseRecorder{header: http.Header{}, status: http.StatusOK}
+		if err := enc(ctx, recorder, response); err != nil {
+			i.store.Release(ctx, req.key)
+			return err
+		}
+
+		// Should saving fail, the key stays claimed: its retries are turned
+		// down as in progress until it expires, rather than run twice.
+		i.store.Save(ctx, entity.IdempotencyKey{
+			Key:       req.key,
+			Status:    recorder.status,
+			Header:    recorder.header,
+			Body:      recorder.body.Bytes(),
+			ExpiresAt: time.Now().Add(i.ttl),
+		})
+
+		for name, values := range recorder.header {
+			w.Header()[name] = values
+		}
+
+		w.WriteHeader(recorder.status)
+		_, err := w.Write(recorder.body.Bytes())
+
+		return err
+	}
+}
+
+// responseRecorder is an http.ResponseWriter keeping the response in memory.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	return r.body.Write(p)
+}
@@ -0,0 +1,210 @@
+package helpers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ht "github.com/go-kit/kit/transport/http"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+)
+
+type memoryIdempotencyStore struct {
+	records map[string]entity.IdempotencyKey
+}
+
+func (m *memoryIdempotencyStore) Reserve(ctx context.Context, record entity.IdempotencyKey) (entity.IdempotencyKey, bool, error) {
+	if stored, ok := m.records[record.Key]; ok && stored.ExpiresAt.After(time.Now()) {
+		return stored, false, nil
+	}
+
+	m.records[record.Key] = record
+	return record, true, nil
+}
+
+func (m *memoryIdempotencyStore) Save(ctx context.Context, record entity.IdempotencyKey) error {
+	stored := m.records[record.Key]
+	stored.Status, stored.Header, stored.Body, stored.ExpiresAt = record.Status, record.Header, record.Body, record.ExpiresAt
+	m.records[record.Key] = stored
+
+	return nil
+}
+
+func (m *memoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	if m.records[key].Status == 0 {
+		delete(m.records, key)
+	}
+
+	return nil
+}
+
+func requestHash(method string, uri string, body string) string {
+	hash := sha256.Sum256([]byte(method + " " + uri + "\n" + body))
+	return hex.EncodeToString(hash[:])
+}
+
+func TestIdempotency(t *testing.T) {
+	type call struct {
+		key        string
+		body       string
+		wantStatus int
+		wantBody   string
+		wantDetail string
+		replayed   bool
+	}
+
+	tests := []struct {
+		name      string
+		stored    []entity.IdempotencyKey
+		calls     []call
+		wantCalls int
+	}{
+		{
+			name: "Given no key, should run every request",
+			calls: []call{
+				{body: `{"name":"Devastator"}`, wantStatus: http.StatusCreated, wantBody: `{"id":1}`},
+				{body: `{"name":"Devastator"}`, wantStatus: http.StatusCreated, wantBody: `{"id":2}`},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "Given the same key and payload, should replay the first response",
+			calls: []call{
+				{key: "abc", body: `{"name":"Devastator"}`, wantStatus: http.StatusCreated, wantBody: `{"id":1}`},
+				{key: "abc", body: `{"name":"Devastator"}`, wantStatus: http.StatusCreated, wantBody: `{"id":1}`, replayed: true},
+				{key: "def", body: `{"name":"Devastator"}`, wantStatus: http.StatusCreated, wantBody: `{"id":2}`},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "Given the same key with another payload, should return conflict",
+			calls: []call{
+				{key: "abc", body: `{"name":"Devastator"}`, wantStatus: http.StatusCreated, wantBody: `{"id":1}`},
+				{key: "abc", body: `{"name":"Executor"}`, wantStatus: http.StatusConflict, wantDetail: "Idempotency-Key was already used for another request"},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "Given the key of a request in progress, should return conflict",
+			stored: []entity.IdempotencyKey{{
+				Key:         "abc",
+				RequestHash: requestHash(http.MethodPost, "/spaceship", `{"name":"Devastator"}`),
+				ExpiresAt:   time.Now().Add(time.Minute),
+			}},
+			calls: []call{
+				{key: "abc", body: `{"name":"Devastator"}`, wantStatus: http.StatusConflict, wantDetail: "a request with this Idempotency-Key is still in progress"},
+			},
+			wantCalls: 0,
+		},
+		{
+			name: "Given the key of a request left in progress past its lease, should take it over",
+			stored: []entity.IdempotencyKey{{
+				Key:         "abc",
+				RequestHash: requestHash(http.MethodPost, "/spaceship", `{"name":"Devastator"}`),
+				ExpiresAt:   time.Now().Add(-time.Second),
+			}},
+			calls: []call{
+				{key: "abc", body: `{"name":"Devastator"}`, wantStatus: http.StatusCreated, wantBody: `{"id":1}`},
+				{key: "abc", body: `{"name":"Devastator"}`, wantStatus: http.StatusCreated, wantBody: `{"id":1}`, replayed: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "Given a failed request, should release its key to let it run again",
+			calls: []call{
+				{key: "abc", body: `{"name":""}`, wantStatus: http.StatusUnprocessableEntity},
+				{key: "abc", body: `{"name":""}`, wantStatus: http.StatusUnprocessableEntity},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "Given body past the size limit, should return request too large",
+			calls: []call{
+				{key: "abc", body: `{"name":"` + strings.Repeat("a", maxBodySize) + `"}`, wantStatus: http.StatusRequestEntityTooLarge},
+			},
+			wantCalls: 0,
+		},
+		{
+			name: "Given too long a key, should return bad request",
+			calls: []call{
+				{key: strings.Repeat("k", 256), body: `{"name":"Devastator"}`, wantStatus: http.StatusBadRequest},
+			},
+			wantCalls: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryIdempotencyStore{records: map[string]entity.IdempotencyKey{}}
+			for _, record := range tt.stored {
+				store.records[record.Key] = record
+			}
+
+			idempotency := NewIdempotency(store, time.Hour, time.Minute)
+
+			var calls int
+			handler := ht.NewServer(
+				idempotency.Middleware(func(ctx context.Context, request interface{}) (interface{}, error) {
+					calls++
+					if request.(map[string]string)["name"] == "" {
+						return nil, NewValidationError("request validation failed", FieldError{Field: "name", Message: "is required"})
+					}
+
+					return map[string]int{"id": calls}, nil
+				}),
+				func(ctx context.Context, r *http.Request) (interface{}, error) {
+					var req map[string]string
+					err := json.NewDecoder(r.Body).Decode(&req)
+					return req, err
+				},
+				idempotency.Encode(func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusCreated)
+					return json.NewEncoder(w).Encode(response)
+				}),
+				ht.ServerBefore(idempotency.Before),
+				ht.ServerErrorEncoder(EncodeError),
+			)
+
+			for _, c := range tt.calls {
+				r := httptest.NewRequest(http.MethodPost, "/spaceship", strings.NewReader(c.body))
+				if c.key != "" {
+					r.Header.Set("Idempotency-Key", c.key)
+				}
+
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+
+				body, _ := io.ReadAll(w.Body)
+				assert.Equal(t, c.wantStatus, w.Code)
+				if c.wantBody != "" {
+					assert.JSONEq(t, c.wantBody, string(body))
+					assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+				}
+
+				if c.wantDetail != "" {
+					var problem Problem
+					json.Unmarshal(body, &problem)
+					assert.Equal(t, c.wantDetail, problem.Detail)
+				}
+
+				if c.replayed {
+					assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+				} else {
+					assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+				}
+			}
+
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
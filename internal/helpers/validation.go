@@ -0,0 +1,282 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Validator checks request models against their `validate` struct tags and
+// reports every failing field at once. Fields are named after their `json`
+// tag so that errors point at what the client actually sent.
+type Validator struct {
+	validate *validator.Validate
+	enums    map[string][]string
+}
+
+func NewValidator() *Validator {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+
+		return name
+	})
+
+	return &Validator{
+		validate: validate,
+		enums:    map[string][]string{},
+	}
+}
+
+// RegisterEnum adds a tag accepting only the given string values.
+func (v *Validator) RegisterEnum(tag string, values ...string) {
+	allowed := make(map[string]bool, len(values))
+	for _, value := range values {
+		allowed[value] = true
+	}
+
+	v.enums[tag] = values
+	v.validate.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+		return allowed[fl.Field().String()]
+	})
+}
+
+// Validate returns a validation error listing every invalid field of s, or
+// nil when s is valid.
+func (v *Validator) Validate(s interface{}) error {
+	err := v.validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return NewInternalError(err)
+	}
+
+	fields := make([]FieldError, len(validationErrs))
+	for i, fieldErr := range validationErrs {
+		fields[i] = FieldError{
+			Field:   fieldPath(fieldErr.Namespace()),
+			Message: v.message(fieldErr),
+		}
+	}
+
+	return NewValidationError("request validation failed", fields...)
+}
+
+// KeepFieldErrors narrows a validation error down to the given top level
+// fields and their nested ones. It returns nil when none of them is invalid,
+// and any other error as is.
+func KeepFieldErrors(err error, fields ...string) error {
+	var domainErr *Error
+	if !errors.As(err, &domainErr) || !errors.Is(err, ErrValidation) {
+		return err
+	}
+
+	var kept []FieldError
+	for _, fieldErr := range domainErr.Fields {
+		for _, field := range fields {
+			if fieldErr.Field == field || strings.HasPrefix(fieldErr.Field, field+"[") || strings.HasPrefix(fieldErr.Field, field+".") {
+				kept = append(kept, fieldErr)
+				break
+			}
+		}
+	}
+
+	if len(kept) == 0 {
+		return nil
+	}
+
+	return NewValidationError(domainErr.Message, kept...)
+}
+
+// PrefixFieldErrors nests the fields of a validation error under prefix, so
+// that "name" becomes "create[2].name". Any other error is returned as is.
+func PrefixFieldErrors(err error, prefix string) error {
+	var domainErr *Error
+	if !errors.As(err, &domainErr) || !errors.Is(err, ErrValidation) {
+		return err
+	}
+
+	fields := make([]FieldError, len(domainErr.Fields))
+	for i, fieldErr := range domainErr.Fields {
+		fields[i] = FieldError{
+			Field:   prefix + "." + fieldErr.Field,
+			Message: fieldErr.Message,
+		}
+	}
+
+	return NewValidationError(domainErr.Message, fields...)
+}
+
+// fieldPath drops the struct name from a validator namespace, turning
+// "CreateRequestModel.armament[0].qty" into "armament[0].qty".
+func fieldPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return namespace
+	}
+
+	return path
+}
+
+func (v *Validator) message(fieldErr validator.FieldError) string {
+	isString := fieldErr.Kind() == reflect.String
+
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "max", "lte":
+		if isString {
+			return fmt.Sprintf("must be at most %s characters long", fieldErr.Param())
+		}
+		if fieldErr.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at most %s items", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be %s or less", fieldErr.Param())
+	case "min", "gte":
+		if isString {
+			return fmt.Sprintf("must be at least %s characters long", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be %s or greater", fieldErr.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fieldErr.Param())
+	case "url", "http_url":
+		return "must be a valid http or https URL"
+	}
+
+	if values, ok := v.enums[fieldErr.Tag()]; ok {
+		return fmt.Sprintf("must be one of: %s", strings.Join(values, ", "))
+	}
+
+	return fmt.Sprintf("failed on the %q rule", fieldErr.Tag())
+}
+
+// maxBodySize is the largest request body read, past which the request is
+// rejected with 413.
+const maxBodySize = 16 << 20
+
+// limitBody caps what is read from body at maxBodySize. Reading past it fails
+// with an *http.MaxBytesError.
+func limitBody(body io.Reader) io.Reader {
+	return http.MaxBytesReader(nil, io.NopCloser(body), maxBodySize)
+}
+
+// readError describes the failure to read a body capped by limitBody.
+func readError(err error) error {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return &Error{Kind: ErrRequestTooLarge, Message: fmt.Sprintf("body must be at most %d bytes", maxBodySize), Err: err}
+	}
+
+	return &Error{Kind: ErrBadRequest, Message: "unreadable body", Err: err}
+}
+
+// DecodeJSON strictly decodes a JSON body into v. Unknown fields and values
+// of the wrong type are reported as validation errors on that field, while
+// a malformed body is a bad request.
+func DecodeJSON(r io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(limitBody(r))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return readError(err)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field == "" {
+		return &Error{Kind: ErrBadRequest, Message: "body must be " + jsonType(reflect.TypeOf(v).Elem().Kind()), Err: err}
+	}
+
+	if errors.As(err, &typeErr) {
+		return NewValidationError("request validation failed", FieldError{
+			Field:   typeErr.Field,
+			Message: "must be " + jsonType(typeErr.Type.Kind()),
+		})
+	}
+
+	// encoding/json has no typed error for unknown fields.
+	if field, found := strings.CutPrefix(err.Error(), "json: unknown field "); found {
+		return NewValidationError("request validation failed", FieldError{
+			Field:   strings.Trim(field, `"`),
+			Message: "is not a known field",
+		})
+	}
+
+	return &Error{Kind: ErrBadRequest, Message: "malformed JSON body", Err: err}
+}
+
+// DecodeMergePatch decodes a JSON merge patch (RFC 7396) body into v, and
+// returns the members set to null. Those decode the same way as missing ones,
+// so they are looked for in the raw document.
+func DecodeMergePatch(r *http.Request, v interface{}) ([]string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		return nil, &Error{Kind: ErrUnsupportedMediaType, Message: "body must be application/merge-patch+json"}
+	}
+
+	body, err := io.ReadAll(limitBody(r.Body))
+	if err != nil {
+		return nil, readError(err)
+	}
+
+	return DecodeMergePatchBody(body, v)
+}
+
+// DecodeMergePatchBody is DecodeMergePatch for a document already read, such
+// as an item of a JSON array.
+func DecodeMergePatchBody(body []byte, v interface{}) ([]string, error) {
+	if err := DecodeJSON(bytes.NewReader(body), v); err != nil {
+		return nil, err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil {
+		return nil, &Error{Kind: ErrBadRequest, Message: "body must be a JSON object", Err: err}
+	}
+
+	var nulls []string
+	for member, value := range members {
+		if string(value) == "null" {
+			nulls = append(nulls, member)
+		}
+	}
+
+	return nulls, nil
+}
+
+func jsonType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	default:
+		return "a string"
+	}
+}
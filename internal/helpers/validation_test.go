@@ -0,0 +1,111 @@
+package helpers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	type body struct {
+		Name string `json:"name"`
+		Crew int64  `json:"crew"`
+	}
+
+	tests := []struct {
+		name      string
+		body      string
+		want      body
+		wantKind  error
+		wantField string
+	}{
+		{
+			name: "Given valid body, should decode it with nil error",
+			body: `{"name": "Devastator", "crew": 1200}`,
+			want: body{Name: "Devastator", Crew: 1200},
+		},
+		{
+			name:      "Given unknown field, should return validation error on that field",
+			body:      `{"name": "Devastator", "captain": "Piett"}`,
+			want:      body{Name: "Devastator"},
+			wantKind:  ErrValidation,
+			wantField: "captain",
+		},
+		{
+			name:      "Given value of the wrong type, should return validation error on that field",
+			body:      `{"name": "Devastator", "crew": "many"}`,
+			want:      body{Name: "Devastator"},
+			wantKind:  ErrValidation,
+			wantField: "crew",
+		},
+		{
+			name:     "Given malformed body, should return bad request error",
+			body:     `{"name": `,
+			wantKind: ErrBadRequest,
+		},
+		{
+			name:     "Given body past the size limit, should return request too large error",
+			body:     `{"name": "` + strings.Repeat("a", maxBodySize) + `"}`,
+			wantKind: ErrRequestTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got body
+			err := DecodeJSON(strings.NewReader(tt.body), &got)
+
+			assert.Equal(t, tt.want, got)
+			if tt.wantKind == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, tt.wantKind)
+			if tt.wantField != "" {
+				var domainErr *Error
+				assert.True(t, errors.As(err, &domainErr))
+				assert.Equal(t, tt.wantField, domainErr.Fields[0].Field)
+			}
+		})
+	}
+}
+
+func TestPrefixFieldErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "Given nil error, should return nil error",
+			err:  nil,
+			want: nil,
+		},
+		{
+			name: "Given validation error, should nest its fields under the prefix",
+			err: NewValidationError("request validation failed",
+				FieldError{Field: "name", Message: "is required"},
+				FieldError{Field: "armament[0].qty", Message: "must be 1 or greater"},
+			),
+			want: NewValidationError("request validation failed",
+				FieldError{Field: "create[2].name", Message: "is required"},
+				FieldError{Field: "create[2].armament[0].qty", Message: "must be 1 or greater"},
+			),
+		},
+		{
+			name: "Given another error, should return it as is",
+			err:  ErrBadRequest,
+			want: ErrBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PrefixFieldErrors(tt.err, "create[2]")
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	mathrand "math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/wndisra/galactic-svc/internal/config"
+)
+
+// RequestIDHeader carries the ID correlating the log entries of a request,
+// across the services it goes through.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the request IDs taken from callers.
+const maxRequestIDLength = 128
+
+// Handler serves the requests with router, logging each one once served:
+// method, route, status, latency and size of the response. The ID of the
+// request is taken from its X-Request-ID header, or generated, and sent back
+// in the response. A logger tagged with it is carried by the request context,
+// see FromContext().
+//
+// Requests are logged at cfg.RequestLevel, a share cfg.RequestSampleRatio of
+// them only. Requests failing with a server error are always logged, as
+// errors.
+func Handler(router *httprouter.Router, logger log.Logger, cfg config.LogConfig) http.Handler {
+	logAt := level.Info
+	if cfg.RequestLevel == "debug" {
+		logAt = level.Debug
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		begin := time.Now()
+
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+			r.Header.Set(RequestIDHeader, id)
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+
+		requestLogger := log.With(logger, "request_id", id)
+		ctx := context.WithValue(WithLogger(r.Context(), requestLogger), requestIDKey{}, id)
+
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		router.ServeHTTP(rw, r.WithContext(ctx))
+
+		var entry log.Logger
+		switch {
+		case rw.status >= http.StatusInternalServerError:
+			entry = level.Error(requestLogger)
+		case sampled(cfg.RequestSampleRatio):
+			entry = logAt(requestLogger)
+		default:
+			return
+		}
+
+		entry.Log(
+			"msg", "request served",
+			"method", r.Method,
+			"route", route(router, r),
+			"path", r.URL.Path,
+			"status", rw.status,
+			"bytes", rw.bytes,
+			"latency_ms", math.Round(float64(time.Since(begin).Microseconds()))/1000,
+		)
+	})
+}
+
+// validRequestID tells whether id, taken from a caller, is safe to log and to
+// send back: short and made of printable ASCII characters only.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+func sampled(ratio float64) bool {
+	return ratio >= 1 || mathrand.Float64() < ratio
+}
+
+// route returns the route pattern r matched, such as /spaceship/:id, keeping
+// the logs groupable by route. Unrouted requests have no route.
+func route(router *httprouter.Router, r *http.Request) string {
+	handle, params, _ := router.Lookup(r.Method, r.URL.Path)
+	if handle == nil {
+		return ""
+	}
+
+	segments := strings.Split(r.URL.Path, "/")
+	for _, param := range params {
+		if strings.HasPrefix(param.Value, "/") {
+			// Catch-all parameter, which takes the rest of the path
+			rest := strings.Count(param.Value, "/")
+			segments = append(segments[:len(segments)-rest], "*"+param.Key)
+			break
+		}
+
+		for i, segment := range segments {
+			if segment == param.Value && !strings.HasPrefix(segment, ":") {
+				segments[i] = ":" + param.Key
+				break
+			}
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// responseWriter records the status and the size of a response.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+
+	return n, err
+}
+
+// Flush sends the response written so far, for streamed responses.
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,217 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wndisra/galactic-svc/internal/config"
+)
+
+func setupRouter() *httprouter.Router {
+	router := httprouter.New()
+	router.Handler(http.MethodGet, "/spaceship/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		level.Info(FromContext(r.Context(), log.NewNopLogger())).Log("msg", "from the service")
+		w.Write([]byte(`{"id":1}`))
+	}))
+	router.Handler(http.MethodGet, "/spaceship/:id/armament/:armament_id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	router.Handler(http.MethodGet, "/swagger/*any", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	router.Handler(http.MethodDelete, "/spaceship/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	return router
+}
+
+// decodeEntries returns the JSON log entries written to buf.
+func decodeEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		requestID     string
+		cfg           config.LogConfig
+		wantRequestID string
+		wantEntries   int
+		wantLevel     string
+		wantRoute     string
+		wantStatus    float64
+		wantBytes     float64
+	}{
+		{
+			name:          "Given an X-Request-ID header, should propagate it to the logs and the response",
+			method:        http.MethodGet,
+			path:          "/spaceship/12",
+			requestID:     "frontend-3f2a",
+			cfg:           config.LogConfig{RequestLevel: "info", RequestSampleRatio: 1},
+			wantRequestID: "frontend-3f2a",
+			wantEntries:   2,
+			wantLevel:     "info",
+			wantRoute:     "/spaceship/:id",
+			wantStatus:    http.StatusOK,
+			wantBytes:     8,
+		},
+		{
+			name:        "Given no X-Request-ID header, should generate one",
+			method:      http.MethodGet,
+			path:        "/spaceship/12",
+			cfg:         config.LogConfig{RequestLevel: "debug", RequestSampleRatio: 1},
+			wantEntries: 2,
+			wantLevel:   "debug",
+			wantRoute:   "/spaceship/:id",
+			wantStatus:  http.StatusOK,
+			wantBytes:   8,
+		},
+		{
+			name:        "Given an X-Request-ID header unsafe to log, should replace it",
+			method:      http.MethodGet,
+			path:        "/spaceship/12",
+			requestID:   "forged\nlevel=error",
+			cfg:         config.LogConfig{RequestLevel: "info", RequestSampleRatio: 1},
+			wantEntries: 2,
+			wantLevel:   "info",
+			wantRoute:   "/spaceship/:id",
+			wantStatus:  http.StatusOK,
+			wantBytes:   8,
+		},
+		{
+			name:        "Given a request left out by sampling, should not log it",
+			method:      http.MethodGet,
+			path:        "/spaceship/12",
+			cfg:         config.LogConfig{RequestLevel: "info", RequestSampleRatio: 0},
+			wantEntries: 1,
+		},
+		{
+			name:        "Given a server error left out by sampling, should still log it as an error",
+			method:      http.MethodDelete,
+			path:        "/spaceship/12",
+			cfg:         config.LogConfig{RequestLevel: "info", RequestSampleRatio: 0},
+			wantEntries: 1,
+			wantLevel:   "error",
+			wantRoute:   "/spaceship/:id",
+			wantStatus:  http.StatusInternalServerError,
+		},
+		{
+			name:        "Given an unrouted request, should log it without route",
+			method:      http.MethodGet,
+			path:        "/starship/12",
+			cfg:         config.LogConfig{RequestLevel: "info", RequestSampleRatio: 1},
+			wantEntries: 1,
+			wantLevel:   "info",
+			wantStatus:  http.StatusNotFound,
+			wantBytes:   19,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler := Handler(setupRouter(), log.NewJSONLogger(&buf), tt.cfg)
+
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.requestID != "" {
+				r.Header.Set(RequestIDHeader, tt.requestID)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			requestID := w.Header().Get(RequestIDHeader)
+			if tt.wantRequestID != "" {
+				assert.Equal(t, tt.wantRequestID, requestID)
+			} else {
+				assert.Regexp(t, "^[0-9a-f]{32}$", requestID)
+			}
+
+			entries := decodeEntries(t, &buf)
+			if !assert.Len(t, entries, tt.wantEntries) {
+				return
+			}
+
+			for _, entry := range entries {
+				assert.Equal(t, requestID, entry["request_id"], "every entry of the request should carry its ID")
+			}
+
+			if tt.wantStatus == 0 {
+				assert.Equal(t, "from the service", entries[0]["msg"])
+				return
+			}
+
+			entry := entries[len(entries)-1]
+			assert.Equal(t, "request served", entry["msg"])
+			assert.Equal(t, tt.wantLevel, entry["level"])
+			assert.Equal(t, tt.method, entry["method"])
+			assert.Equal(t, tt.wantRoute, entry["route"])
+			assert.Equal(t, tt.path, entry["path"])
+			assert.Equal(t, tt.wantStatus, entry["status"])
+			assert.Equal(t, tt.wantBytes, entry["bytes"])
+			assert.Contains(t, entry, "latency_ms")
+		})
+	}
+}
+
+func TestRoute(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "Given a path with parameters, should return its route", path: "/spaceship/3/armament/4", want: "/spaceship/:id/armament/:armament_id"},
+		{name: "Given parameters of the same value, should replace each once", path: "/spaceship/3/armament/3", want: "/spaceship/:id/armament/:armament_id"},
+		{name: "Given a catch-all parameter, should replace the rest of the path", path: "/swagger/css/index.css", want: "/swagger/*any"},
+		{name: "Given an unrouted path, should return no route", path: "/starship", want: ""},
+	}
+
+	router := setupRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, route(router, httptest.NewRequest(http.MethodGet, tt.path, nil)))
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	fallback := log.NewNopLogger()
+	assert.Equal(t, fallback, FromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context(), fallback))
+}
+
+func TestHandler_Flush(t *testing.T) {
+	router := httprouter.New()
+	router.Handler(http.MethodGet, "/spaceship/export", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("id,name\n"))
+		if flusher, ok := w.(http.Flusher); assert.True(t, ok, "streamed responses should be flushable") {
+			flusher.Flush()
+		}
+	}))
+
+	w := httptest.NewRecorder()
+	Handler(router, log.NewNopLogger(), config.LogConfig{RequestLevel: "info", RequestSampleRatio: 1}).
+		ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/spaceship/export", nil))
+
+	assert.True(t, w.Flushed)
+}
@@ -0,0 +1,34 @@
+// Package logging logs the requests served, and carries a logger scoped to
+// each request, tagged with its ID, down to the services and repositories.
+package logging
+
+import (
+	"context"
+
+	"github.com/go-kit/log"
+)
+
+type loggerKey struct{}
+
+type requestIDKey struct{}
+
+// WithLogger returns ctx carrying logger, see FromContext().
+func WithLogger(ctx context.Context, logger log.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger of the request ctx belongs to, which tags
+// every entry with the request ID, or fallback outside of requests.
+func FromContext(ctx context.Context, fallback log.Logger) log.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(log.Logger); ok {
+		return logger
+	}
+
+	return fallback
+}
+
+// RequestID returns the ID of the request ctx belongs to, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/wndisra/galactic-svc/internal/helpers"
+)
+
+// outcomes label the errors of an endpoint after the status they are served
+// with, keeping the label set small.
+var outcomes = map[int]string{
+	http.StatusBadRequest:            "bad_request",
+	http.StatusUnauthorized:          "unauthorized",
+	http.StatusNotFound:              "not_found",
+	http.StatusConflict:              "conflict",
+	http.StatusPreconditionFailed:    "precondition_failed",
+	http.StatusUnsupportedMediaType:  "unsupported_media_type",
+	http.StatusRequestEntityTooLarge: "too_large",
+	http.StatusUnprocessableEntity:   "validation",
+
+	helpers.StatusClientClosedRequest: "cancelled",
+	http.StatusGatewayTimeout:         "timeout",
+}
+
+// Outcome labels the result of an endpoint: "success", the kind of a client
+// error such as "not_found", "cancelled", "timeout", or "error".
+func Outcome(err error) string {
+	if err == nil {
+		return "success"
+	}
+
+	if outcome, ok := outcomes[helpers.StatusCode(err)]; ok {
+		return outcome
+	}
+
+	return "error"
+}
+
+// Endpoint counts and times the requests served by the endpoint name, by
+// outcome. A nil Metrics leaves the endpoint as it is.
+func (m *Metrics) Endpoint(name string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		if m == nil {
+			return next
+		}
+
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func(begin time.Time) {
+				labels := []string{"endpoint", name, "outcome", Outcome(err)}
+				m.requests.With(labels...).Add(1)
+				m.duration.With(labels...).Observe(time.Since(begin).Seconds())
+			}(time.Now())
+
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Package metrics instruments the service for Prometheus: endpoint requests,
+// database queries and the connection pool, all served by Handler.
+package metrics
+
+import (
+	"net/http"
+
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "galactic"
+
+// dbBuckets are finer than the default buckets, most queries taking a few
+// milliseconds.
+var dbBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5}
+
+// Metrics holds the collectors of the service, on a registry of its own.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests *kitprometheus.Counter
+	duration *kitprometheus.Histogram
+
+	queries     *prometheus.HistogramVec
+	queryErrors *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+	}
+
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "endpoint",
+		Name:      "requests_total",
+		Help:      "Requests served by an endpoint, by outcome.",
+	}, []string{"endpoint", "outcome"})
+
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "endpoint",
+		Name:      "request_duration_seconds",
+		Help:      "Time taken by an endpoint to serve a request, by outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint", "outcome"})
+
+	m.queries = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Time taken by database queries, by table and operation.",
+		Buckets:   dbBuckets,
+	}, []string{"table", "operation"})
+
+	m.queryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_errors_total",
+		Help:      "Failed database queries, by table and operation. Records not found are not failures.",
+	}, []string{"table", "operation"})
+
+	m.registry.MustRegister(
+		requests,
+		duration,
+		m.queries,
+		m.queryErrors,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	m.requests = kitprometheus.NewCounter(requests)
+	m.duration = kitprometheus.NewHistogram(duration)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
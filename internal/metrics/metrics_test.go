@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/wndisra/galactic-svc/internal/helpers"
+)
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "Given no error, should be success", want: "success"},
+		{name: "Given a not found error, should be not_found", err: helpers.NewNotFoundError("spaceship not found"), want: "not_found"},
+		{name: "Given a validation error, should be validation", err: helpers.NewValidationError("invalid"), want: "validation"},
+		{name: "Given an invalid path param, should be bad_request", err: helpers.ErrInvalidPathParam, want: "bad_request"},
+		{name: "Given too large a body, should be too_large", err: helpers.ErrRequestTooLarge, want: "too_large"},
+		{name: "Given a cancelled request, should be cancelled", err: context.Canceled, want: "cancelled"},
+		{name: "Given a query past its deadline, should be timeout", err: helpers.NewInternalError(context.DeadlineExceeded), want: "timeout"},
+		{name: "Given an internal error, should be error", err: helpers.NewInternalError(errors.New("boom")), want: "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Outcome(tt.err))
+		})
+	}
+}
+
+func TestMetrics_Endpoint(t *testing.T) {
+	m := New()
+	e := m.Endpoint("spaceship.get_by_id")(func(ctx context.Context, request interface{}) (interface{}, error) {
+		if request == nil {
+			return nil, helpers.NewNotFoundError("spaceship not found")
+		}
+
+		return request, nil
+	})
+
+	e(context.Background(), 1)
+	e(context.Background(), 2)
+	e(context.Background(), nil)
+
+	want := `
+# HELP galactic_endpoint_requests_total Requests served by an endpoint, by outcome.
+# TYPE galactic_endpoint_requests_total counter
+galactic_endpoint_requests_total{endpoint="spaceship.get_by_id",outcome="not_found"} 1
+galactic_endpoint_requests_total{endpoint="spaceship.get_by_id",outcome="success"} 2
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.registry, strings.NewReader(want), "galactic_endpoint_requests_total"))
+	count, err := testutil.GatherAndCount(m.registry, "galactic_endpoint_request_duration_seconds")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestMetrics_Endpoint_Nil(t *testing.T) {
+	var m *Metrics
+	e := m.Endpoint("spaceship.create")(func(ctx context.Context, request interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+
+	got, err := e(context.Background(), nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", got)
+}
+
+func TestMetrics_GormPlugin(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{Conn: db, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := New()
+	assert.NoError(t, gormDB.Use(m.GormPlugin()))
+
+	type SpaceShip struct {
+		ID   uint
+		Name string
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `space_ships` WHERE `space_ships`.`id` = ?")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Devastator"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `space_ships` WHERE `space_ships`.`id` = ?")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).
+		WillReturnError(assert.AnError)
+
+	var ship SpaceShip
+	gormDB.First(&ship, 1)
+	gormDB.First(&ship, 2)
+	gormDB.Exec("SELECT RELEASE_LOCK(?)", "lock")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, 2, testutil.CollectAndCount(m.queries))
+
+	want := `
+# HELP galactic_db_query_errors_total Failed database queries, by table and operation. Records not found are not failures.
+# TYPE galactic_db_query_errors_total counter
+galactic_db_query_errors_total{operation="raw",table="raw"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.registry, strings.NewReader(want), "galactic_db_query_errors_total"))
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m := New()
+	m.RegisterPool(func() sql.DBStats {
+		return sql.DBStats{MaxOpenConnections: 25, OpenConnections: 3, InUse: 2, Idle: 1, WaitCount: 4, WaitDuration: 1500 * time.Millisecond}
+	})
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "galactic_db_pool_in_use_connections 2\n")
+	assert.Contains(t, w.Body.String(), "galactic_db_pool_wait_duration_seconds_total 1.5\n")
+	assert.Contains(t, w.Body.String(), "go_goroutines ")
+}
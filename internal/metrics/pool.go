@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector reads the state of a connection pool on every scrape.
+type poolCollector struct {
+	stats func() sql.DBStats
+
+	maxOpen, open, inUse, idle                      *prometheus.Desc
+	waitCount, waitDuration, idleClosed, lifeClosed *prometheus.Desc
+}
+
+// RegisterPool exposes the connection pool whose state stats returns, such
+// as database.Health.Stats.
+func (m *Metrics) RegisterPool(stats func() sql.DBStats) {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	m.registry.MustRegister(&poolCollector{
+		stats:        stats,
+		maxOpen:      desc("max_open_connections", "Maximum number of open connections, 0 being unlimited."),
+		open:         desc("open_connections", "Open connections, in use or idle."),
+		inUse:        desc("in_use_connections", "Connections in use."),
+		idle:         desc("idle_connections", "Idle connections."),
+		waitCount:    desc("wait_count_total", "Waits for a free connection."),
+		waitDuration: desc("wait_duration_seconds_total", "Time spent waiting for a free connection."),
+		idleClosed:   desc("max_idle_closed_total", "Connections closed for exceeding the maximum idle connections or idle time."),
+		lifeClosed:   desc("max_lifetime_closed_total", "Connections closed for exceeding their maximum lifetime."),
+	})
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.maxOpen, c.open, c.inUse, c.idle, c.waitCount, c.waitDuration, c.idleClosed, c.lifeClosed} {
+		ch <- d
+	}
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats()
+
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.idleClosed, prometheus.CounterValue, float64(stats.MaxIdleClosed+stats.MaxIdleTimeClosed))
+	ch <- prometheus.MustNewConstMetric(c.lifeClosed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+}
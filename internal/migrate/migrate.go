@@ -0,0 +1,255 @@
+// Package migrate applies the versioned schema migrations of the service and
+// records them in the schema_migrations table.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"gorm.io/gorm"
+)
+
+const (
+	// lockName is the MySQL named lock held while migrating, so that replicas
+	// starting at once take turns instead of racing.
+	lockName    = "galactic-svc.schema_migrations"
+	lockTimeout = time.Minute
+)
+
+var (
+	ErrIrreversible = errors.New("migration cannot be rolled back")
+	errLockTimeout  = errors.New("timed out waiting for another process to finish migrating")
+)
+
+const createTableQuery = "CREATE TABLE IF NOT EXISTS `schema_migrations` (" +
+	"`version` bigint unsigned NOT NULL," +
+	"`name` varchar(255) NOT NULL," +
+	"`applied_at` datetime(3) NOT NULL," +
+	"PRIMARY KEY (`version`))"
+
+// Migration is a versioned change of the schema. Versions order migrations
+// and must never be reused once released: a change to a released migration
+// goes in a new one.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error // returns ErrIrreversible when there is no way back
+}
+
+// Exec returns a migration step running statements in order.
+func Exec(statements ...string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// Status tells whether a migration is applied. Applied migrations unknown to
+// this build, left by a newer one, have no Name.
+type Status struct {
+	Version   uint
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type appliedMigration struct {
+	Version   uint
+	Name      string
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	logger     log.Logger
+}
+
+// New returns a Migrator for the migrations of the service.
+func New(db *gorm.DB, logger log.Logger) *Migrator {
+	return newMigrator(db, migrations(logger), logger)
+}
+
+func newMigrator(db *gorm.DB, migrations []Migration, logger log.Logger) *Migrator {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	return &Migrator{
+		db:         db,
+		migrations: sorted,
+		logger:     logger,
+	}
+}
+
+// Up applies every pending migration, in order of version. Each one runs in
+// a transaction along with its schema_migrations row; MySQL commits DDL
+// statements implicitly though, so a migration failing halfway may need
+// fixing by hand.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.locked(ctx, func(conn *gorm.DB, applied map[uint]appliedMigration) error {
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := migration.Up(tx); err != nil {
+					return err
+				}
+
+				return tx.Exec("INSERT INTO `schema_migrations` (`version`,`name`,`applied_at`) VALUES (?,?,?)", migration.Version, migration.Name, time.Now()).Error
+			})
+			if err != nil {
+				level.Error(m.logger).Log("msg", "migrate.Up(): failed to apply migration", "version", migration.Version, "name", migration.Name, "err", err)
+				return fmt.Errorf("migrate.Up(): migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+
+			level.Info(m.logger).Log("msg", "migration applied", "version", migration.Version, "name", migration.Name)
+		}
+
+		return nil
+	})
+}
+
+// Down rolls the last steps applied migrations back, latest first.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.locked(ctx, func(conn *gorm.DB, applied map[uint]appliedMigration) error {
+		versions := make([]uint, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		for i := 0; i < steps && i < len(versions); i++ {
+			migration, ok := m.find(versions[i])
+			if !ok {
+				return fmt.Errorf("migrate.Down(): migration %d is unknown to this build", versions[i])
+			}
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := migration.Down(tx); err != nil {
+					return err
+				}
+
+				return tx.Exec("DELETE FROM `schema_migrations` WHERE `version` = ?", migration.Version).Error
+			})
+			if err != nil {
+				level.Error(m.logger).Log("msg", "migrate.Down(): failed to roll migration back", "version", migration.Version, "name", migration.Name, "err", err)
+				return fmt.Errorf("migrate.Down(): migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+
+			level.Info(m.logger).Log("msg", "migration rolled back", "version", migration.Version, "name", migration.Name)
+		}
+
+		return nil
+	})
+}
+
+// Status lists the known and the applied migrations, in order of version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.locked(ctx, func(conn *gorm.DB, applied map[uint]appliedMigration) error {
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if a, ok := applied[migration.Version]; ok {
+				status.Applied, status.AppliedAt = true, a.AppliedAt
+			}
+
+			statuses = append(statuses, status)
+		}
+
+		for _, a := range applied {
+			if _, ok := m.find(a.Version); !ok {
+				statuses = append(statuses, Status{Version: a.Version, Applied: true, AppliedAt: a.AppliedAt})
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Check returns an error while some migration is pending, for readiness.
+func (m *Migrator) Check(ctx context.Context) error {
+	applied, err := m.applied(m.db.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("migrate.Check(): %w", err)
+	}
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			return fmt.Errorf("migrate.Check(): migration %d %s is pending", migration.Version, migration.Name)
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) find(version uint) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+
+	return Migration{}, false
+}
+
+// locked runs fn on a single connection holding the migration lock, with the
+// migrations applied so far.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *gorm.DB, applied map[uint]appliedMigration) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		var acquired sql.NullInt64
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", lockName, int(lockTimeout.Seconds())).Scan(&acquired).Error; err != nil {
+			return fmt.Errorf("migrate: failed to take the lock: %w", err)
+		}
+
+		if acquired.Int64 != 1 {
+			return errLockTimeout
+		}
+
+		defer conn.Exec("SELECT RELEASE_LOCK(?)", lockName)
+
+		if err := conn.Exec(createTableQuery).Error; err != nil {
+			return fmt.Errorf("migrate: failed to create schema_migrations: %w", err)
+		}
+
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		return fn(conn, applied)
+	})
+}
+
+func (m *Migrator) applied(db *gorm.DB) (map[uint]appliedMigration, error) {
+	var rows []appliedMigration
+	if err := db.Raw("SELECT `version`,`name`,`applied_at` FROM `schema_migrations` ORDER BY `version`").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("migrate: failed to read schema_migrations: %w", err)
+	}
+
+	applied := make(map[uint]appliedMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
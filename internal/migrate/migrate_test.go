@@ -0,0 +1,286 @@
+package migrate
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	lockQuery    = "SELECT GET_LOCK(?, ?)"
+	releaseQuery = "SELECT RELEASE_LOCK(?)"
+	appliedQuery = "SELECT `version`,`name`,`applied_at` FROM `schema_migrations` ORDER BY `version`"
+	insertQuery  = "INSERT INTO `schema_migrations` (`version`,`name`,`applied_at`) VALUES (?,?,?)"
+	deleteQuery  = "DELETE FROM `schema_migrations` WHERE `version` = ?"
+)
+
+func setupMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{Conn: db, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return gormDB, mock
+}
+
+var testMigrations = []Migration{
+	{
+		Version: 2,
+		Name:    "add_index",
+		Up:      Exec("CREATE INDEX `idx_crew` ON `space_ships` (`crew`)"),
+		Down:    Exec("DROP INDEX `idx_crew` ON `space_ships`"),
+	},
+	{
+		Version: 1,
+		Name:    "create_table",
+		Up:      Exec("CREATE TABLE `space_ships` (`id` bigint)"),
+		Down: func(tx *gorm.DB) error {
+			return ErrIrreversible
+		},
+	},
+}
+
+// expectLocked expects the lock to be taken and the applied versions to be
+// read, before the statements of a migration run.
+func expectLocked(mock sqlmock.Sqlmock, applied ...uint) {
+	mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).
+		WithArgs(lockName, 60).
+		WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta(createTableQuery)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rows := sqlmock.NewRows([]string{"version", "name", "applied_at"})
+	for _, version := range applied {
+		rows.AddRow(version, "", time.Now())
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(appliedQuery)).WillReturnRows(rows)
+}
+
+func expectReleased(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta(releaseQuery)).
+		WithArgs(lockName).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestMigrator_Up(t *testing.T) {
+	tests := []struct {
+		name    string
+		mocks   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "Given a lock held by another process, should return non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).
+					WithArgs(lockName, 60).
+					WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(0))
+			},
+			wantErr: errLockTimeout,
+		},
+		{
+			name: "Given a fresh database, should apply every migration in order",
+			mocks: func(mock sqlmock.Sqlmock) {
+				expectLocked(mock)
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE `space_ships`")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(insertQuery)).
+					WithArgs(1, "create_table", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX `idx_crew`")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(insertQuery)).
+					WithArgs(2, "add_index", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				expectReleased(mock)
+			},
+		},
+		{
+			name: "Given applied migrations, should only apply the pending ones",
+			mocks: func(mock sqlmock.Sqlmock) {
+				expectLocked(mock, 1)
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX `idx_crew`")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(insertQuery)).
+					WithArgs(2, "add_index", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				expectReleased(mock)
+			},
+		},
+		{
+			name: "Got error applying a migration, should stop and return non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				expectLocked(mock)
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE `space_ships`")).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+				expectReleased(mock)
+			},
+			wantErr: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			tt.mocks(mock)
+
+			m := newMigrator(db, testMigrations, log.NewNopLogger())
+			err := m.Up(context.Background())
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestMigrator_Down(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   int
+		mocks   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name:  "Given applied migrations, should roll the latest back",
+			steps: 1,
+			mocks: func(mock sqlmock.Sqlmock) {
+				expectLocked(mock, 1, 2)
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("DROP INDEX `idx_crew`")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				expectReleased(mock)
+			},
+		},
+		{
+			name:  "Given an irreversible migration, should return non-nil error",
+			steps: 2,
+			mocks: func(mock sqlmock.Sqlmock) {
+				expectLocked(mock, 1, 2)
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("DROP INDEX `idx_crew`")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+				expectReleased(mock)
+			},
+			wantErr: ErrIrreversible,
+		},
+		{
+			name:  "Given no applied migration, should do nothing",
+			steps: 1,
+			mocks: func(mock sqlmock.Sqlmock) {
+				expectLocked(mock)
+				expectReleased(mock)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			tt.mocks(mock)
+
+			m := newMigrator(db, testMigrations, log.NewNopLogger())
+			err := m.Down(context.Background(), tt.steps)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestMigrator_Status(t *testing.T) {
+	db, mock := setupMockDB(t)
+	expectLocked(mock, 1, 7)
+	expectReleased(mock)
+
+	m := newMigrator(db, testMigrations, log.NewNopLogger())
+	got, err := m.Status(context.Background())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, err)
+	assert.Len(t, got, 3)
+	assert.Equal(t, Status{Version: 1, Name: "create_table", Applied: true, AppliedAt: got[0].AppliedAt}, got[0])
+	assert.Equal(t, Status{Version: 2, Name: "add_index"}, got[1])
+	assert.Equal(t, uint(7), got[2].Version)
+	assert.Empty(t, got[2].Name)
+}
+
+func TestMigrator_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied []uint
+		wantErr string
+	}{
+		{
+			name:    "Given a pending migration, should return non-nil error",
+			applied: []uint{1},
+			wantErr: "migration 2 add_index is pending",
+		},
+		{
+			name:    "Given every migration applied, should return nil",
+			applied: []uint{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+
+			rows := sqlmock.NewRows([]string{"version", "name", "applied_at"})
+			for _, version := range tt.applied {
+				rows.AddRow(version, "", time.Now())
+			}
+			mock.ExpectQuery(regexp.QuoteMeta(appliedQuery)).WillReturnRows(rows)
+
+			m := newMigrator(db, testMigrations, log.NewNopLogger())
+			err := m.Check(context.Background())
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	all := migrations(log.NewNopLogger())
+
+	for i, migration := range all {
+		assert.Equal(t, uint(i+1), migration.Version, "versions must follow each other")
+		assert.NotEmpty(t, migration.Name)
+		assert.NotNil(t, migration.Up)
+		assert.NotNil(t, migration.Down)
+	}
+}
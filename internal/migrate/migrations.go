@@ -0,0 +1,91 @@
+package migrate
+
+import (
+	"github.com/go-kit/log"
+	"gorm.io/gorm"
+
+	"github.com/wndisra/galactic-svc/internal/repository/database"
+)
+
+// migrations lists the migrations of the service. New ones are appended with
+// the next version.
+func migrations(logger log.Logger) []Migration {
+	return []Migration{
+		{
+			Version: 1,
+			Name:    "create_tables",
+			Up:      createTables,
+			Down: Exec(
+				"DROP TABLE IF EXISTS `idempotency_keys`",
+				"DROP TABLE IF EXISTS `armaments`",
+				"DROP TABLE IF EXISTS `weapons`",
+				"DROP TABLE IF EXISTS `space_ships`",
+			),
+		},
+		{
+			Version: 2,
+			Name:    "link_armaments_to_weapons",
+			Up: func(tx *gorm.DB) error {
+				return database.MigrateArmamentWeapons(tx, logger)
+			},
+			Down: func(tx *gorm.DB) error {
+				return ErrIrreversible
+			},
+		},
+		{
+			Version: 3,
+			Name:    "drop_armament_titles",
+			Up: func(tx *gorm.DB) error {
+				return database.DropArmamentTitles(tx, logger)
+			},
+			Down: func(tx *gorm.DB) error {
+				return ErrIrreversible
+			},
+		},
+	}
+}
+
+// createTables creates the schema AutoMigrate used to maintain. Databases it
+// created are adopted as they are, adding the columns older releases lacked.
+func createTables(tx *gorm.DB) error {
+	err := Exec(
+		"CREATE TABLE IF NOT EXISTS `space_ships` (`id` bigint unsigned AUTO_INCREMENT,`created_at` datetime(3) NULL,`updated_at` datetime(3) NULL,`deleted_at` datetime(3) NULL,"+
+			"`name` longtext,`class` longtext,`crew` bigint,`image` longtext,`value` double,`status` longtext,`version` bigint unsigned NOT NULL DEFAULT 1,"+
+			"PRIMARY KEY (`id`),INDEX `idx_space_ships_deleted_at` (`deleted_at`))",
+		"CREATE TABLE IF NOT EXISTS `weapons` (`id` bigint unsigned AUTO_INCREMENT,`created_at` datetime(3) NULL,`updated_at` datetime(3) NULL,`deleted_at` datetime(3) NULL,"+
+			"`name` longtext,`normalized_name` varchar(100),`damage` bigint,`range` double,`cost` double,"+
+			"PRIMARY KEY (`id`),INDEX `idx_weapons_deleted_at` (`deleted_at`),UNIQUE INDEX `idx_weapons_normalized_name` (`normalized_name`))",
+		"CREATE TABLE IF NOT EXISTS `armaments` (`id` bigint unsigned AUTO_INCREMENT,`created_at` datetime(3) NULL,`updated_at` datetime(3) NULL,`deleted_at` datetime(3) NULL,"+
+			"`space_ship_id` bigint unsigned,`weapon_id` bigint unsigned,`qty` bigint,"+
+			"PRIMARY KEY (`id`),INDEX `idx_armaments_deleted_at` (`deleted_at`),"+
+			"CONSTRAINT `fk_armaments_weapon` FOREIGN KEY (`weapon_id`) REFERENCES `weapons`(`id`),"+
+			"CONSTRAINT `fk_space_ships_armaments` FOREIGN KEY (`space_ship_id`) REFERENCES `space_ships`(`id`))",
+		"CREATE TABLE IF NOT EXISTS `idempotency_keys` (`key` varchar(255),`request_hash` varchar(64),`status` bigint,`header` text,`body` longblob,"+
+			"`created_at` datetime(3) NULL,`expires_at` datetime(3) NULL,"+
+			"PRIMARY KEY (`key`),INDEX `idx_idempotency_keys_expires_at` (`expires_at`))",
+	)(tx)
+	if err != nil {
+		return err
+	}
+
+	columns := []struct {
+		table  string
+		column string
+		add    string
+	}{
+		{"space_ships", "version", "ALTER TABLE `space_ships` ADD `version` bigint unsigned NOT NULL DEFAULT 1"},
+		{"armaments", "weapon_id", "ALTER TABLE `armaments` ADD `weapon_id` bigint unsigned, ADD CONSTRAINT `fk_armaments_weapon` FOREIGN KEY (`weapon_id`) REFERENCES `weapons`(`id`)"},
+	}
+
+	for _, c := range columns {
+		if tx.Migrator().HasColumn(c.table, c.column) {
+			continue
+		}
+
+		if err := tx.Exec(c.add).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"gorm.io/gorm"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+	"github.com/wndisra/galactic-svc/internal/logging"
+)
+
+type armamentRepository struct {
+	db       *gorm.DB
+	timeouts QueryTimeouts
+	logger   log.Logger
+}
+
+func NewArmamentRepository(db *gorm.DB, timeouts QueryTimeouts, logger log.Logger) *armamentRepository {
+	return &armamentRepository{
+		db:       db,
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+func (r *armamentRepository) conn(ctx context.Context) *gorm.DB {
+	return connFromContext(ctx, r.db)
+}
+
+// log returns the logger of the request ctx belongs to.
+func (r *armamentRepository) log(ctx context.Context) log.Logger {
+	return logging.FromContext(ctx, r.logger)
+}
+
+// GetAll returns the armaments of a spaceship, oldest first.
+func (r *armamentRepository) GetAll(ctx context.Context, spaceshipID int64) ([]entity.Armament, error) {
+	ctx, cancel := bound(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var armaments []entity.Armament
+
+	result := r.conn(ctx).Preload("Weapon").Where("space_ship_id = ?", spaceshipID).Order("id").Find(&armaments)
+
+	err := result.Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		level.Error(r.log(ctx)).Log("msg", "database.armamentRepository.GetAll(): failed to fetch from database", "spaceship_id", spaceshipID, "err", err)
+		return nil, err
+	}
+
+	return armaments, nil
+}
+
+// GetByID returns an armament of a spaceship, or an empty one when the
+// spaceship has no such armament.
+func (r *armamentRepository) GetByID(ctx context.Context, spaceshipID int64, id int64) (entity.Armament, error) {
+	ctx, cancel := bound(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var armament entity.Armament
+
+	result := r.conn(ctx).Preload("Weapon").First(&armament, "id = ? AND space_ship_id = ?", id, spaceshipID)
+
+	err := result.Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		level.Error(r.log(ctx)).Log("msg", "database.armamentRepository.GetByID(): failed to fetch from database", "spaceship_id", spaceshipID, "armament_id", id, "err", err)
+		return entity.Armament{}, err
+	}
+
+	return armament, nil
+}
+
+// Insert adds an armament to the spaceship set in req and returns it with its
+// new ID.
+func (r *armamentRepository) Insert(ctx context.Context, req entity.Armament) (entity.Armament, error) {
+	ctx, cancel := bound(ctx, r.timeouts.Write)
+	defer cancel()
+
+	armament := entity.Armament{
+		SpaceShipID: req.SpaceShipID,
+		WeaponID:    req.WeaponID,
+		Qty:         req.Qty,
+	}
+
+	result := r.conn(ctx).Create(&armament)
+	if result.Error != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.armamentRepository.Insert(): failed to insert to database", "spaceship_id", req.SpaceShipID, "err", result.Error)
+		return entity.Armament{}, result.Error
+	}
+
+	return armament, nil
+}
+
+// Update overwrites the weapon and quantity of an armament in place, keeping
+// its ID.
+func (r *armamentRepository) Update(ctx context.Context, req entity.Armament) error {
+	ctx, cancel := bound(ctx, r.timeouts.Write)
+	defer cancel()
+
+	model := entity.Armament{Model: gorm.Model{ID: req.ID}}
+
+	result := r.conn(ctx).Model(&model).Select("weapon_id", "qty").Updates(req)
+	if result.Error != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.armamentRepository.Update(): failed to update data in database", "armament_id", req.ID, "err", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *armamentRepository) Delete(ctx context.Context, spaceshipID int64, id int64) error {
+	ctx, cancel := bound(ctx, r.timeouts.Write)
+	defer cancel()
+
+	var model entity.Armament
+
+	result := r.conn(ctx).Delete(&model, "id = ? AND space_ship_id = ?", id, spaceshipID)
+	err := result.Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		level.Error(r.log(ctx)).Log("msg", "database.armamentRepository.Delete(): failed to delete data in database", "spaceship_id", spaceshipID, "armament_id", id, "err", err)
+		return err
+	}
+
+	return nil
+}
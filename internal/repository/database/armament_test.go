@@ -0,0 +1,337 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+)
+
+func TestNewArmamentRepository(t *testing.T) {
+	logger := setupMockLogger()
+	mockDB, _ := setupMockDB()
+	timeouts := QueryTimeouts{Read: time.Second, Write: 2 * time.Second}
+	expected := &armamentRepository{
+		db:       mockDB,
+		timeouts: timeouts,
+		logger:   logger,
+	}
+
+	got := NewArmamentRepository(mockDB, timeouts, logger)
+	assert.NotNil(t, got)
+	assert.Equal(t, expected, got)
+}
+
+func TestArmamentRepository_GetAll(t *testing.T) {
+	query := "SELECT * FROM `armaments` WHERE space_ship_id = ? AND `armaments`.`deleted_at` IS NULL ORDER BY id"
+	weaponsQuery := "SELECT * FROM `weapons` WHERE `weapons`.`id` IN (?,?) AND `weapons`.`deleted_at` IS NULL"
+
+	tests := []struct {
+		name    string
+		id      int64
+		mocks   func(mock sqlmock.Sqlmock)
+		want    []entity.Armament
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return nil slice with non-nil error",
+			id:   1,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(1).
+					WillReturnError(assert.AnError)
+			},
+			want:    nil,
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given existed spaceship ID, should return its armaments with nil error",
+			id:   3,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(3).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "weapon_id", "qty", "space_ship_id"}).
+						AddRow(5, 1, 60, 3).
+						AddRow(6, 2, 10, 3))
+				mock.ExpectQuery(regexp.QuoteMeta(weaponsQuery)).
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+						AddRow(1, "Turbo Laser").
+						AddRow(2, "Ion Cannon"))
+			},
+			want: []entity.Armament{
+				{Model: gorm.Model{ID: 5}, WeaponID: 1, Weapon: entity.Weapon{Model: gorm.Model{ID: 1}, Name: "Turbo Laser"}, Qty: 60, SpaceShipID: 3},
+				{Model: gorm.Model{ID: 6}, WeaponID: 2, Weapon: entity.Weapon{Model: gorm.Model{ID: 2}, Name: "Ion Cannon"}, Qty: 10, SpaceShipID: 3},
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &armamentRepository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			got, err := r.GetAll(context.Background(), tt.id)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestArmamentRepository_GetByID(t *testing.T) {
+	query := "SELECT * FROM `armaments` WHERE (id = ? AND space_ship_id = ?) AND `armaments`.`deleted_at` IS NULL ORDER BY `armaments`.`id` LIMIT 1"
+	weaponQuery := "SELECT * FROM `weapons` WHERE `weapons`.`id` = ? AND `weapons`.`deleted_at` IS NULL"
+
+	tests := []struct {
+		name    string
+		id      int64
+		mocks   func(mock sqlmock.Sqlmock)
+		want    entity.Armament
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return empty struct with non-nil error",
+			id:   1,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(1, 3).
+					WillReturnError(assert.AnError)
+			},
+			want:    entity.Armament{},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given armament of another spaceship, should return empty struct with nil error",
+			id:   2,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(2, 3).
+					WillReturnError(gorm.ErrRecordNotFound)
+			},
+			want:    entity.Armament{},
+			wantErr: nil,
+		},
+		{
+			name: "Given existed ID, should return non-empty struct with nil error",
+			id:   5,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(5, 3).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "weapon_id", "qty", "space_ship_id"}).
+						AddRow(5, 1, 60, 3))
+				mock.ExpectQuery(regexp.QuoteMeta(weaponQuery)).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+						AddRow(1, "Turbo Laser"))
+			},
+			want:    entity.Armament{Model: gorm.Model{ID: 5}, WeaponID: 1, Weapon: entity.Weapon{Model: gorm.Model{ID: 1}, Name: "Turbo Laser"}, Qty: 60, SpaceShipID: 3},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &armamentRepository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			got, err := r.GetByID(context.Background(), 3, tt.id)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestArmamentRepository_Insert(t *testing.T) {
+	query := "INSERT INTO `armaments` (`created_at`,`updated_at`,`deleted_at`,`space_ship_id`,`weapon_id`,`qty`) VALUES (?,?,?,?,?,?)"
+
+	tests := []struct {
+		name    string
+		mocks   func(mock sqlmock.Sqlmock)
+		wantID  uint
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 3, 2, 10).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantID:  0,
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given valid param with no Gorm error, should return the armament with its new ID",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 3, 2, 10).
+					WillReturnResult(sqlmock.NewResult(7, 1))
+				mock.ExpectCommit()
+			},
+			wantID:  7,
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &armamentRepository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			got, err := r.Insert(context.Background(), entity.Armament{WeaponID: 2, Qty: 10, SpaceShipID: 3})
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.wantID, got.ID)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestArmamentRepository_Update(t *testing.T) {
+	query := "UPDATE `armaments` SET `updated_at`=?,`weapon_id`=?,`qty`=? WHERE `armaments`.`deleted_at` IS NULL AND `id` = ?"
+
+	tests := []struct {
+		name    string
+		mocks   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(sqlmock.AnyArg(), 1, 80, 5).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given valid param, should update the armament in place",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(sqlmock.AnyArg(), 1, 80, 5).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &armamentRepository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			err := r.Update(context.Background(), entity.Armament{Model: gorm.Model{ID: 5}, WeaponID: 1, Qty: 80, SpaceShipID: 3})
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestArmamentRepository_Delete(t *testing.T) {
+	query := "UPDATE `armaments` SET `deleted_at`=? WHERE (id = ? AND space_ship_id = ?) AND `armaments`.`deleted_at` IS NULL"
+
+	tests := []struct {
+		name    string
+		mocks   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(sqlmock.AnyArg(), 5, 3).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given valid param with no error, should return nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(sqlmock.AnyArg(), 5, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &armamentRepository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			err := r.Delete(context.Background(), 3, 5)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/wndisra/galactic-svc/internal/config"
+)
+
+// maxConnectBackoff caps the wait between two connection attempts.
+const maxConnectBackoff = 30 * time.Second
+
+// Open connects to the MySQL database of cfg and tunes its connection pool.
+// A database which is not up yet is retried up to cfg.ConnectAttempts times,
+// waiting cfg.ConnectBackoff after the first failure and twice as long after
+// every next one.
+func Open(ctx context.Context, cfg config.DBConfig, logger log.Logger) (*gorm.DB, error) {
+	return open(ctx, mysql.Open(cfg.DSN()), cfg, logger)
+}
+
+func open(ctx context.Context, dialector gorm.Dialector, cfg config.DBConfig, logger log.Logger) (*gorm.DB, error) {
+	var (
+		db  *gorm.DB
+		err error
+	)
+
+	backoff := cfg.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		if db == nil {
+			db, err = gorm.Open(dialector, &gorm.Config{DisableAutomaticPing: true})
+			if err != nil {
+				db = nil
+			}
+		}
+
+		if db != nil {
+			err = ping(ctx, db, cfg.PingTimeout)
+		}
+
+		if err == nil {
+			break
+		}
+
+		if attempt >= cfg.ConnectAttempts {
+			level.Error(logger).Log("msg", "database.Open(): failed to connect to database", "attempts", attempt, "err", err)
+			return nil, fmt.Errorf("database.Open(): %w", err)
+		}
+
+		level.Warn(logger).Log("msg", "database.Open(): database unavailable, retrying", "attempt", attempt, "backoff", backoff, "err", err)
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("database.Open(): %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, maxConnectBackoff)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("database.Open(): %w", err)
+	}
+
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	if err := db.Use(contextErrors{}); err != nil {
+		return nil, fmt.Errorf("database.Open(): %w", err)
+	}
+
+	return db, nil
+}
+
+func ping(ctx context.Context, db *gorm.DB, timeout time.Duration) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return sqlDB.PingContext(ctx)
+}
+
+// Health reports on the connection pool of a database opened by Open.
+type Health struct {
+	db          *gorm.DB
+	pingTimeout time.Duration
+}
+
+func NewHealth(db *gorm.DB, pingTimeout time.Duration) *Health {
+	return &Health{
+		db:          db,
+		pingTimeout: pingTimeout,
+	}
+}
+
+// Ready pings the database, giving up after the ping timeout, and returns nil
+// when it answers.
+func (h *Health) Ready(ctx context.Context) error {
+	if err := ping(ctx, h.db, h.pingTimeout); err != nil {
+		return fmt.Errorf("database.Health.Ready(): %w", err)
+	}
+
+	return nil
+}
+
+// Stats returns the state of the connection pool, such as the connections
+// in use or idle and the number of waits for a free one.
+func (h *Health) Stats() sql.DBStats {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return sql.DBStats{}
+	}
+
+	return sqlDB.Stats()
+}
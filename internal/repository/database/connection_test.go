@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/wndisra/galactic-svc/internal/config"
+)
+
+func setupPingingMockDB(t *testing.T) (gorm.Dialector, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return mysql.New(mysql.Config{Conn: db, SkipInitializeWithVersion: true}), mock
+}
+
+func TestOpen(t *testing.T) {
+	cfg := config.Default().DB
+	cfg.ConnectAttempts = 3
+	cfg.ConnectBackoff = time.Millisecond
+	cfg.MaxOpenConns = 7
+
+	tests := []struct {
+		name    string
+		mocks   func(mock sqlmock.Sqlmock)
+		ctx     func() context.Context
+		wantErr error
+	}{
+		{
+			name: "Given a database up at once, should connect",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectPing()
+			},
+		},
+		{
+			name: "Given a database coming up late, should retry until it answers",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectPing().WillReturnError(assert.AnError)
+				mock.ExpectPing().WillReturnError(assert.AnError)
+				mock.ExpectPing()
+			},
+		},
+		{
+			name: "Got error on every attempt, should return non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectPing().WillReturnError(assert.AnError)
+				mock.ExpectPing().WillReturnError(assert.AnError)
+				mock.ExpectPing().WillReturnError(assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name:  "Given a cancelled context, should stop retrying",
+			mocks: func(mock sqlmock.Sqlmock) {},
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			wantErr: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialector, mock := setupPingingMockDB(t)
+			tt.mocks(mock)
+
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx()
+			}
+
+			got, err := open(ctx, dialector, cfg, setupMockLogger())
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+
+			assert.NoError(t, err)
+			sqlDB, _ := got.DB()
+			assert.Equal(t, 7, sqlDB.Stats().MaxOpenConnections)
+		})
+	}
+}
+
+func TestHealth_Ready(t *testing.T) {
+	tests := []struct {
+		name    string
+		mocks   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "Got error pinging the database, should return non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectPing().WillReturnError(assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given a database answering, should return nil",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectPing()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialector, mock := setupPingingMockDB(t)
+			db, err := gorm.Open(dialector, &gorm.Config{DisableAutomaticPing: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.mocks(mock)
+
+			h := NewHealth(db, time.Second)
+			err = h.Ready(context.Background())
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, 0, h.Stats().InUse)
+		})
+	}
+}
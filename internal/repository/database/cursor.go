@@ -0,0 +1,218 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+	"github.com/wndisra/galactic-svc/internal/helpers"
+)
+
+var errInvalidCursor = &helpers.Error{Kind: helpers.ErrInvalidQueryParam, Message: "malformed cursor"}
+
+// sortColumn describes a column a listing can be ordered, and therefore
+// paginated, by.
+type sortColumn struct {
+	name string
+	// value reads the column value from a row, to be stored in a cursor.
+	value func(spaceship entity.SpaceShip) interface{}
+	// target returns a pointer the cursor value is decoded into.
+	target func() interface{}
+}
+
+var (
+	idColumn = sortColumn{
+		name:   "id",
+		value:  func(s entity.SpaceShip) interface{} { return s.ID },
+		target: func() interface{} { return new(uint) },
+	}
+	createdAtColumn = sortColumn{
+		name:   "created_at",
+		value:  func(s entity.SpaceShip) interface{} { return s.CreatedAt },
+		target: func() interface{} { return new(time.Time) },
+	}
+)
+
+// sortColumns is the whitelist of columns a spaceship listing can be sorted
+// by, keyed by the field name clients use.
+var sortColumns = map[string]sortColumn{
+	"id":         idColumn,
+	"created_at": createdAtColumn,
+	"updated_at": {
+		name:   "updated_at",
+		value:  func(s entity.SpaceShip) interface{} { return s.UpdatedAt },
+		target: func() interface{} { return new(time.Time) },
+	},
+	"name": {
+		name:   "name",
+		value:  func(s entity.SpaceShip) interface{} { return s.Name },
+		target: func() interface{} { return new(string) },
+	},
+	"class": {
+		name:   "class",
+		value:  func(s entity.SpaceShip) interface{} { return s.Class },
+		target: func() interface{} { return new(string) },
+	},
+	"crew": {
+		name:   "crew",
+		value:  func(s entity.SpaceShip) interface{} { return s.Crew },
+		target: func() interface{} { return new(int64) },
+	},
+	"value": {
+		name:   "value",
+		value:  func(s entity.SpaceShip) interface{} { return s.Value },
+		target: func() interface{} { return new(float64) },
+	},
+	"status": {
+		name:   "status",
+		value:  func(s entity.SpaceShip) interface{} { return s.Status },
+		target: func() interface{} { return new(string) },
+	},
+}
+
+type sortKey struct {
+	column sortColumn
+	desc   bool
+}
+
+// ordering is the list of keys a listing is sorted by. It always ends with
+// the primary key, so that the order is total and stable between pages.
+type ordering []sortKey
+
+var defaultOrdering = ordering{
+	{column: createdAtColumn},
+	{column: idColumn},
+}
+
+// newOrdering validates the requested sort fields against the whitelist and
+// appends the primary key as a tie-breaker when it is missing.
+func newOrdering(fields []entity.SortField) (ordering, error) {
+	if len(fields) == 0 {
+		return defaultOrdering, nil
+	}
+
+	o := make(ordering, 0, len(fields)+1)
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		column, ok := sortColumns[field.Field]
+		if !ok {
+			return nil, &helpers.Error{Kind: helpers.ErrInvalidQueryParam, Message: fmt.Sprintf("unsupported sort field %q", field.Field)}
+		}
+
+		if seen[column.name] {
+			return nil, &helpers.Error{Kind: helpers.ErrInvalidQueryParam, Message: fmt.Sprintf("duplicated sort field %q", field.Field)}
+		}
+		seen[column.name] = true
+
+		o = append(o, sortKey{column: column, desc: field.Desc})
+	}
+
+	if !seen[idColumn.name] {
+		o = append(o, sortKey{column: idColumn})
+	}
+
+	return o, nil
+}
+
+func (o ordering) String() string {
+	keys := make([]string, len(o))
+	for i, key := range o {
+		keys[i] = key.column.name
+		if key.desc {
+			keys[i] = "-" + keys[i]
+		}
+	}
+
+	return strings.Join(keys, ",")
+}
+
+func (o ordering) orderBy() clause.OrderBy {
+	columns := make([]clause.OrderByColumn, len(o))
+	for i, key := range o {
+		columns[i] = clause.OrderByColumn{Column: clause.Column{Name: key.column.name}, Desc: key.desc}
+	}
+
+	return clause.OrderBy{Columns: columns}
+}
+
+// cursor is the decoded form of the opaque token handed to clients. It holds
+// the sort key values of the last row of a page.
+type cursor struct {
+	Order  string            `json:"o"`
+	Values []json.RawMessage `json:"v"`
+}
+
+func encodeCursor(o ordering, last entity.SpaceShip) (string, error) {
+	c := cursor{
+		Order:  o.String(),
+		Values: make([]json.RawMessage, len(o)),
+	}
+
+	for i, key := range o {
+		value, err := json.Marshal(key.column.value(last))
+		if err != nil {
+			return "", err
+		}
+		c.Values[i] = value
+	}
+
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// after decodes token and builds the condition selecting the rows that come
+// after it in the given ordering.
+func (o ordering) after(token string) (clause.Expression, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, errInvalidCursor
+	}
+
+	if c.Order != o.String() || len(c.Values) != len(o) {
+		return nil, errInvalidCursor
+	}
+
+	values := make([]interface{}, len(o))
+	for i, key := range o {
+		target := key.column.target()
+		if err := json.Unmarshal(c.Values[i], target); err != nil {
+			return nil, errInvalidCursor
+		}
+		values[i] = reflect.ValueOf(target).Elem().Interface()
+	}
+
+	// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... with < for descending keys.
+	branches := make([]clause.Expression, len(o))
+	for i, key := range o {
+		exprs := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			exprs = append(exprs, clause.Eq{Column: clause.Column{Name: o[j].column.name}, Value: values[j]})
+		}
+
+		column := clause.Column{Name: key.column.name}
+		if key.desc {
+			exprs = append(exprs, clause.Lt{Column: column, Value: values[i]})
+		} else {
+			exprs = append(exprs, clause.Gt{Column: column, Value: values[i]})
+		}
+
+		branches[i] = clause.And(exprs...)
+	}
+
+	return clause.Or(branches...), nil
+}
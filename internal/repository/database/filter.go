@@ -0,0 +1,104 @@
+package database
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+)
+
+// likeEscaper escapes the characters that carry a meaning inside a LIKE
+// pattern, so user input is always matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// equal matches rows where column is exactly value.
+func equal(column string, value interface{}) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{Column: clause.Column{Name: column}, Value: value})
+	}
+}
+
+// in matches rows where column is any of values. A single value is turned
+// into an equality check.
+func in(column string, values []string) func(db *gorm.DB) *gorm.DB {
+	if len(values) == 1 {
+		return equal(column, values[0])
+	}
+
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.IN{Column: clause.Column{Name: column}, Values: args})
+	}
+}
+
+// contains matches rows where column contains value as a substring.
+func contains(column string, value string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Like{Column: clause.Column{Name: column}, Value: "%" + likeEscaper.Replace(value) + "%"})
+	}
+}
+
+// atLeast matches rows where column is greater than or equal to value.
+func atLeast(column string, value interface{}) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Gte{Column: clause.Column{Name: column}, Value: value})
+	}
+}
+
+// atMost matches rows where column is less than or equal to value.
+func atMost(column string, value interface{}) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Lte{Column: clause.Column{Name: column}, Value: value})
+	}
+}
+
+// withDeleted also matches soft deleted rows.
+func withDeleted(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// spaceShipScopes turns a filter into the list of conditions to apply on a
+// spaceship query. Every value is sent as a bound parameter.
+func spaceShipScopes(filter entity.SpaceShipFilter) []func(db *gorm.DB) *gorm.DB {
+	var scopes []func(db *gorm.DB) *gorm.DB
+
+	if filter.Name != "" {
+		scopes = append(scopes, contains("name", filter.Name))
+	}
+
+	if len(filter.Classes) > 0 {
+		scopes = append(scopes, in("class", filter.Classes))
+	}
+
+	if len(filter.Statuses) > 0 {
+		scopes = append(scopes, in("status", filter.Statuses))
+	}
+
+	if filter.MinCrew != nil {
+		scopes = append(scopes, atLeast("crew", *filter.MinCrew))
+	}
+
+	if filter.MaxCrew != nil {
+		scopes = append(scopes, atMost("crew", *filter.MaxCrew))
+	}
+
+	if filter.MinValue != nil {
+		scopes = append(scopes, atLeast("value", *filter.MinValue))
+	}
+
+	if filter.MaxValue != nil {
+		scopes = append(scopes, atMost("value", *filter.MaxValue))
+	}
+
+	if filter.IncludeDeleted {
+		scopes = append(scopes, withDeleted)
+	}
+
+	return scopes
+}
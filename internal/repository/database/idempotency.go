@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+	"github.com/wndisra/galactic-svc/internal/logging"
+)
+
+// idempotencyRepository stores the responses to idempotent requests. It
+// never joins the transaction carried by ctx, as a key must be claimed before
+// the request runs and released whatever became of it.
+type idempotencyRepository struct {
+	db       *gorm.DB
+	timeouts QueryTimeouts
+	logger   log.Logger
+}
+
+func NewIdempotencyRepository(db *gorm.DB, timeouts QueryTimeouts, logger log.Logger) *idempotencyRepository {
+	return &idempotencyRepository{
+		db:       db,
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+// conn returns the connection pool, bound to ctx but never to the transaction
+// it carries.
+func (r *idempotencyRepository) conn(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+// log returns the logger of the request ctx belongs to.
+func (r *idempotencyRepository) log(ctx context.Context) log.Logger {
+	return logging.FromContext(ctx, r.logger)
+}
+
+// Reserve claims record.Key for a request in progress until record.ExpiresAt,
+// once the expired keys are gone: stored responses past their TTL and
+// reservations left in progress past their lease alike. When the key is
+// already claimed, it returns false along with the stored record.
+func (r *idempotencyRepository) Reserve(ctx context.Context, record entity.IdempotencyKey) (entity.IdempotencyKey, bool, error) {
+	ctx, cancel := bound(ctx, r.timeouts.Write)
+	defer cancel()
+
+	result := r.conn(ctx).Where("expires_at < ?", time.Now()).Delete(&entity.IdempotencyKey{})
+	if result.Error != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.idempotencyRepository.Reserve(): failed to delete expired keys", "key", record.Key, "err", result.Error)
+		return entity.IdempotencyKey{}, false, result.Error
+	}
+
+	result = r.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	if result.Error != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.idempotencyRepository.Reserve(): failed to insert to database", "key", record.Key, "err", result.Error)
+		return entity.IdempotencyKey{}, false, result.Error
+	}
+
+	if result.RowsAffected == 1 {
+		return record, true, nil
+	}
+
+	var stored entity.IdempotencyKey
+	result = r.conn(ctx).First(&stored, "`key` = ?", record.Key)
+	if result.Error != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.idempotencyRepository.Reserve(): failed to fetch from database", "key", record.Key, "err", result.Error)
+		return entity.IdempotencyKey{}, false, result.Error
+	}
+
+	return stored, false, nil
+}
+
+// Save stores the response to the request which reserved record.Key, and
+// keeps it until record.ExpiresAt in place of the reservation lease.
+func (r *idempotencyRepository) Save(ctx context.Context, record entity.IdempotencyKey) error {
+	// The response is stored even when the client is gone, for its retry.
+	ctx, cancel := bound(context.WithoutCancel(ctx), r.timeouts.Write)
+	defer cancel()
+
+	result := r.conn(ctx).Model(&entity.IdempotencyKey{Key: record.Key}).Updates(entity.IdempotencyKey{
+		Status:    record.Status,
+		Header:    record.Header,
+		Body:      record.Body,
+		ExpiresAt: record.ExpiresAt,
+	})
+	if result.Error != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.idempotencyRepository.Save(): failed to update database", "key", record.Key, "err", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+// Release frees a key whose request failed, so that it may be retried.
+func (r *idempotencyRepository) Release(ctx context.Context, key string) error {
+	// The key is freed even when the client is gone, so that it may retry.
+	ctx, cancel := bound(context.WithoutCancel(ctx), r.timeouts.Write)
+	defer cancel()
+
+	result := r.conn(ctx).Where("`key` = ? AND status = 0", key).Delete(&entity.IdempotencyKey{})
+	if result.Error != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.idempotencyRepository.Release(): failed to delete from database", "key", key, "err", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
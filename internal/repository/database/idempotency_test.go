@@ -0,0 +1,262 @@
+package database
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+)
+
+func TestNewIdempotencyRepository(t *testing.T) {
+	mockDB, _ := setupMockDB()
+	timeouts := QueryTimeouts{Read: time.Second, Write: 2 * time.Second}
+	logger := setupMockLogger()
+
+	expected := &idempotencyRepository{
+		db:       mockDB,
+		timeouts: timeouts,
+		logger:   logger,
+	}
+
+	got := NewIdempotencyRepository(mockDB, timeouts, logger)
+	assert.NotNil(t, got)
+	assert.Equal(t, expected, got)
+}
+
+func TestIdempotencyRepository_Reserve(t *testing.T) {
+	deleteQuery := "DELETE FROM `idempotency_keys` WHERE expires_at < ?"
+	insertQuery := "INSERT INTO `idempotency_keys` (`key`,`request_hash`,`status`,`header`,`body`,`created_at`,`expires_at`) VALUES (?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `key`=`key`"
+	selectQuery := "SELECT * FROM `idempotency_keys` WHERE `key` = ? ORDER BY `idempotency_keys`.`key` LIMIT 1"
+
+	expiresAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	record := entity.IdempotencyKey{Key: "abc", RequestHash: "123", ExpiresAt: expiresAt}
+
+	tests := []struct {
+		name         string
+		mocks        func(mock sqlmock.Sqlmock)
+		want         entity.IdempotencyKey
+		wantReserved bool
+		wantErr      error
+	}{
+		{
+			name: "Got error deleting expired keys, should return non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given a free key, should claim it",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(insertQuery)).
+					WithArgs("abc", "123", 0, nil, []byte(nil), sqlmock.AnyArg(), expiresAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want:         record,
+			wantReserved: true,
+		},
+		{
+			name: "Given a key left in progress past its lease, should take it over",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(insertQuery)).
+					WithArgs("abc", "123", 0, nil, []byte(nil), sqlmock.AnyArg(), expiresAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want:         record,
+			wantReserved: true,
+		},
+		{
+			name: "Given a claimed key, should return the stored record",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(insertQuery)).
+					WithArgs("abc", "123", 0, nil, []byte(nil), sqlmock.AnyArg(), expiresAt).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				mock.ExpectQuery(regexp.QuoteMeta(selectQuery)).
+					WithArgs("abc").
+					WillReturnRows(sqlmock.NewRows([]string{"key", "request_hash", "status", "header", "body"}).
+						AddRow("abc", "123", 201, `{"Location":["/spaceship/1"]}`, []byte(`{"id":1}`)))
+			},
+			want: entity.IdempotencyKey{
+				Key:         "abc",
+				RequestHash: "123",
+				Status:      201,
+				Header:      http.Header{"Location": {"/spaceship/1"}},
+				Body:        []byte(`{"id":1}`),
+			},
+			wantReserved: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &idempotencyRepository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			got, reserved, err := r.Reserve(context.Background(), record)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.want.Key, got.Key)
+			assert.Equal(t, tt.want.Status, got.Status)
+			assert.Equal(t, tt.want.Header, got.Header)
+			assert.Equal(t, tt.want.Body, got.Body)
+			assert.Equal(t, tt.wantReserved, reserved)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestIdempotencyRepository_Save(t *testing.T) {
+	query := "UPDATE `idempotency_keys` SET `status`=?,`header`=?,`body`=?,`expires_at`=? WHERE `key` = ?"
+
+	expiresAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		mocks   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(201, `{"Location":["/spaceship/1"]}`, []byte(`{"id":1}`), expiresAt, "abc").
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given a claimed key, should store the response",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(201, `{"Location":["/spaceship/1"]}`, []byte(`{"id":1}`), expiresAt, "abc").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &idempotencyRepository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			err := r.Save(context.Background(), entity.IdempotencyKey{
+				Key:       "abc",
+				Status:    201,
+				Header:    http.Header{"Location": {"/spaceship/1"}},
+				Body:      []byte(`{"id":1}`),
+				ExpiresAt: expiresAt,
+			})
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestIdempotencyRepository_Release(t *testing.T) {
+	query := "DELETE FROM `idempotency_keys` WHERE `key` = ? AND status = 0"
+
+	tests := []struct {
+		name    string
+		mocks   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs("abc").
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given a claimed key, should free it",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs("abc").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &idempotencyRepository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			err := r.Release(context.Background(), "abc")
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
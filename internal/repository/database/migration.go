@@ -0,0 +1,111 @@
+package database
+
+import (
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"gorm.io/gorm"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+)
+
+// legacyArmament is an armament as stored before the weapon catalog, when the
+// weapon was a free text title.
+type legacyArmament struct {
+	ID    uint
+	Title string
+}
+
+// MigrateArmamentWeapons moves the armaments still carrying a free text title
+// over to the weapon catalog: titles naming the same weapon share a catalog
+// entry, found or created. It is meant to run within a transaction, and is a
+// no-op once the title column is gone, see DropArmamentTitles().
+func MigrateArmamentWeapons(db *gorm.DB, logger log.Logger) error {
+	if !db.Migrator().HasColumn(&entity.Armament{}, "title") {
+		return nil
+	}
+
+	var armaments []legacyArmament
+
+	result := db.Table("armaments").Select("id", "title").Where("weapon_id IS NULL OR weapon_id = 0").Find(&armaments)
+	if result.Error != nil {
+		level.Error(logger).Log("msg", "database.MigrateArmamentWeapons(): failed to fetch legacy armaments", "err", result.Error)
+		return result.Error
+	}
+
+	for _, group := range groupByWeapon(armaments) {
+		weapon := entity.Weapon{
+			Name:           group.name,
+			NormalizedName: entity.NormalizeWeaponName(group.name),
+		}
+
+		result := db.Unscoped().Where("normalized_name = ?", weapon.NormalizedName).FirstOrCreate(&weapon)
+		if result.Error != nil {
+			level.Error(logger).Log("msg", "database.MigrateArmamentWeapons(): failed to link armaments to weapons", "weapon", weapon.Name, "err", result.Error)
+			return result.Error
+		}
+
+		result = db.Table("armaments").Where("id IN ?", group.armamentIDs).Update("weapon_id", weapon.ID)
+		if result.Error != nil {
+			level.Error(logger).Log("msg", "database.MigrateArmamentWeapons(): failed to link armaments to weapons", "weapon", weapon.Name, "err", result.Error)
+			return result.Error
+		}
+	}
+
+	return nil
+}
+
+// DropArmamentTitles drops the free text title of the armaments once
+// MigrateArmamentWeapons() moved them over to the weapon catalog. MySQL commits
+// DDL statements implicitly, so it runs as a migration of its own rather than
+// along with the backfill. It is a no-op once the column is gone.
+func DropArmamentTitles(db *gorm.DB, logger log.Logger) error {
+	if !db.Migrator().HasColumn(&entity.Armament{}, "title") {
+		return nil
+	}
+
+	if err := db.Migrator().DropColumn(&entity.Armament{}, "title"); err != nil {
+		level.Error(logger).Log("msg", "database.DropArmamentTitles(): failed to drop the title column", "err", err)
+		return err
+	}
+
+	return nil
+}
+
+type weaponGroup struct {
+	name        string
+	armamentIDs []uint
+}
+
+// groupByWeapon gathers the legacy armaments by normalized title, in order of
+// first appearance. Each group is named after its most frequent spelling, the
+// earliest one winning ties. Blank titles are named "Unknown".
+func groupByWeapon(armaments []legacyArmament) []weaponGroup {
+	var groups []weaponGroup
+	var spellings []map[string]int
+	indexes := map[string]int{}
+
+	for _, armament := range armaments {
+		title := armament.Title
+		if entity.NormalizeWeaponName(title) == "" {
+			title = "Unknown"
+		}
+
+		key := entity.NormalizeWeaponName(title)
+		i, ok := indexes[key]
+		if !ok {
+			i = len(groups)
+			indexes[key] = i
+			groups = append(groups, weaponGroup{name: title})
+			spellings = append(spellings, map[string]int{})
+		}
+
+		groups[i].armamentIDs = append(groups[i].armamentIDs, armament.ID)
+		spellings[i][title]++
+
+		if spellings[i][title] > spellings[i][groups[i].name] {
+			groups[i].name = title
+		}
+	}
+
+	return groups
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupByWeapon(t *testing.T) {
+	tests := []struct {
+		name      string
+		armaments []legacyArmament
+		want      []weaponGroup
+	}{
+		{
+			name:      "Given no armament, should return no group",
+			armaments: nil,
+			want:      nil,
+		},
+		{
+			name: "Given spellings of the same weapon, should name the group after the most frequent one",
+			armaments: []legacyArmament{
+				{ID: 1, Title: "turbo laser"},
+				{ID: 2, Title: "Turbo Laser"},
+				{ID: 3, Title: "Ion Cannon"},
+				{ID: 4, Title: "Turbo-Laser"},
+				{ID: 5, Title: "Turbo Laser"},
+			},
+			want: []weaponGroup{
+				{name: "Turbo Laser", armamentIDs: []uint{1, 2, 4, 5}},
+				{name: "Ion Cannon", armamentIDs: []uint{3}},
+			},
+		},
+		{
+			name: "Given tied spellings, should keep the earliest one",
+			armaments: []legacyArmament{
+				{ID: 1, Title: "ion cannon"},
+				{ID: 2, Title: "Ion Cannon"},
+			},
+			want: []weaponGroup{
+				{name: "ion cannon", armamentIDs: []uint{1, 2}},
+			},
+		},
+		{
+			name: "Given blank titles, should gather them as unknown",
+			armaments: []legacyArmament{
+				{ID: 1, Title: ""},
+				{ID: 2, Title: " - "},
+			},
+			want: []weaponGroup{
+				{name: "Unknown", armamentIDs: []uint{1, 2}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := groupByWeapon(tt.armaments)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -7,6 +7,7 @@ package mock_spaceship
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	entity "github.com/wndisra/galactic-svc/internal/entity"
 	gomock "go.uber.org/mock/gomock"
@@ -35,47 +36,61 @@ func (m *MockSpaceShipRepository) EXPECT() *MockSpaceShipRepositoryMockRecorder
 	return m.recorder
 }
 
+// BumpVersion mocks base method.
+func (m *MockSpaceShipRepository) BumpVersion(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BumpVersion", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BumpVersion indicates an expected call of BumpVersion.
+func (mr *MockSpaceShipRepositoryMockRecorder) BumpVersion(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BumpVersion", reflect.TypeOf((*MockSpaceShipRepository)(nil).BumpVersion), ctx, id)
+}
+
 // Delete mocks base method.
-func (m *MockSpaceShipRepository) Delete(ctx context.Context, id int64) error {
+func (m *MockSpaceShipRepository) Delete(ctx context.Context, id int64, version uint) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockSpaceShipRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+func (mr *MockSpaceShipRepositoryMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSpaceShipRepository)(nil).Delete), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSpaceShipRepository)(nil).Delete), ctx, id, version)
 }
 
-// DeleteArmaments mocks base method.
-func (m *MockSpaceShipRepository) DeleteArmaments(ctx context.Context, spaceshipID int64) error {
+// Export mocks base method.
+func (m *MockSpaceShipRepository) Export(ctx context.Context, fn func(entity.SpaceShip) error) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteArmaments", ctx, spaceshipID)
+	ret := m.ctrl.Call(m, "Export", ctx, fn)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteArmaments indicates an expected call of DeleteArmaments.
-func (mr *MockSpaceShipRepositoryMockRecorder) DeleteArmaments(ctx, spaceshipID interface{}) *gomock.Call {
+// Export indicates an expected call of Export.
+func (mr *MockSpaceShipRepositoryMockRecorder) Export(ctx, fn interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteArmaments", reflect.TypeOf((*MockSpaceShipRepository)(nil).DeleteArmaments), ctx, spaceshipID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockSpaceShipRepository)(nil).Export), ctx, fn)
 }
 
 // GetAll mocks base method.
-func (m *MockSpaceShipRepository) GetAll(ctx context.Context, req entity.SpaceShip) ([]entity.SpaceShip, error) {
+func (m *MockSpaceShipRepository) GetAll(ctx context.Context, filter entity.SpaceShipFilter, opts entity.ListOptions) (entity.SpaceShipPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", ctx, req)
-	ret0, _ := ret[0].([]entity.SpaceShip)
+	ret := m.ctrl.Call(m, "GetAll", ctx, filter, opts)
+	ret0, _ := ret[0].(entity.SpaceShipPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockSpaceShipRepositoryMockRecorder) GetAll(ctx, req interface{}) *gomock.Call {
+func (mr *MockSpaceShipRepositoryMockRecorder) GetAll(ctx, filter, opts interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockSpaceShipRepository)(nil).GetAll), ctx, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockSpaceShipRepository)(nil).GetAll), ctx, filter, opts)
 }
 
 // GetByID mocks base method.
@@ -93,12 +108,28 @@ func (mr *MockSpaceShipRepositoryMockRecorder) GetByID(ctx, id interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockSpaceShipRepository)(nil).GetByID), ctx, id)
 }
 
+// GetByIDWithDeleted mocks base method.
+func (m *MockSpaceShipRepository) GetByIDWithDeleted(ctx context.Context, id int64) (entity.SpaceShip, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDWithDeleted", ctx, id)
+	ret0, _ := ret[0].(entity.SpaceShip)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDWithDeleted indicates an expected call of GetByIDWithDeleted.
+func (mr *MockSpaceShipRepositoryMockRecorder) GetByIDWithDeleted(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDWithDeleted", reflect.TypeOf((*MockSpaceShipRepository)(nil).GetByIDWithDeleted), ctx, id)
+}
+
 // Insert mocks base method.
-func (m *MockSpaceShipRepository) Insert(ctx context.Context, req entity.SpaceShip) error {
+func (m *MockSpaceShipRepository) Insert(ctx context.Context, req entity.SpaceShip) (entity.SpaceShip, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Insert", ctx, req)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(entity.SpaceShip)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Insert indicates an expected call of Insert.
@@ -107,6 +138,49 @@ func (mr *MockSpaceShipRepositoryMockRecorder) Insert(ctx, req interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockSpaceShipRepository)(nil).Insert), ctx, req)
 }
 
+// InsertBatch mocks base method.
+func (m *MockSpaceShipRepository) InsertBatch(ctx context.Context, req []entity.SpaceShip) ([]entity.SpaceShip, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBatch", ctx, req)
+	ret0, _ := ret[0].([]entity.SpaceShip)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertBatch indicates an expected call of InsertBatch.
+func (mr *MockSpaceShipRepositoryMockRecorder) InsertBatch(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBatch", reflect.TypeOf((*MockSpaceShipRepository)(nil).InsertBatch), ctx, req)
+}
+
+// Purge mocks base method.
+func (m *MockSpaceShipRepository) Purge(ctx context.Context, id int64, version uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockSpaceShipRepositoryMockRecorder) Purge(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockSpaceShipRepository)(nil).Purge), ctx, id, version)
+}
+
+// Restore mocks base method.
+func (m *MockSpaceShipRepository) Restore(ctx context.Context, id int64, deletedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id, deletedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockSpaceShipRepositoryMockRecorder) Restore(ctx, id, deletedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockSpaceShipRepository)(nil).Restore), ctx, id, deletedAt)
+}
+
 // Update mocks base method.
 func (m *MockSpaceShipRepository) Update(ctx context.Context, id int64, req entity.SpaceShip) error {
 	m.ctrl.T.Helper()
@@ -120,3 +194,151 @@ func (mr *MockSpaceShipRepositoryMockRecorder) Update(ctx, id, req interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockSpaceShipRepository)(nil).Update), ctx, id, req)
 }
+
+// WithTx mocks base method.
+func (m *MockSpaceShipRepository) WithTx(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockSpaceShipRepositoryMockRecorder) WithTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockSpaceShipRepository)(nil).WithTx), ctx, fn)
+}
+
+// MockArmamentRepository is a mock of ArmamentRepository interface.
+type MockArmamentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockArmamentRepositoryMockRecorder
+}
+
+// MockArmamentRepositoryMockRecorder is the mock recorder for MockArmamentRepository.
+type MockArmamentRepositoryMockRecorder struct {
+	mock *MockArmamentRepository
+}
+
+// NewMockArmamentRepository creates a new mock instance.
+func NewMockArmamentRepository(ctrl *gomock.Controller) *MockArmamentRepository {
+	mock := &MockArmamentRepository{ctrl: ctrl}
+	mock.recorder = &MockArmamentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArmamentRepository) EXPECT() *MockArmamentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockArmamentRepository) Delete(ctx context.Context, spaceshipID, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, spaceshipID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockArmamentRepositoryMockRecorder) Delete(ctx, spaceshipID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockArmamentRepository)(nil).Delete), ctx, spaceshipID, id)
+}
+
+// GetAll mocks base method.
+func (m *MockArmamentRepository) GetAll(ctx context.Context, spaceshipID int64) ([]entity.Armament, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, spaceshipID)
+	ret0, _ := ret[0].([]entity.Armament)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockArmamentRepositoryMockRecorder) GetAll(ctx, spaceshipID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockArmamentRepository)(nil).GetAll), ctx, spaceshipID)
+}
+
+// GetByID mocks base method.
+func (m *MockArmamentRepository) GetByID(ctx context.Context, spaceshipID, id int64) (entity.Armament, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, spaceshipID, id)
+	ret0, _ := ret[0].(entity.Armament)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockArmamentRepositoryMockRecorder) GetByID(ctx, spaceshipID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockArmamentRepository)(nil).GetByID), ctx, spaceshipID, id)
+}
+
+// Insert mocks base method.
+func (m *MockArmamentRepository) Insert(ctx context.Context, req entity.Armament) (entity.Armament, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, req)
+	ret0, _ := ret[0].(entity.Armament)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockArmamentRepositoryMockRecorder) Insert(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockArmamentRepository)(nil).Insert), ctx, req)
+}
+
+// Update mocks base method.
+func (m *MockArmamentRepository) Update(ctx context.Context, req entity.Armament) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockArmamentRepositoryMockRecorder) Update(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockArmamentRepository)(nil).Update), ctx, req)
+}
+
+// MockWeaponRepository is a mock of WeaponRepository interface.
+type MockWeaponRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWeaponRepositoryMockRecorder
+}
+
+// MockWeaponRepositoryMockRecorder is the mock recorder for MockWeaponRepository.
+type MockWeaponRepositoryMockRecorder struct {
+	mock *MockWeaponRepository
+}
+
+// NewMockWeaponRepository creates a new mock instance.
+func NewMockWeaponRepository(ctrl *gomock.Controller) *MockWeaponRepository {
+	mock := &MockWeaponRepository{ctrl: ctrl}
+	mock.recorder = &MockWeaponRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWeaponRepository) EXPECT() *MockWeaponRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetByIDs mocks base method.
+func (m *MockWeaponRepository) GetByIDs(ctx context.Context, ids []int64) ([]entity.Weapon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDs", ctx, ids)
+	ret0, _ := ret[0].([]entity.Weapon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDs indicates an expected call of GetByIDs.
+func (mr *MockWeaponRepositoryMockRecorder) GetByIDs(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDs", reflect.TypeOf((*MockWeaponRepository)(nil).GetByIDs), ctx, ids)
+}
@@ -0,0 +1,167 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package mock_weapon is a generated GoMock package.
+package mock_weapon
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/wndisra/galactic-svc/internal/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWeaponRepository is a mock of WeaponRepository interface.
+type MockWeaponRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWeaponRepositoryMockRecorder
+}
+
+// MockWeaponRepositoryMockRecorder is the mock recorder for MockWeaponRepository.
+type MockWeaponRepositoryMockRecorder struct {
+	mock *MockWeaponRepository
+}
+
+// NewMockWeaponRepository creates a new mock instance.
+func NewMockWeaponRepository(ctrl *gomock.Controller) *MockWeaponRepository {
+	mock := &MockWeaponRepository{ctrl: ctrl}
+	mock.recorder = &MockWeaponRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWeaponRepository) EXPECT() *MockWeaponRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountArmaments mocks base method.
+func (m *MockWeaponRepository) CountArmaments(ctx context.Context, id int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountArmaments", ctx, id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountArmaments indicates an expected call of CountArmaments.
+func (mr *MockWeaponRepositoryMockRecorder) CountArmaments(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountArmaments", reflect.TypeOf((*MockWeaponRepository)(nil).CountArmaments), ctx, id)
+}
+
+// Delete mocks base method.
+func (m *MockWeaponRepository) Delete(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockWeaponRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWeaponRepository)(nil).Delete), ctx, id)
+}
+
+// GetAll mocks base method.
+func (m *MockWeaponRepository) GetAll(ctx context.Context) ([]entity.Weapon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]entity.Weapon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockWeaponRepositoryMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockWeaponRepository)(nil).GetAll), ctx)
+}
+
+// GetByID mocks base method.
+func (m *MockWeaponRepository) GetByID(ctx context.Context, id int64) (entity.Weapon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(entity.Weapon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockWeaponRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockWeaponRepository)(nil).GetByID), ctx, id)
+}
+
+// GetByName mocks base method.
+func (m *MockWeaponRepository) GetByName(ctx context.Context, name string) (entity.Weapon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByName", ctx, name)
+	ret0, _ := ret[0].(entity.Weapon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByName indicates an expected call of GetByName.
+func (mr *MockWeaponRepositoryMockRecorder) GetByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockWeaponRepository)(nil).GetByName), ctx, name)
+}
+
+// Insert mocks base method.
+func (m *MockWeaponRepository) Insert(ctx context.Context, req entity.Weapon) (entity.Weapon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, req)
+	ret0, _ := ret[0].(entity.Weapon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockWeaponRepositoryMockRecorder) Insert(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockWeaponRepository)(nil).Insert), ctx, req)
+}
+
+// Restore mocks base method.
+func (m *MockWeaponRepository) Restore(ctx context.Context, req entity.Weapon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockWeaponRepositoryMockRecorder) Restore(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockWeaponRepository)(nil).Restore), ctx, req)
+}
+
+// Update mocks base method.
+func (m *MockWeaponRepository) Update(ctx context.Context, req entity.Weapon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockWeaponRepositoryMockRecorder) Update(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockWeaponRepository)(nil).Update), ctx, req)
+}
+
+// WithTx mocks base method.
+func (m *MockWeaponRepository) WithTx(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockWeaponRepositoryMockRecorder) WithTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockWeaponRepository)(nil).WithTx), ctx, fn)
+}
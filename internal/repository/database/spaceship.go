@@ -2,30 +2,87 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"errors"
-	"fmt"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"gorm.io/gorm"
 
 	"github.com/wndisra/galactic-svc/internal/entity"
+	"github.com/wndisra/galactic-svc/internal/helpers"
+	"github.com/wndisra/galactic-svc/internal/logging"
 )
 
 type repository struct {
-	db     *gorm.DB
-	logger log.Logger
+	db       *gorm.DB
+	timeouts QueryTimeouts
+	logger   log.Logger
 }
 
-func NewRepository(db *gorm.DB, logger log.Logger) *repository {
+func NewRepository(db *gorm.DB, timeouts QueryTimeouts, logger log.Logger) *repository {
 	return &repository{
-		db:     db,
-		logger: logger,
+		db:       db,
+		timeouts: timeouts,
+		logger:   logger,
 	}
 }
 
-func (r *repository) Insert(ctx context.Context, req entity.SpaceShip) error {
-	result := r.db.Create(&entity.SpaceShip{
+// updatableColumns are written on every update, so that zero values are not
+// skipped the way gorm.DB.Updates() does by default.
+var updatableColumns = []string{"name", "class", "crew", "image", "value", "status", "version"}
+
+// insertBatchSize caps the rows of a single INSERT statement in InsertBatch().
+const insertBatchSize = 100
+
+var errStaleVersion = helpers.NewConflictError("spaceship has been modified concurrently, fetch it again")
+
+type txKey struct{}
+
+// WithTx runs fn inside a database transaction. Repository calls made with the
+// context handed to fn take part in it, and everything is rolled back when fn
+// returns an error.
+func (r *repository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, r.db, fn)
+}
+
+func withTx(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	return connFromContext(ctx, db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction carried by ctx, or the connection pool when
+// there is none.
+func (r *repository) conn(ctx context.Context) *gorm.DB {
+	return connFromContext(ctx, r.db)
+}
+
+// log returns the logger of the request ctx belongs to.
+func (r *repository) log(ctx context.Context) log.Logger {
+	return logging.FromContext(ctx, r.logger)
+}
+
+// connFromContext returns the transaction carried by ctx, or db when there is
+// none, bound to ctx so that queries are traced and cancelled along with it.
+// Every repository of the package shares the transactions started by
+// WithTx().
+func connFromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+
+	return db.WithContext(ctx)
+}
+
+// Insert adds a spaceship with its armaments, and returns it as persisted,
+// with its new ID.
+func (r *repository) Insert(ctx context.Context, req entity.SpaceShip) (entity.SpaceShip, error) {
+	ctx, cancel := bound(ctx, r.timeouts.Write)
+	defer cancel()
+
+	spaceship := entity.SpaceShip{
 		Name:      req.Name,
 		Class:     req.Class,
 		Crew:      req.Crew,
@@ -33,88 +90,378 @@ func (r *repository) Insert(ctx context.Context, req entity.SpaceShip) error {
 		Value:     req.Value,
 		Status:    req.Status,
 		Armaments: req.Armaments,
-	})
+	}
 
+	result := r.conn(ctx).Create(&spaceship)
 	if result.Error != nil {
-		level.Error(r.logger).Log("msg", "database.Insert(): failed to insert to database")
-		return result.Error
+		level.Error(r.log(ctx)).Log("msg", "database.Insert(): failed to insert to database", "err", result.Error)
+		return entity.SpaceShip{}, result.Error
 	}
 
-	return nil
+	return spaceship, nil
+}
+
+// InsertBatch adds spaceships with their armaments, insertBatchSize rows per
+// statement, and returns them with their new IDs in the same order.
+func (r *repository) InsertBatch(ctx context.Context, req []entity.SpaceShip) ([]entity.SpaceShip, error) {
+	ctx, cancel := bound(ctx, r.timeouts.Batch)
+	defer cancel()
+
+	spaceships := make([]entity.SpaceShip, len(req))
+	for i, spaceship := range req {
+		spaceships[i] = entity.SpaceShip{
+			Name:      spaceship.Name,
+			Class:     spaceship.Class,
+			Crew:      spaceship.Crew,
+			Image:     spaceship.Image,
+			Value:     spaceship.Value,
+			Status:    spaceship.Status,
+			Armaments: spaceship.Armaments,
+		}
+	}
+
+	result := r.conn(ctx).CreateInBatches(&spaceships, insertBatchSize)
+	if result.Error != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.InsertBatch(): failed to insert to database", "count", len(spaceships), "err", result.Error)
+		return nil, result.Error
+	}
+
+	return spaceships, nil
 }
 
 func (r *repository) GetByID(ctx context.Context, id int64) (entity.SpaceShip, error) {
+	ctx, cancel := bound(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var spaceship entity.SpaceShip
+
+	result := r.conn(ctx).Preload("Armaments.Weapon").First(&spaceship, "id = ?", id)
+
+	err := result.Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		level.Error(r.log(ctx)).Log("msg", "database.GetByID(): failed to fetch from database", "spaceship_id", id, "err", err)
+		return entity.SpaceShip{}, err
+	}
+
+	return spaceship, nil
+}
+
+// GetByIDWithDeleted is GetByID without armaments, which also finds a soft
+// deleted spaceship.
+func (r *repository) GetByIDWithDeleted(ctx context.Context, id int64) (entity.SpaceShip, error) {
+	ctx, cancel := bound(ctx, r.timeouts.Read)
+	defer cancel()
+
 	var spaceship entity.SpaceShip
 
-	result := r.db.Preload("Armaments").First(&spaceship, "id = ?", id)
+	result := r.conn(ctx).Unscoped().First(&spaceship, "id = ?", id)
 
 	err := result.Error
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		level.Error(r.logger).Log("msg", "database.GetByID(): failed to fetch from database")
+		level.Error(r.log(ctx)).Log("msg", "database.GetByIDWithDeleted(): failed to fetch from database", "spaceship_id", id, "err", err)
 		return entity.SpaceShip{}, err
 	}
 
 	return spaceship, nil
 }
 
+// Update overwrites every column of the spaceship with req, zero values
+// included, and inserts the armaments of req. Existing armaments are kept, see
+// DeleteArmaments() to drop them first.
+//
+// req.Version must be the version the changes are based on: the update is a
+// compare-and-swap that bumps it, and fails with a conflict when the stored
+// version moved on in the meantime.
 func (r *repository) Update(ctx context.Context, id int64, req entity.SpaceShip) error {
-	var entity entity.SpaceShip
+	ctx, cancel := bound(ctx, r.timeouts.Write)
+	defer cancel()
+
+	model := entity.SpaceShip{Model: gorm.Model{ID: uint(id)}}
 
-	r.db.First(&entity, "id = ?", id)
-	entity.Armaments = req.Armaments // ensure armaments data also updated
+	expectedVersion := req.Version
+	req.Version = expectedVersion + 1
 
-	result := r.db.Model(&entity).Updates(req)
+	result := r.conn(ctx).Model(&model).Where("version = ?", expectedVersion).Select(updatableColumns).Updates(req)
 	err := result.Error
 	if err != nil {
-		level.Error(r.logger).Log("msg", "database.Update(): failed to update data in database")
+		level.Error(r.log(ctx)).Log("msg", "database.Update(): failed to update data in database", "spaceship_id", id, "err", err)
+		return err
+	}
+
+	if result.RowsAffected == 0 {
+		return errStaleVersion
+	}
+
+	if len(req.Armaments) == 0 {
+		return nil
+	}
+
+	armaments := make([]entity.Armament, len(req.Armaments))
+	for i, armament := range req.Armaments {
+		armaments[i] = entity.Armament{
+			SpaceShipID: uint(id),
+			WeaponID:    armament.WeaponID,
+			Qty:         armament.Qty,
+		}
+	}
+
+	result = r.conn(ctx).Create(&armaments)
+	err = result.Error
+	if err != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.Update(): failed to insert armaments to database", "spaceship_id", id, "err", err)
 		return err
 	}
 
 	return nil
 }
 
-func (r *repository) Delete(ctx context.Context, id int64) error {
-	var model entity.SpaceShip
+// Delete soft deletes the spaceship along with its armaments. Both are given
+// the same deletion time, which tells them apart from armaments deleted
+// earlier on when restoring the spaceship.
+//
+// A non-zero version makes the deletion a compare-and-swap, failing with a
+// conflict when the stored version moved on in the meantime.
+func (r *repository) Delete(ctx context.Context, id int64, version uint) error {
+	ctx, cancel := bound(ctx, r.timeouts.Write)
+	defer cancel()
+
+	deletedAt := r.db.NowFunc()
 
-	result := r.db.Delete(&model, "id = ?", id)
+	result := whereVersion(r.conn(ctx).Model(&entity.SpaceShip{}).Where("id = ?", id), version).UpdateColumn("deleted_at", deletedAt)
 	err := result.Error
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		level.Error(r.logger).Log("msg", "database.Delete(): failed to update data in database")
+		level.Error(r.log(ctx)).Log("msg", "database.Delete(): failed to update data in database", "spaceship_id", id, "err", err)
+		return err
+	}
+
+	if version != 0 && result.RowsAffected == 0 {
+		return errStaleVersion
+	}
+
+	result = r.conn(ctx).Model(&entity.Armament{}).Where("space_ship_id = ?", id).UpdateColumn("deleted_at", deletedAt)
+	err = result.Error
+	if err != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.Delete(): failed to delete armaments data in database", "spaceship_id", id, "err", err)
 		return err
 	}
 
 	return nil
 }
 
-func (r *repository) GetAll(ctx context.Context, req entity.SpaceShip) ([]entity.SpaceShip, error) {
-	var spaceships []entity.SpaceShip
+// whereVersion narrows db down to the given version of the spaceship, unless
+// it is zero, which stands for any version.
+func whereVersion(db *gorm.DB, version uint) *gorm.DB {
+	if version == 0 {
+		return db
+	}
 
-	whereQuery := "name LIKE " + "'%" + req.Name + "%'"
+	return db.Where("version = ?", version)
+}
+
+// Restore undoes Delete, bringing back the spaceship and the armaments deleted
+// along with it at deletedAt.
+func (r *repository) Restore(ctx context.Context, id int64, deletedAt time.Time) error {
+	ctx, cancel := bound(ctx, r.timeouts.Write)
+	defer cancel()
 
-	if req.Class != "" {
-		whereQuery += fmt.Sprintf(" AND class = '%s'", req.Class)
+	result := r.conn(ctx).Unscoped().Model(&entity.SpaceShip{}).Where("id = ?", id).UpdateColumn("deleted_at", nil)
+	err := result.Error
+	if err != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.Restore(): failed to update data in database", "spaceship_id", id, "err", err)
+		return err
 	}
 
-	if req.Status != "" {
-		whereQuery += fmt.Sprintf(" AND status = '%s'", req.Status)
+	result = r.conn(ctx).Unscoped().Model(&entity.Armament{}).Where("space_ship_id = ? AND deleted_at = ?", id, deletedAt).UpdateColumn("deleted_at", nil)
+	err = result.Error
+	if err != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.Restore(): failed to restore armaments data in database", "spaceship_id", id, "err", err)
+		return err
 	}
 
-	result := r.db.Where(whereQuery).Find(&spaceships)
+	return nil
+}
+
+// Purge permanently removes the spaceship and every armament it ever had,
+// whether they are soft deleted or not.
+//
+// A non-zero version makes the removal a compare-and-swap, failing with a
+// conflict when the stored version moved on in the meantime. The armaments are
+// removed first, so Purge is meant to run within WithTx to have them back then.
+func (r *repository) Purge(ctx context.Context, id int64, version uint) error {
+	ctx, cancel := bound(ctx, r.timeouts.Write)
+	defer cancel()
+
+	result := r.conn(ctx).Unscoped().Delete(&entity.Armament{}, "space_ship_id = ?", id)
 	err := result.Error
+	if err != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.Purge(): failed to delete armaments data in database", "spaceship_id", id, "err", err)
+		return err
+	}
+
+	result = whereVersion(r.conn(ctx).Unscoped().Where("id = ?", id), version).Delete(&entity.SpaceShip{})
+	err = result.Error
+	if err != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.Purge(): failed to delete data in database", "spaceship_id", id, "err", err)
+		return err
+	}
+
+	if version != 0 && result.RowsAffected == 0 {
+		return errStaleVersion
+	}
+
+	return nil
+}
+
+// Export hands every spaceship, armaments included, over to fn one at a time,
+// ordered by ID. Rows are read from a cursor, so the fleet is never held in
+// memory as a whole. An error returned by fn stops the export.
+func (r *repository) Export(ctx context.Context, fn func(spaceship entity.SpaceShip) error) error {
+	ctx, cancel := bound(ctx, r.timeouts.Export)
+	defer cancel()
+
+	rows, err := r.conn(ctx).Model(&entity.SpaceShip{}).
+		Select("space_ships.id", "space_ships.name", "space_ships.class", "space_ships.crew", "space_ships.image",
+			"space_ships.value", "space_ships.status", "armaments.id", "armaments.weapon_id", "armaments.qty").
+		Joins("LEFT JOIN armaments ON armaments.space_ship_id = space_ships.id AND armaments.deleted_at IS NULL").
+		Order("space_ships.id").Order("armaments.id").
+		Rows()
+	if err != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.Export(): failed to fetch from database", "err", err)
+		return err
+	}
+	defer rows.Close()
+
+	// A spaceship spans as many rows as it has armaments, at least one.
+	var current entity.SpaceShip
+	for rows.Next() {
+		var spaceship entity.SpaceShip
+		var armamentID, weaponID, qty sql.NullInt64
+
+		err := rows.Scan(&spaceship.ID, &spaceship.Name, &spaceship.Class, &spaceship.Crew, &spaceship.Image,
+			&spaceship.Value, &spaceship.Status, &armamentID, &weaponID, &qty)
+		if err != nil {
+			level.Error(r.log(ctx)).Log("msg", "database.Export(): failed to scan row", "err", err)
+			return err
+		}
+
+		if spaceship.ID != current.ID {
+			if current.ID != 0 {
+				if err := fn(current); err != nil {
+					return err
+				}
+			}
+			current = spaceship
+		}
+
+		if armamentID.Valid {
+			armament := entity.Armament{SpaceShipID: current.ID, WeaponID: uint(weaponID.Int64), Qty: int(qty.Int64)}
+			armament.ID = uint(armamentID.Int64)
+			current.Armaments = append(current.Armaments, armament)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.Export(): failed to read rows", "err", err)
+		return err
+	}
+
+	if current.ID != 0 {
+		return fn(current)
+	}
+
+	return nil
+}
+
+func (r *repository) GetAll(ctx context.Context, filter entity.SpaceShipFilter, opts entity.ListOptions) (entity.SpaceShipPage, error) {
+	ctx, cancel := bound(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var page entity.SpaceShipPage
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = entity.DefaultListLimit
+	}
+
+	order, err := newOrdering(opts.Sort)
+	if err != nil {
+		return entity.SpaceShipPage{}, err
+	}
+
+	query := r.conn(ctx).Scopes(spaceShipScopes(filter)...).Clauses(order.orderBy()).Limit(limit + 1)
+
+	if opts.Cursor != "" {
+		after, err := order.after(opts.Cursor)
+		if err != nil {
+			return entity.SpaceShipPage{}, err
+		}
+		query = query.Where(after)
+	} else if opts.Offset > 0 {
+		query = query.Offset(opts.Offset)
+	}
+
+	var spaceships []entity.SpaceShip
+
+	result := query.Find(&spaceships)
+	err = result.Error
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return []entity.SpaceShip{}, err
+		level.Error(r.log(ctx)).Log("msg", "database.GetAll(): failed to fetch from database", "err", err)
+		return entity.SpaceShipPage{}, err
 	}
 
-	return spaceships, nil
+	// One extra row is fetched to know whether another page follows.
+	if len(spaceships) > limit {
+		spaceships = spaceships[:limit]
+		page.HasMore = true
+
+		page.NextCursor, err = encodeCursor(order, spaceships[limit-1])
+		if err != nil {
+			level.Error(r.log(ctx)).Log("msg", "database.GetAll(): failed to encode cursor", "err", err)
+			return entity.SpaceShipPage{}, err
+		}
+	}
+	page.SpaceShips = spaceships
+
+	if opts.WithTotal {
+		var total int64
+
+		result := r.conn(ctx).Model(&entity.SpaceShip{}).Scopes(spaceShipScopes(filter)...).Count(&total)
+		if result.Error != nil {
+			level.Error(r.log(ctx)).Log("msg", "database.GetAll(): failed to count from database", "err", result.Error)
+			return entity.SpaceShipPage{}, result.Error
+		}
+		page.Total = &total
+	}
+
+	return page, nil
 }
 
 func (r *repository) DeleteArmaments(ctx context.Context, spaceshipID int64) error {
+	ctx, cancel := bound(ctx, r.timeouts.Write)
+	defer cancel()
+
 	var model entity.Armament
 
-	result := r.db.Delete(&model, "space_ship_id = ?", spaceshipID)
+	result := r.conn(ctx).Delete(&model, "space_ship_id = ?", spaceshipID)
 	err := result.Error
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		level.Error(r.logger).Log("msg", "database.DeleteArmaments(): failed to delete armaments data in database")
+		level.Error(r.log(ctx)).Log("msg", "database.DeleteArmaments(): failed to delete armaments data in database", "spaceship_id", spaceshipID, "err", err)
+		return err
+	}
+
+	return nil
+}
+
+// BumpVersion moves the spaceship on to its next version without touching
+// anything else, for changes made to it through its armaments.
+func (r *repository) BumpVersion(ctx context.Context, id int64) error {
+	ctx, cancel := bound(ctx, r.timeouts.Write)
+	defer cancel()
+
+	result := r.conn(ctx).Model(&entity.SpaceShip{}).Where("id = ?", id).UpdateColumn("version", gorm.Expr("version + 1"))
+	err := result.Error
+	if err != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.BumpVersion(): failed to update data in database", "spaceship_id", id, "err", err)
 		return err
 	}
 
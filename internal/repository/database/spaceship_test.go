@@ -5,6 +5,7 @@ import (
 	"os"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	kitlog "github.com/go-kit/log"
@@ -14,6 +15,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/wndisra/galactic-svc/internal/entity"
+	"github.com/wndisra/galactic-svc/internal/helpers"
 )
 
 func setupMockDB() (*gorm.DB, sqlmock.Sqlmock) {
@@ -46,24 +48,112 @@ func TestNewRepository(t *testing.T) {
 	logger := setupMockLogger()
 
 	mockDB, _ := setupMockDB()
+	timeouts := QueryTimeouts{Read: time.Second, Write: 2 * time.Second}
 	expected := &repository{
-		db:     mockDB,
-		logger: logger,
+		db:       mockDB,
+		timeouts: timeouts,
+		logger:   logger,
 	}
 
-	got := NewRepository(mockDB, logger)
+	got := NewRepository(mockDB, timeouts, logger)
 	assert.NotNil(t, got)
 	assert.Equal(t, expected, got)
 }
 
+func TestRepository_WithTx(t *testing.T) {
+	deleteArmamentsQuery := "UPDATE `armaments` SET `deleted_at`=? WHERE space_ship_id = ? AND `armaments`.`deleted_at` IS NULL"
+	deleteQuery := "UPDATE `space_ships` SET `deleted_at`=? WHERE id = ? AND `space_ships`.`deleted_at` IS NULL"
+
+	tests := []struct {
+		name    string
+		id      int64
+		mocks   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "Got error in the first step, should rollback and return non-nil error",
+			id:   1,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(deleteArmamentsQuery)).
+					WithArgs(sqlmock.AnyArg(), 1).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Got error in the last step, should rollback the previous steps and return non-nil error",
+			id:   2,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(deleteArmamentsQuery)).
+					WithArgs(sqlmock.AnyArg(), 2).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).
+					WithArgs(sqlmock.AnyArg(), 2).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Got no error in every step, should commit and return nil error",
+			id:   3,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(deleteArmamentsQuery)).
+					WithArgs(sqlmock.AnyArg(), 3).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).
+					WithArgs(sqlmock.AnyArg(), 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(deleteArmamentsQuery)).
+					WithArgs(sqlmock.AnyArg(), 3).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &repository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			err := r.WithTx(context.Background(), func(ctx context.Context) error {
+				if err := r.DeleteArmaments(ctx, tt.id); err != nil {
+					return err
+				}
+
+				return r.Delete(ctx, tt.id, 0)
+			})
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
 func TestRepository_Insert(t *testing.T) {
-	query := "INSERT INTO `space_ships` (`created_at`,`updated_at`,`deleted_at`,`name`,`class`,`crew`,`image`,`value`,`status`) VALUES (?,?,?,?,?,?,?,?,?)"
-	armamentQuery := "INSERT INTO `armaments` (`created_at`,`updated_at`,`deleted_at`,`title`,`qty`,`space_ship_id`) VALUES (?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `space_ship_id`=VALUES(`space_ship_id`)"
+	query := "INSERT INTO `space_ships` (`created_at`,`updated_at`,`deleted_at`,`name`,`class`,`crew`,`image`,`value`,`status`,`version`) VALUES (?,?,?,?,?,?,?,?,?,?)"
+	armamentQuery := "INSERT INTO `armaments` (`created_at`,`updated_at`,`deleted_at`,`space_ship_id`,`weapon_id`,`qty`) VALUES (?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `space_ship_id`=VALUES(`space_ship_id`)"
 
 	tests := []struct {
 		name    string
 		param   entity.SpaceShip
 		mocks   func(mock sqlmock.Sqlmock)
+		wantID  uint
 		wantErr error
 	}{
 		{
@@ -77,22 +167,23 @@ func TestRepository_Insert(t *testing.T) {
 				Status: "Operational",
 				Armaments: []entity.Armament{
 					{
-						Title: "Turbo Laser",
-						Qty:   60,
+						WeaponID: 1,
+						Qty:      60,
 					},
 				},
 			},
 			mocks: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
 				mock.ExpectExec(regexp.QuoteMeta(query)).
-					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "Devastator", "Star Destroyer", 1200, "https://test", 100.99, "Operational").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "Devastator", "Star Destroyer", 1200, "https://test", 100.99, "Operational", 1).
 					WillReturnError(assert.AnError)
 				mock.ExpectRollback()
 			},
+			wantID:  0,
 			wantErr: assert.AnError,
 		},
 		{
-			name: "Given valid param with no Gorm error, should return nil error",
+			name: "Given valid param with no Gorm error, should return the spaceship with its ID and nil error",
 			param: entity.SpaceShip{
 				Name:   "Devastator 2",
 				Class:  "Star Destroyer 2",
@@ -102,21 +193,114 @@ func TestRepository_Insert(t *testing.T) {
 				Status: "Operational",
 				Armaments: []entity.Armament{
 					{
-						Title: "Turbo Laser",
-						Qty:   10,
+						WeaponID: 1,
+						Qty:      10,
 					},
 				},
 			},
 			mocks: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
 				mock.ExpectExec(regexp.QuoteMeta(query)).
-					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "Devastator 2", "Star Destroyer 2", 2200, "https://test", 100.99, "Operational").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "Devastator 2", "Star Destroyer 2", 2200, "https://test", 100.99, "Operational", 1).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(regexp.QuoteMeta(armamentQuery)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 1, 10).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			wantID:  1,
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &repository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			got, err := r.Insert(context.Background(), tt.param)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.wantID, got.ID)
+			if tt.wantID != 0 {
+				assert.Equal(t, tt.param.Name, got.Name)
+				assert.Equal(t, uint(1), got.Version)
+				assert.Equal(t, tt.wantID, got.Armaments[0].SpaceShipID)
+			}
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestRepository_InsertBatch(t *testing.T) {
+	query := "INSERT INTO `space_ships` (`created_at`,`updated_at`,`deleted_at`,`name`,`class`,`crew`,`image`,`value`,`status`,`version`) VALUES (?,?,?,?,?,?,?,?,?,?),(?,?,?,?,?,?,?,?,?,?)"
+	armamentQuery := "INSERT INTO `armaments` (`created_at`,`updated_at`,`deleted_at`,`space_ship_id`,`weapon_id`,`qty`) VALUES (?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `space_ship_id`=VALUES(`space_ship_id`)"
+
+	param := []entity.SpaceShip{
+		{
+			Name:   "Devastator",
+			Class:  "Star Destroyer",
+			Crew:   1200,
+			Value:  100.99,
+			Status: "Operational",
+			Armaments: []entity.Armament{
+				{
+					WeaponID: 1,
+					Qty:      60,
+				},
+			},
+		},
+		{
+			Name:   "Executor",
+			Class:  "Dreadnought",
+			Crew:   280000,
+			Value:  500,
+			Status: "Operational",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		mocks   func(mock sqlmock.Sqlmock)
+		wantIDs []uint
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return nil slice with non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantIDs: nil,
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given valid param with no Gorm error, should insert every spaceship in one statement and return their new IDs",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "Devastator", "Star Destroyer", 1200, "", 100.99, "Operational", 1,
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "Executor", "Dreadnought", 280000, "", 500.0, "Operational", 1,
+					).
+					WillReturnResult(sqlmock.NewResult(7, 2))
 				mock.ExpectExec(regexp.QuoteMeta(armamentQuery)).
-					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "Turbo Laser", 10, sqlmock.AnyArg()).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 7, 1, 60).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectCommit()
 			},
+			wantIDs: []uint{7, 8},
 			wantErr: nil,
 		},
 	}
@@ -135,9 +319,15 @@ func TestRepository_Insert(t *testing.T) {
 
 			tt.mocks(mock)
 
-			err := r.Insert(context.Background(), tt.param)
+			got, err := r.InsertBatch(context.Background(), param)
+
+			var gotIDs []uint
+			for _, spaceship := range got {
+				gotIDs = append(gotIDs, spaceship.ID)
+			}
 
 			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.wantIDs, gotIDs)
 			assert.Equal(t, tt.wantErr, err)
 		})
 	}
@@ -146,6 +336,7 @@ func TestRepository_Insert(t *testing.T) {
 func TestRepository_GetByID(t *testing.T) {
 	query := "SELECT * FROM `space_ships` WHERE id = ? AND `space_ships`.`deleted_at` IS NULL ORDER BY `space_ships`.`id` LIMIT 1"
 	armamentQuery := "SELECT * FROM `armaments` WHERE `armaments`.`space_ship_id` = ? AND `armaments`.`deleted_at` IS NULL"
+	weaponQuery := "SELECT * FROM `weapons` WHERE `weapons`.`id` = ? AND `weapons`.`deleted_at` IS NULL"
 
 	tests := []struct {
 		name    string
@@ -186,8 +377,12 @@ func TestRepository_GetByID(t *testing.T) {
 						AddRow(3, "Devastator", "Star Destroyer", 15000, "https://test", 200.99, "Operational"))
 				mock.ExpectQuery(regexp.QuoteMeta(armamentQuery)).
 					WithArgs(3).
-					WillReturnRows(sqlmock.NewRows([]string{"title", "qty", "space_ship_id"}).
-						AddRow("Turbo Laser", 10, 3))
+					WillReturnRows(sqlmock.NewRows([]string{"weapon_id", "qty", "space_ship_id"}).
+						AddRow(1, 10, 3))
+				mock.ExpectQuery(regexp.QuoteMeta(weaponQuery)).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+						AddRow(1, "Turbo Laser"))
 			},
 			want: entity.SpaceShip{
 				Name:   "Devastator",
@@ -198,7 +393,8 @@ func TestRepository_GetByID(t *testing.T) {
 				Status: "Operational",
 				Armaments: []entity.Armament{
 					{
-						Title:       "Turbo Laser",
+						WeaponID:    1,
+						Weapon:      entity.Weapon{Model: gorm.Model{ID: 1}, Name: "Turbo Laser"},
 						Qty:         10,
 						SpaceShipID: 3,
 					},
@@ -238,8 +434,8 @@ func TestRepository_GetByID(t *testing.T) {
 }
 
 func TestRepository_Update(t *testing.T) {
-	selectQuery := "SELECT * FROM `space_ships` WHERE id = ? AND `space_ships`.`deleted_at` IS NULL ORDER BY `space_ships`.`id` LIMIT 1"
-	updateQuery := "UPDATE `space_ships` SET `updated_at`=?,`name`=?,`class`=?,`crew`=?,`image`=?,`value`=?,`status`=? WHERE `space_ships`.`deleted_at` IS NULL AND `id` = ?"
+	updateQuery := "UPDATE `space_ships` SET `updated_at`=?,`name`=?,`class`=?,`crew`=?,`image`=?,`value`=?,`status`=?,`version`=? WHERE version = ? AND `space_ships`.`deleted_at` IS NULL AND `id` = ?"
+	armamentQuery := "INSERT INTO `armaments` (`created_at`,`updated_at`,`deleted_at`,`space_ship_id`,`weapon_id`,`qty`) VALUES (?,?,?,?,?,?)"
 
 	tests := []struct {
 		name    string
@@ -259,42 +455,118 @@ func TestRepository_Update(t *testing.T) {
 				Value:     200.99,
 				Status:    "Operational",
 				Armaments: []entity.Armament{},
+				Version:   3,
 			},
 			mocks: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(regexp.QuoteMeta(selectQuery)).
-					WithArgs(1).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "class", "crew", "image", "value", "status"}).
-						AddRow(1, "Devastator", "Star Destroyer", 15000, "https://test", 200.99, "Operational"))
-
 				mock.ExpectBegin()
 				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).
-					WithArgs(sqlmock.AnyArg(), "Devastator", "Star Destroyer", 15000, "https://test", 200.99, "Operational", 1).
+					WithArgs(sqlmock.AnyArg(), "Devastator", "Star Destroyer", 15000, "https://test", 200.99, "Operational", 4, 3, 1).
 					WillReturnError(assert.AnError)
 				mock.ExpectRollback()
 			},
 			wantErr: assert.AnError,
 		},
 		{
-			name: "Given valid param with no error, should return nil error",
+			name: "Given zero values, should still write them",
 			id:   1,
 			param: entity.SpaceShip{
-				Name:      "Devastator",
-				Class:     "Star Destroyer",
-				Crew:      15000,
-				Image:     "https://test",
-				Value:     200.99,
-				Status:    "Operational",
-				Armaments: []entity.Armament{},
+				Name:    "Devastator",
+				Class:   "Star Destroyer",
+				Crew:    0,
+				Image:   "",
+				Value:   0,
+				Status:  "Decommissioned",
+				Version: 3,
+			},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).
+					WithArgs(sqlmock.AnyArg(), "Devastator", "Star Destroyer", 0, "", 0.0, "Decommissioned", 4, 3, 1).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Given outdated version, should return conflict error",
+			id:   1,
+			param: entity.SpaceShip{
+				Name:    "Devastator",
+				Class:   "Star Destroyer",
+				Crew:    15000,
+				Image:   "https://test",
+				Value:   200.99,
+				Status:  "Operational",
+				Version: 3,
+			},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).
+					WithArgs(sqlmock.AnyArg(), "Devastator", "Star Destroyer", 15000, "https://test", 200.99, "Operational", 4, 3, 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			wantErr: errStaleVersion,
+		},
+		{
+			name: "Got error inserting armaments, should return non-nil error",
+			id:   1,
+			param: entity.SpaceShip{
+				Name:   "Devastator",
+				Class:  "Star Destroyer",
+				Crew:   15000,
+				Image:  "https://test",
+				Value:  200.99,
+				Status: "Operational",
+				Armaments: []entity.Armament{
+					{
+						WeaponID: 1,
+						Qty:      10,
+					},
+				},
+				Version: 3,
+			},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).
+					WithArgs(sqlmock.AnyArg(), "Devastator", "Star Destroyer", 15000, "https://test", 200.99, "Operational", 4, 3, 1).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(armamentQuery)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 1, 1, 10).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given valid param with armaments, should return nil error",
+			id:   1,
+			param: entity.SpaceShip{
+				Name:   "Devastator",
+				Class:  "Star Destroyer",
+				Crew:   15000,
+				Image:  "https://test",
+				Value:  200.99,
+				Status: "Operational",
+				Armaments: []entity.Armament{
+					{
+						WeaponID: 1,
+						Qty:      10,
+					},
+				},
+				Version: 3,
 			},
 			mocks: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(regexp.QuoteMeta(selectQuery)).
-					WithArgs(1).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "class", "crew", "image", "value", "status"}).
-						AddRow(1, "Devastator", "Star Destroyer", 15000, "https://test", 200.99, "Operational"))
-
 				mock.ExpectBegin()
 				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).
-					WithArgs(sqlmock.AnyArg(), "Devastator", "Star Destroyer", 15000, "https://test", 200.99, "Operational", 1).
+					WithArgs(sqlmock.AnyArg(), "Devastator", "Star Destroyer", 15000, "https://test", 200.99, "Operational", 4, 3, 1).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(armamentQuery)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 1, 1, 10).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectCommit()
 			},
@@ -326,10 +598,13 @@ func TestRepository_Update(t *testing.T) {
 
 func TestRepository_Delete(t *testing.T) {
 	query := "UPDATE `space_ships` SET `deleted_at`=? WHERE id = ? AND `space_ships`.`deleted_at` IS NULL"
+	versionQuery := "UPDATE `space_ships` SET `deleted_at`=? WHERE id = ? AND version = ? AND `space_ships`.`deleted_at` IS NULL"
+	armamentQuery := "UPDATE `armaments` SET `deleted_at`=? WHERE space_ship_id = ? AND `armaments`.`deleted_at` IS NULL"
 
 	tests := []struct {
 		name    string
 		id      int64
+		version uint
 		mocks   func(mock sqlmock.Sqlmock)
 		wantErr error
 	}{
@@ -354,11 +629,16 @@ func TestRepository_Delete(t *testing.T) {
 					WithArgs(sqlmock.AnyArg(), 2).
 					WillReturnError(gorm.ErrRecordNotFound)
 				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(armamentQuery)).
+					WithArgs(sqlmock.AnyArg(), 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
 			},
 			wantErr: nil,
 		},
 		{
-			name: "Given valid param with no error, should return nil error",
+			name: "Got error deleting armaments, should return non-nil error",
 			id:   3,
 			mocks: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
@@ -366,6 +646,61 @@ func TestRepository_Delete(t *testing.T) {
 					WithArgs(sqlmock.AnyArg(), 3).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(armamentQuery)).
+					WithArgs(sqlmock.AnyArg(), 3).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given valid param with no error, should delete the spaceship and its armaments at once",
+			id:   3,
+			mocks: func(mock sqlmock.Sqlmock) {
+				deletedAt := sqlmock.AnyArg()
+
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(deletedAt, 3).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(armamentQuery)).
+					WithArgs(deletedAt, 3).
+					WillReturnResult(sqlmock.NewResult(1, 2))
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+		{
+			name:    "Given outdated version, should return stale version error",
+			id:      3,
+			version: 4,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(versionQuery)).
+					WithArgs(sqlmock.AnyArg(), 3, 4).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			wantErr: errStaleVersion,
+		},
+		{
+			name:    "Given current version, should delete the spaceship and its armaments",
+			id:      3,
+			version: 5,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(versionQuery)).
+					WithArgs(sqlmock.AnyArg(), 3, 5).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(armamentQuery)).
+					WithArgs(sqlmock.AnyArg(), 3).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
 			},
 			wantErr: nil,
 		},
@@ -385,7 +720,7 @@ func TestRepository_Delete(t *testing.T) {
 
 			tt.mocks(mock)
 
-			err := r.Delete(context.Background(), tt.id)
+			err := r.Delete(context.Background(), tt.id, tt.version)
 
 			assert.NoError(t, mock.ExpectationsWereMet())
 			assert.Equal(t, tt.wantErr, err)
@@ -393,72 +728,546 @@ func TestRepository_Delete(t *testing.T) {
 	}
 }
 
-func TestRepository_GetAll(t *testing.T) {
-	query := "SELECT * FROM `space_ships` WHERE name LIKE '%%' AND `space_ships`.`deleted_at` IS NULL"
-	queryWithFilter := "SELECT * FROM `space_ships` WHERE (name LIKE '%Devas%' AND class = 'Star Destroyer' AND status = 'Operational') AND `space_ships`.`deleted_at` IS NULL"
+func TestRepository_GetByIDWithDeleted(t *testing.T) {
+	query := "SELECT * FROM `space_ships` WHERE id = ? ORDER BY `space_ships`.`id` LIMIT 1"
+	deletedAt := time.Date(2023, 11, 2, 10, 0, 0, 0, time.UTC)
 
 	tests := []struct {
 		name    string
-		req     entity.SpaceShip
+		id      int64
 		mocks   func(mock sqlmock.Sqlmock)
-		want    []entity.SpaceShip
+		want    entity.SpaceShip
 		wantErr error
 	}{
 		{
-			name: "Got error in Gorm query, should return empty slice of struct with non-nil error",
-			req:  entity.SpaceShip{},
+			name: "Got error in Gorm query, should return empty struct with non-nil error",
+			id:   1,
 			mocks: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(regexp.QuoteMeta(query)).
-					WithArgs().
+					WithArgs(1).
 					WillReturnError(assert.AnError)
 			},
-			want:    []entity.SpaceShip{},
+			want:    entity.SpaceShip{},
 			wantErr: assert.AnError,
 		},
 		{
-			name: "Got error record not found in Gorm query, should return empty slice of struct with nil error",
-			req:  entity.SpaceShip{},
+			name: "Given non-existed ID, should return empty struct with nil error",
+			id:   2,
 			mocks: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(regexp.QuoteMeta(query)).
-					WithArgs().
+					WithArgs(2).
 					WillReturnError(gorm.ErrRecordNotFound)
 			},
-			want:    []entity.SpaceShip(nil),
+			want:    entity.SpaceShip{},
 			wantErr: nil,
 		},
 		{
-			name: "Pass filter with no error in Gorm query, should return non-empty slice of struct with nil error",
-			req: entity.SpaceShip{
-				Name:   "Devas",
-				Class:  "Star Destroyer",
-				Status: "Operational",
+			name: "Given soft deleted ID, should return it with nil error",
+			id:   3,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(3).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "deleted_at"}).
+						AddRow(3, "Devastator", deletedAt))
+			},
+			want: entity.SpaceShip{
+				Model: gorm.Model{ID: 3, DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}},
+				Name:  "Devastator",
 			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &repository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			got, err := r.GetByIDWithDeleted(context.Background(), tt.id)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestRepository_Restore(t *testing.T) {
+	query := "UPDATE `space_ships` SET `deleted_at`=? WHERE id = ?"
+	armamentQuery := "UPDATE `armaments` SET `deleted_at`=? WHERE space_ship_id = ? AND deleted_at = ?"
+	deletedAt := time.Date(2023, 11, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		id      int64
+		mocks   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return non-nil error",
+			id:   1,
 			mocks: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(regexp.QuoteMeta(queryWithFilter)).
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(nil, 1).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Got error restoring armaments, should return non-nil error",
+			id:   2,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(nil, 2).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(armamentQuery)).
+					WithArgs(nil, 2, deletedAt).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given valid param with no error, should restore the armaments deleted along",
+			id:   3,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(nil, 3).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(armamentQuery)).
+					WithArgs(nil, 3, deletedAt).
+					WillReturnResult(sqlmock.NewResult(1, 2))
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &repository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			err := r.Restore(context.Background(), tt.id, deletedAt)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestRepository_Purge(t *testing.T) {
+	query := "DELETE FROM `space_ships` WHERE id = ?"
+	versionQuery := "DELETE FROM `space_ships` WHERE id = ? AND version = ?"
+	armamentQuery := "DELETE FROM `armaments` WHERE space_ship_id = ?"
+
+	tests := []struct {
+		name    string
+		id      int64
+		version uint
+		mocks   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "Got error deleting armaments, should return non-nil error",
+			id:   1,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(armamentQuery)).
+					WithArgs(1).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Got error in Gorm query, should return non-nil error",
+			id:   2,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(armamentQuery)).
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(1, 2))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(2).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given valid param with no error, should return nil error",
+			id:   3,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(armamentQuery)).
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(1, 2))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+		{
+			name:    "Given outdated version, should return stale version error",
+			id:      3,
+			version: 4,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(armamentQuery)).
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(versionQuery)).
+					WithArgs(3, 4).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			wantErr: errStaleVersion,
+		},
+		{
+			name:    "Given current version, should return nil error",
+			id:      3,
+			version: 5,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(armamentQuery)).
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(versionQuery)).
+					WithArgs(3, 5).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &repository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			err := r.Purge(context.Background(), tt.id, tt.version)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestRepository_GetAll(t *testing.T) {
+	minCrew, maxCrew := int64(100), int64(20000)
+	minValue, maxValue := 10.5, 500.0
+	createdAt := time.Date(2023, 11, 1, 10, 0, 0, 0, time.UTC)
+
+	cursor, _ := encodeCursor(defaultOrdering, entity.SpaceShip{Model: gorm.Model{ID: 7, CreatedAt: createdAt}})
+
+	query := "SELECT * FROM `space_ships` WHERE `space_ships`.`deleted_at` IS NULL ORDER BY `created_at`,`id` LIMIT 21"
+	queryWithFilter := "SELECT * FROM `space_ships` WHERE `name` LIKE ? AND `class` = ? AND `status` = ? AND `space_ships`.`deleted_at` IS NULL ORDER BY `created_at`,`id` LIMIT 21"
+	queryWithListAndRange := "SELECT * FROM `space_ships` WHERE `class` IN (?,?) AND `crew` >= ? AND `crew` <= ? AND `value` >= ? AND `value` <= ? AND `space_ships`.`deleted_at` IS NULL ORDER BY `created_at`,`id` LIMIT 21"
+	queryWithWildcard := "SELECT * FROM `space_ships` WHERE `name` LIKE ? AND `space_ships`.`deleted_at` IS NULL ORDER BY `created_at`,`id` LIMIT 21"
+	queryWithOffset := "SELECT * FROM `space_ships` WHERE `space_ships`.`deleted_at` IS NULL ORDER BY `created_at`,`id` LIMIT 3 OFFSET 4"
+	queryWithCursor := "SELECT * FROM `space_ships` WHERE (`created_at` > ? OR (`created_at` = ? AND `id` > ?)) AND `space_ships`.`deleted_at` IS NULL ORDER BY `created_at`,`id` LIMIT 3"
+	countQuery := "SELECT count(*) FROM `space_ships` WHERE `status` = ? AND `space_ships`.`deleted_at` IS NULL"
+	queryWithStatus := "SELECT * FROM `space_ships` WHERE `status` = ? AND `space_ships`.`deleted_at` IS NULL ORDER BY `created_at`,`id` LIMIT 21"
+	queryWithDeleted := "SELECT * FROM `space_ships` WHERE `status` = ? ORDER BY `created_at`,`id` LIMIT 21"
+	countQueryWithDeleted := "SELECT count(*) FROM `space_ships` WHERE `status` = ?"
+	deletedAt := time.Date(2023, 11, 2, 10, 0, 0, 0, time.UTC)
+
+	sortedQuery := "SELECT * FROM `space_ships` WHERE `space_ships`.`deleted_at` IS NULL ORDER BY `value` DESC,`name`,`id` LIMIT 2"
+	sortedQueryWithCursor := "SELECT * FROM `space_ships` WHERE (`value` < ? OR (`value` = ? AND `name` > ?) OR (`value` = ? AND `name` = ? AND `id` > ?)) AND `space_ships`.`deleted_at` IS NULL ORDER BY `value` DESC,`name`,`id` LIMIT 2"
+	sort := []entity.SortField{{Field: "value", Desc: true}, {Field: "name"}}
+	sortedOrdering, _ := newOrdering(sort)
+	sortedCursor, _ := encodeCursor(sortedOrdering, entity.SpaceShip{Model: gorm.Model{ID: 3}, Name: "Devastator", Value: 200.99})
+
+	total := int64(2)
+
+	tests := []struct {
+		name    string
+		req     entity.SpaceShipFilter
+		opts    entity.ListOptions
+		mocks   func(mock sqlmock.Sqlmock)
+		want    entity.SpaceShipPage
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return empty page with non-nil error",
+			req:  entity.SpaceShipFilter{},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
 					WithArgs().
+					WillReturnError(assert.AnError)
+			},
+			want:    entity.SpaceShipPage{},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Got error record not found in Gorm query, should return empty page with nil error",
+			req:  entity.SpaceShipFilter{},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs().
+					WillReturnError(gorm.ErrRecordNotFound)
+			},
+			want:    entity.SpaceShipPage{},
+			wantErr: nil,
+		},
+		{
+			name: "Pass include deleted, should also return and count soft deleted spaceships",
+			req:  entity.SpaceShipFilter{Statuses: []string{"Destroyed"}, IncludeDeleted: true},
+			opts: entity.ListOptions{WithTotal: true},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(queryWithDeleted)).
+					WithArgs("Destroyed").
+					WillReturnRows(sqlmock.NewRows([]string{"name", "status", "deleted_at"}).
+						AddRow("Devastator", "Destroyed", deletedAt).
+						AddRow("Executor", "Destroyed", nil))
+				mock.ExpectQuery(regexp.QuoteMeta(countQueryWithDeleted)).
+					WithArgs("Destroyed").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+			},
+			want: entity.SpaceShipPage{
+				SpaceShips: []entity.SpaceShip{
+					{
+						Model:  gorm.Model{DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}},
+						Name:   "Devastator",
+						Status: "Destroyed",
+					},
+					{
+						Name:   "Executor",
+						Status: "Destroyed",
+					},
+				},
+				Total: &total,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Pass filter with no error in Gorm query, should return non-empty page with nil error",
+			req: entity.SpaceShipFilter{
+				Name:     "Devas",
+				Classes:  []string{"Star Destroyer"},
+				Statuses: []string{"Operational"},
+			},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(queryWithFilter)).
+					WithArgs("%Devas%", "Star Destroyer", "Operational").
 					WillReturnRows(sqlmock.NewRows([]string{"name", "class", "crew", "image", "value", "status"}).
 						AddRow("Devastator", "Star Destroyer", 15000, "https://test", 200.99, "Operational").
 						AddRow("Devastator 2", "Star Destroyer", 15000, "https://test", 200.99, "Operational"))
 			},
-			want: []entity.SpaceShip{
-				{
-					Name:      "Devastator",
-					Class:     "Star Destroyer",
-					Crew:      15000,
-					Image:     "https://test",
-					Value:     200.99,
-					Status:    "Operational",
-					Armaments: []entity.Armament(nil),
+			want: entity.SpaceShipPage{
+				SpaceShips: []entity.SpaceShip{
+					{
+						Name:      "Devastator",
+						Class:     "Star Destroyer",
+						Crew:      15000,
+						Image:     "https://test",
+						Value:     200.99,
+						Status:    "Operational",
+						Armaments: []entity.Armament(nil),
+					},
+					{
+						Name:      "Devastator 2",
+						Class:     "Star Destroyer",
+						Crew:      15000,
+						Image:     "https://test",
+						Value:     200.99,
+						Status:    "Operational",
+						Armaments: []entity.Armament(nil),
+					},
 				},
-				{
-					Name:      "Devastator 2",
-					Class:     "Star Destroyer",
-					Crew:      15000,
-					Image:     "https://test",
-					Value:     200.99,
-					Status:    "Operational",
-					Armaments: []entity.Armament(nil),
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Pass class list and ranges, should bind every value as a parameter",
+			req: entity.SpaceShipFilter{
+				Classes:  []string{"Star Destroyer", "Corvette' OR '1'='1"},
+				MinCrew:  &minCrew,
+				MaxCrew:  &maxCrew,
+				MinValue: &minValue,
+				MaxValue: &maxValue,
+			},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(queryWithListAndRange)).
+					WithArgs("Star Destroyer", "Corvette' OR '1'='1", int64(100), int64(20000), 10.5, 500.0).
+					WillReturnRows(sqlmock.NewRows([]string{"name"}))
+			},
+			want:    entity.SpaceShipPage{SpaceShips: []entity.SpaceShip{}},
+			wantErr: nil,
+		},
+		{
+			name: "Pass name with LIKE wildcards, should escape them",
+			req: entity.SpaceShipFilter{
+				Name: `100%_off\`,
+			},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(queryWithWildcard)).
+					WithArgs(`%100\%\_off\\%`).
+					WillReturnRows(sqlmock.NewRows([]string{"name"}))
+			},
+			want:    entity.SpaceShipPage{SpaceShips: []entity.SpaceShip{}},
+			wantErr: nil,
+		},
+		{
+			name: "Pass limit and offset with more rows left, should return next cursor",
+			opts: entity.ListOptions{Limit: 2, Offset: 4},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(queryWithOffset)).
+					WithArgs().
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "name"}).
+						AddRow(5, createdAt, "Devastator").
+						AddRow(7, createdAt, "Devastator 2").
+						AddRow(8, createdAt, "Devastator 3"))
+			},
+			want: entity.SpaceShipPage{
+				SpaceShips: []entity.SpaceShip{
+					{Model: gorm.Model{ID: 5, CreatedAt: createdAt}, Name: "Devastator"},
+					{Model: gorm.Model{ID: 7, CreatedAt: createdAt}, Name: "Devastator 2"},
+				},
+				NextCursor: cursor,
+				HasMore:    true,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Pass cursor, should resume after the cursor",
+			opts: entity.ListOptions{Limit: 2, Cursor: cursor},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(queryWithCursor)).
+					WithArgs(createdAt, createdAt, 7).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "name"}).
+						AddRow(8, createdAt, "Devastator 3"))
+			},
+			want: entity.SpaceShipPage{
+				SpaceShips: []entity.SpaceShip{
+					{Model: gorm.Model{ID: 8, CreatedAt: createdAt}, Name: "Devastator 3"},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name:    "Pass malformed cursor, should return non-nil error without querying",
+			opts:    entity.ListOptions{Cursor: "not-a-cursor"},
+			mocks:   func(mock sqlmock.Sqlmock) {},
+			want:    entity.SpaceShipPage{},
+			wantErr: errInvalidCursor,
+		},
+		{
+			name: "Pass sort fields, should order by them with id as tie-breaker",
+			opts: entity.ListOptions{Sort: sort, Limit: 1},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(sortedQuery)).
+					WithArgs().
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "value"}).
+						AddRow(3, "Devastator", 200.99).
+						AddRow(4, "Executor", 150.5))
+			},
+			want: entity.SpaceShipPage{
+				SpaceShips: []entity.SpaceShip{
+					{Model: gorm.Model{ID: 3}, Name: "Devastator", Value: 200.99},
 				},
+				NextCursor: sortedCursor,
+				HasMore:    true,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Pass sort fields with cursor, should resume after the cursor in that order",
+			opts: entity.ListOptions{Sort: sort, Limit: 1, Cursor: sortedCursor},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(sortedQueryWithCursor)).
+					WithArgs(200.99, 200.99, "Devastator", 200.99, "Devastator", 3).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "value"}).
+						AddRow(4, "Executor", 150.5))
+			},
+			want: entity.SpaceShipPage{
+				SpaceShips: []entity.SpaceShip{
+					{Model: gorm.Model{ID: 4}, Name: "Executor", Value: 150.5},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name:    "Pass cursor from another sort order, should return non-nil error without querying",
+			opts:    entity.ListOptions{Cursor: sortedCursor},
+			mocks:   func(mock sqlmock.Sqlmock) {},
+			want:    entity.SpaceShipPage{},
+			wantErr: errInvalidCursor,
+		},
+		{
+			name:    "Pass unknown sort field, should return non-nil error without querying",
+			opts:    entity.ListOptions{Sort: []entity.SortField{{Field: "deleted_at"}}},
+			mocks:   func(mock sqlmock.Sqlmock) {},
+			want:    entity.SpaceShipPage{},
+			wantErr: helpers.ErrInvalidQueryParam,
+		},
+		{
+			name: "Ask for total, should count the rows matching the filter",
+			req:  entity.SpaceShipFilter{Statuses: []string{"Operational"}},
+			opts: entity.ListOptions{WithTotal: true},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(queryWithStatus)).
+					WithArgs("Operational").
+					WillReturnRows(sqlmock.NewRows([]string{"name"}).
+						AddRow("Devastator").
+						AddRow("Devastator 2"))
+				mock.ExpectQuery(regexp.QuoteMeta(countQuery)).
+					WithArgs("Operational").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+			},
+			want: entity.SpaceShipPage{
+				SpaceShips: []entity.SpaceShip{
+					{Name: "Devastator"},
+					{Name: "Devastator 2"},
+				},
+				Total: &total,
 			},
 			wantErr: nil,
 		},
@@ -478,11 +1287,110 @@ func TestRepository_GetAll(t *testing.T) {
 
 			tt.mocks(mock)
 
-			got, err := r.GetAll(context.Background(), tt.req)
+			got, err := r.GetAll(context.Background(), tt.req, tt.opts)
 
 			assert.NoError(t, mock.ExpectationsWereMet())
 			assert.Equal(t, tt.want, got)
-			assert.Equal(t, tt.wantErr, err)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRepository_Export(t *testing.T) {
+	query := "SELECT space_ships.id,space_ships.name,space_ships.class,space_ships.crew,space_ships.image,space_ships.value,space_ships.status," +
+		"armaments.id,armaments.weapon_id,armaments.qty FROM `space_ships` " +
+		"LEFT JOIN armaments ON armaments.space_ship_id = space_ships.id AND armaments.deleted_at IS NULL " +
+		"WHERE `space_ships`.`deleted_at` IS NULL ORDER BY space_ships.id,armaments.id"
+	columns := []string{"id", "name", "class", "crew", "image", "value", "status", "id", "weapon_id", "qty"}
+
+	devastator := entity.SpaceShip{
+		Model: gorm.Model{ID: 3}, Name: "Devastator", Class: "Star Destroyer", Crew: 15000,
+		Image: "https://test", Value: 200.99, Status: "Operational",
+		Armaments: []entity.Armament{
+			{Model: gorm.Model{ID: 5}, SpaceShipID: 3, WeaponID: 1, Qty: 60},
+			{Model: gorm.Model{ID: 6}, SpaceShipID: 3, WeaponID: 2, Qty: 10},
+		},
+	}
+	executor := entity.SpaceShip{
+		Model: gorm.Model{ID: 4}, Name: "Executor", Class: "Star Dreadnought", Crew: 280000, Status: "Damaged",
+	}
+
+	tests := []struct {
+		name    string
+		fnErr   error
+		mocks   func(mock sqlmock.Sqlmock)
+		want    []entity.SpaceShip
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WillReturnError(assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given no spaceship, should not call fn",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+		},
+		{
+			name: "Given spaceships, should group their armaments and call fn once for each",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(3, "Devastator", "Star Destroyer", 15000, "https://test", 200.99, "Operational", 5, 1, 60).
+						AddRow(3, "Devastator", "Star Destroyer", 15000, "https://test", 200.99, "Operational", 6, 2, 10).
+						AddRow(4, "Executor", "Star Dreadnought", 280000, "", 0, "Damaged", nil, nil, nil))
+			},
+			want: []entity.SpaceShip{devastator, executor},
+		},
+		{
+			name:  "Got error from fn, should stop and return it",
+			fnErr: assert.AnError,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(3, "Devastator", "Star Destroyer", 15000, "https://test", 200.99, "Operational", 5, 1, 60).
+						AddRow(4, "Executor", "Star Dreadnought", 280000, "", 0, "Damaged", nil, nil, nil))
+			},
+			want: []entity.SpaceShip{
+				{
+					Model: gorm.Model{ID: 3}, Name: "Devastator", Class: "Star Destroyer", Crew: 15000,
+					Image: "https://test", Value: 200.99, Status: "Operational",
+					Armaments: []entity.Armament{{Model: gorm.Model{ID: 5}, SpaceShipID: 3, WeaponID: 1, Qty: 60}},
+				},
+			},
+			wantErr: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &repository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			var got []entity.SpaceShip
+			err := r.Export(context.Background(), func(spaceship entity.SpaceShip) error {
+				got = append(got, spaceship)
+				return tt.fnErr
+			})
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.want, got)
+			assert.ErrorIs(t, err, tt.wantErr)
 		})
 	}
 }
@@ -555,3 +1463,60 @@ func TestRepository_DeleteArmaments(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_BumpVersion(t *testing.T) {
+	query := "UPDATE `space_ships` SET `version`=version + 1 WHERE id = ? AND `space_ships`.`deleted_at` IS NULL"
+
+	tests := []struct {
+		name    string
+		id      int64
+		mocks   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return non-nil error",
+			id:   1,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(1).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given valid param with no error, should return nil error",
+			id:   2,
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &repository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			err := r.BumpVersion(context.Background(), tt.id)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
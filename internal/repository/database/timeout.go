@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/wndisra/galactic-svc/internal/config"
+)
+
+// QueryTimeouts bound each repository call by the kind of operation it runs,
+// on top of the deadline of its ctx. A zero timeout leaves the calls of its
+// kind bounded by ctx alone.
+type QueryTimeouts struct {
+	Read   time.Duration
+	Write  time.Duration
+	Batch  time.Duration // bulk inserts
+	Export time.Duration // streamed listings
+}
+
+// NewQueryTimeouts returns the query timeouts set by cfg.
+func NewQueryTimeouts(cfg config.DBConfig) QueryTimeouts {
+	return QueryTimeouts{
+		Read:   cfg.ReadQueryTimeout,
+		Write:  cfg.WriteQueryTimeout,
+		Batch:  cfg.BatchQueryTimeout,
+		Export: cfg.ExportQueryTimeout,
+	}
+}
+
+// bound returns ctx cancelled after timeout, unless it is zero. The returned
+// function must be called once the queries are done.
+func bound(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// contextErrors makes the errors of queries cut short by their context match
+// context.Canceled or context.DeadlineExceeded, whatever the driver reported
+// instead, so that they may be told from the failures of the database.
+type contextErrors struct{}
+
+func (p contextErrors) Name() string {
+	return "context_errors"
+}
+
+func (p contextErrors) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	return errors.Join(
+		cb.Create().After("*").Register("context_errors:after_create", p.after),
+		cb.Query().After("*").Register("context_errors:after_query", p.after),
+		cb.Update().After("*").Register("context_errors:after_update", p.after),
+		cb.Delete().After("*").Register("context_errors:after_delete", p.after),
+		cb.Row().After("*").Register("context_errors:after_row", p.after),
+		cb.Raw().After("*").Register("context_errors:after_raw", p.after),
+	)
+}
+
+func (p contextErrors) after(db *gorm.DB) {
+	if db.Error == nil || errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		return
+	}
+
+	ctxErr := db.Statement.Context.Err()
+	if ctxErr != nil && !errors.Is(db.Error, ctxErr) {
+		db.Error = fmt.Errorf("%w: %w", ctxErr, db.Error)
+	}
+}
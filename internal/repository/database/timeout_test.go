@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wndisra/galactic-svc/internal/config"
+	"github.com/wndisra/galactic-svc/internal/entity"
+)
+
+func TestNewQueryTimeouts(t *testing.T) {
+	cfg := config.Default().DB
+	cfg.ExportQueryTimeout = time.Minute
+
+	got := NewQueryTimeouts(cfg)
+
+	assert.Equal(t, QueryTimeouts{Read: 5 * time.Second, Write: 10 * time.Second, Batch: 30 * time.Second, Export: time.Minute}, got)
+}
+
+func TestRepository_Context(t *testing.T) {
+	query := "SELECT * FROM `space_ships` WHERE id = ? AND `space_ships`.`deleted_at` IS NULL ORDER BY `space_ships`.`id` LIMIT 1"
+
+	cancelled := func() (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return ctx, cancel
+	}
+	cancelledSoon := func() (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+		return ctx, cancel
+	}
+	background := func() (context.Context, context.CancelFunc) {
+		return context.Background(), func() {}
+	}
+
+	tests := []struct {
+		name     string
+		ctx      func() (context.Context, context.CancelFunc)
+		timeouts QueryTimeouts
+		call     func(ctx context.Context, r *repository) error
+		mocks    func(mock sqlmock.Sqlmock)
+		wantErr  error
+	}{
+		{
+			name: "Given a cancelled context, should not run the query and return context.Canceled",
+			ctx:  cancelled,
+			call: func(ctx context.Context, r *repository) error {
+				_, err := r.GetByID(ctx, 1)
+				return err
+			},
+			mocks:   func(mock sqlmock.Sqlmock) {},
+			wantErr: context.Canceled,
+		},
+		{
+			name: "Given a context cancelled during the query, should abort it and return context.Canceled",
+			ctx:  cancelledSoon,
+			call: func(ctx context.Context, r *repository) error {
+				_, err := r.GetByID(ctx, 1)
+				return err
+			},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(1).
+					WillDelayFor(time.Second).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			},
+			wantErr: context.Canceled,
+		},
+		{
+			name:     "Given a read outlasting the read timeout, should abort it and return context.DeadlineExceeded",
+			ctx:      background,
+			timeouts: QueryTimeouts{Read: 20 * time.Millisecond, Write: time.Minute},
+			call: func(ctx context.Context, r *repository) error {
+				_, err := r.GetByID(ctx, 1)
+				return err
+			},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(1).
+					WillDelayFor(time.Second).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:     "Given a write outlasting the write timeout, should abort it and return context.DeadlineExceeded",
+			ctx:      background,
+			timeouts: QueryTimeouts{Read: time.Minute, Write: 20 * time.Millisecond},
+			call: func(ctx context.Context, r *repository) error {
+				return r.Delete(ctx, 1, 0)
+			},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin().WillDelayFor(time.Second)
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:     "Given a read within the read timeout, should return nil error",
+			ctx:      background,
+			timeouts: QueryTimeouts{Read: time.Second},
+			call: func(ctx context.Context, r *repository) error {
+				_, err := r.GetAll(ctx, entity.SpaceShipFilter{}, entity.ListOptions{Limit: 1})
+				return err
+			},
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `space_ships`")).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			if err := mockDB.Use(contextErrors{}); err != nil {
+				t.Fatal(err)
+			}
+
+			r := &repository{
+				db:       mockDB,
+				timeouts: tt.timeouts,
+				logger:   setupMockLogger(),
+			}
+
+			tt.mocks(mock)
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			begin := time.Now()
+			err := tt.call(ctx, r)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Less(t, time.Since(begin), time.Second, "the query should have been aborted")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
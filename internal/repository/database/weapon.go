@@ -0,0 +1,238 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"gorm.io/gorm"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+	"github.com/wndisra/galactic-svc/internal/helpers"
+	"github.com/wndisra/galactic-svc/internal/logging"
+)
+
+// errWeaponNameTaken reports a write that lost the race for a name to another
+// one, past the check of the service.
+var errWeaponNameTaken = helpers.NewConflictError("a weapon with the same name already exists")
+
+type weaponRepository struct {
+	db       *gorm.DB
+	timeouts QueryTimeouts
+	logger   log.Logger
+}
+
+func NewWeaponRepository(db *gorm.DB, timeouts QueryTimeouts, logger log.Logger) *weaponRepository {
+	return &weaponRepository{
+		db:       db,
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+func (r *weaponRepository) conn(ctx context.Context) *gorm.DB {
+	return connFromContext(ctx, r.db)
+}
+
+// isDuplicateKey tells whether err violates a unique index, the one on the
+// normalized names of weapons.
+func (r *weaponRepository) isDuplicateKey(err error) bool {
+	if translator, ok := r.db.Dialector.(gorm.ErrorTranslator); ok {
+		err = translator.Translate(err)
+	}
+
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
+
+// log returns the logger of the request ctx belongs to.
+func (r *weaponRepository) log(ctx context.Context) log.Logger {
+	return logging.FromContext(ctx, r.logger)
+}
+
+// WithTx runs fn inside a database transaction, see repository.WithTx().
+func (r *weaponRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, r.db, fn)
+}
+
+// Insert adds a weapon to the catalog and returns it with its new ID.
+func (r *weaponRepository) Insert(ctx context.Context, req entity.Weapon) (entity.Weapon, error) {
+	ctx, cancel := bound(ctx, r.timeouts.Write)
+	defer cancel()
+
+	weapon := entity.Weapon{
+		Name:           req.Name,
+		NormalizedName: entity.NormalizeWeaponName(req.Name),
+		Damage:         req.Damage,
+		Range:          req.Range,
+		Cost:           req.Cost,
+	}
+
+	result := r.conn(ctx).Create(&weapon)
+	if r.isDuplicateKey(result.Error) {
+		return entity.Weapon{}, errWeaponNameTaken
+	}
+
+	if result.Error != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.weaponRepository.Insert(): failed to insert to database", "err", result.Error)
+		return entity.Weapon{}, result.Error
+	}
+
+	return weapon, nil
+}
+
+// GetByID returns a weapon, or an empty one when there is none.
+func (r *weaponRepository) GetByID(ctx context.Context, id int64) (entity.Weapon, error) {
+	ctx, cancel := bound(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var weapon entity.Weapon
+
+	result := r.conn(ctx).First(&weapon, "id = ?", id)
+
+	err := result.Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		level.Error(r.log(ctx)).Log("msg", "database.weaponRepository.GetByID(): failed to fetch from database", "weapon_id", id, "err", err)
+		return entity.Weapon{}, err
+	}
+
+	return weapon, nil
+}
+
+// GetByIDs returns the weapons found among ids, in no particular order.
+func (r *weaponRepository) GetByIDs(ctx context.Context, ids []int64) ([]entity.Weapon, error) {
+	ctx, cancel := bound(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var weapons []entity.Weapon
+
+	result := r.conn(ctx).Where("id IN ?", ids).Find(&weapons)
+
+	err := result.Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		level.Error(r.log(ctx)).Log("msg", "database.weaponRepository.GetByIDs(): failed to fetch from database", "count", len(ids), "err", err)
+		return nil, err
+	}
+
+	return weapons, nil
+}
+
+// GetByName returns the weapon whose name normalizes the same way as name, or
+// an empty one when there is none. Deleted weapons are returned as well, as
+// they keep their name to themselves.
+func (r *weaponRepository) GetByName(ctx context.Context, name string) (entity.Weapon, error) {
+	ctx, cancel := bound(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var weapon entity.Weapon
+
+	result := r.conn(ctx).Unscoped().First(&weapon, "normalized_name = ?", entity.NormalizeWeaponName(name))
+
+	err := result.Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		level.Error(r.log(ctx)).Log("msg", "database.weaponRepository.GetByName(): failed to fetch from database", "name", name, "err", err)
+		return entity.Weapon{}, err
+	}
+
+	return weapon, nil
+}
+
+// GetAll returns the whole catalog sorted by name.
+func (r *weaponRepository) GetAll(ctx context.Context) ([]entity.Weapon, error) {
+	ctx, cancel := bound(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var weapons []entity.Weapon
+
+	result := r.conn(ctx).Order("name").Order("id").Find(&weapons)
+
+	err := result.Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		level.Error(r.log(ctx)).Log("msg", "database.weaponRepository.GetAll(): failed to fetch from database", "err", err)
+		return nil, err
+	}
+
+	return weapons, nil
+}
+
+// Update overwrites every attribute of a weapon, zero values included.
+func (r *weaponRepository) Update(ctx context.Context, req entity.Weapon) error {
+	ctx, cancel := bound(ctx, r.timeouts.Write)
+	defer cancel()
+
+	model := entity.Weapon{Model: gorm.Model{ID: req.ID}}
+
+	result := r.conn(ctx).Model(&model).Select("name", "normalized_name", "damage", "range", "cost").Updates(req)
+	if r.isDuplicateKey(result.Error) {
+		return errWeaponNameTaken
+	}
+
+	if result.Error != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.weaponRepository.Update(): failed to update data in database", "weapon_id", req.ID, "err", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+// Restore brings a deleted weapon back to the catalog, overwriting every
+// attribute of it like Update does.
+func (r *weaponRepository) Restore(ctx context.Context, req entity.Weapon) error {
+	ctx, cancel := bound(ctx, r.timeouts.Write)
+	defer cancel()
+
+	model := entity.Weapon{Model: gorm.Model{ID: req.ID}}
+
+	req.NormalizedName = entity.NormalizeWeaponName(req.Name)
+	req.DeletedAt = gorm.DeletedAt{}
+
+	result := r.conn(ctx).Unscoped().Model(&model).Select("name", "normalized_name", "damage", "range", "cost", "deleted_at").Updates(req)
+	if r.isDuplicateKey(result.Error) {
+		return errWeaponNameTaken
+	}
+
+	if result.Error != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.weaponRepository.Restore(): failed to update data in database", "weapon_id", req.ID, "err", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *weaponRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := bound(ctx, r.timeouts.Write)
+	defer cancel()
+
+	var model entity.Weapon
+
+	result := r.conn(ctx).Delete(&model, "id = ?", id)
+	err := result.Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		level.Error(r.log(ctx)).Log("msg", "database.weaponRepository.Delete(): failed to delete data in database", "weapon_id", id, "err", err)
+		return err
+	}
+
+	return nil
+}
+
+// CountArmaments tells how many armaments still refer to a weapon. Armaments
+// deleted along with their spaceship count as well, since restoring the
+// spaceship brings them back.
+func (r *weaponRepository) CountArmaments(ctx context.Context, id int64) (int64, error) {
+	ctx, cancel := bound(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var count int64
+
+	result := r.conn(ctx).Unscoped().Model(&entity.Armament{}).
+		Joins("JOIN space_ships ON space_ships.id = armaments.space_ship_id").
+		Where("armaments.weapon_id = ?", id).
+		Where("armaments.deleted_at IS NULL OR armaments.deleted_at = space_ships.deleted_at").
+		Count(&count)
+	if result.Error != nil {
+		level.Error(r.log(ctx)).Log("msg", "database.weaponRepository.CountArmaments(): failed to count from database", "weapon_id", id, "err", result.Error)
+		return 0, result.Error
+	}
+
+	return count, nil
+}
@@ -0,0 +1,421 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+)
+
+func TestNewWeaponRepository(t *testing.T) {
+	mockDB, _ := setupMockDB()
+	timeouts := QueryTimeouts{Read: time.Second, Write: 2 * time.Second}
+	logger := setupMockLogger()
+
+	expected := &weaponRepository{
+		db:       mockDB,
+		timeouts: timeouts,
+		logger:   logger,
+	}
+
+	got := NewWeaponRepository(mockDB, timeouts, logger)
+	assert.NotNil(t, got)
+	assert.Equal(t, expected, got)
+}
+
+func TestWeaponRepository_Insert(t *testing.T) {
+	query := "INSERT INTO `weapons` (`created_at`,`updated_at`,`deleted_at`,`name`,`normalized_name`,`damage`,`range`,`cost`) VALUES (?,?,?,?,?,?,?,?)"
+
+	tests := []struct {
+		name    string
+		mocks   func(mock sqlmock.Sqlmock)
+		wantID  uint
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "Turbo Laser", "turbolaser", 500, 20.5, 1000.0).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantID:  0,
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given name taken in the meantime, should return conflict error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "Turbo Laser", "turbolaser", 500, 20.5, 1000.0).
+					WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'turbolaser'"})
+				mock.ExpectRollback()
+			},
+			wantID:  0,
+			wantErr: errWeaponNameTaken,
+		},
+		{
+			name: "Given valid param with no Gorm error, should return the weapon with its normalized name and new ID",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "Turbo Laser", "turbolaser", 500, 20.5, 1000.0).
+					WillReturnResult(sqlmock.NewResult(4, 1))
+				mock.ExpectCommit()
+			},
+			wantID:  4,
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &weaponRepository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			got, err := r.Insert(context.Background(), entity.Weapon{Name: "Turbo Laser", Damage: 500, Range: 20.5, Cost: 1000})
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.wantID, got.ID)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestWeaponRepository_GetByName(t *testing.T) {
+	query := "SELECT * FROM `weapons` WHERE normalized_name = ? ORDER BY `weapons`.`id` LIMIT 1"
+
+	deletedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		mocks   func(mock sqlmock.Sqlmock)
+		want    entity.Weapon
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return empty struct with non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs("turbolaser").
+					WillReturnError(assert.AnError)
+			},
+			want:    entity.Weapon{},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given unknown name, should return empty struct with nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs("turbolaser").
+					WillReturnError(gorm.ErrRecordNotFound)
+			},
+			want:    entity.Weapon{},
+			wantErr: nil,
+		},
+		{
+			name: "Given name spelled differently, should return the weapon with nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs("turbolaser").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "normalized_name"}).
+						AddRow(1, "Turbo Laser", "turbolaser"))
+			},
+			want:    entity.Weapon{Model: gorm.Model{ID: 1}, Name: "Turbo Laser", NormalizedName: "turbolaser"},
+			wantErr: nil,
+		},
+		{
+			name: "Given name of a deleted weapon, should still return the weapon with nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs("turbolaser").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "deleted_at", "name", "normalized_name"}).
+						AddRow(1, deletedAt, "Turbo Laser", "turbolaser"))
+			},
+			want: entity.Weapon{
+				Model:          gorm.Model{ID: 1, DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}},
+				Name:           "Turbo Laser",
+				NormalizedName: "turbolaser",
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &weaponRepository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			got, err := r.GetByName(context.Background(), "turbo-LASER")
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestWeaponRepository_GetByIDs(t *testing.T) {
+	query := "SELECT * FROM `weapons` WHERE id IN (?,?) AND `weapons`.`deleted_at` IS NULL"
+
+	tests := []struct {
+		name    string
+		mocks   func(mock sqlmock.Sqlmock)
+		want    []entity.Weapon
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return nil slice with non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(1, 9).
+					WillReturnError(assert.AnError)
+			},
+			want:    nil,
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given some unknown IDs, should only return the weapons found",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(1, 9).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+						AddRow(1, "Turbo Laser"))
+			},
+			want:    []entity.Weapon{{Model: gorm.Model{ID: 1}, Name: "Turbo Laser"}},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &weaponRepository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			got, err := r.GetByIDs(context.Background(), []int64{1, 9})
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestWeaponRepository_Update(t *testing.T) {
+	query := "UPDATE `weapons` SET `updated_at`=?,`name`=?,`normalized_name`=?,`damage`=?,`range`=?,`cost`=? WHERE `weapons`.`deleted_at` IS NULL AND `id` = ?"
+
+	tests := []struct {
+		name    string
+		mocks   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(sqlmock.AnyArg(), "Ion Cannon", "ioncannon", 0, 0.0, 0.0, 2).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given name taken in the meantime, should return conflict error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(sqlmock.AnyArg(), "Ion Cannon", "ioncannon", 0, 0.0, 0.0, 2).
+					WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'ioncannon'"})
+				mock.ExpectRollback()
+			},
+			wantErr: errWeaponNameTaken,
+		},
+		{
+			name: "Given zero values, should still write them",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(sqlmock.AnyArg(), "Ion Cannon", "ioncannon", 0, 0.0, 0.0, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &weaponRepository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			err := r.Update(context.Background(), entity.Weapon{Model: gorm.Model{ID: 2}, Name: "Ion Cannon", NormalizedName: "ioncannon"})
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestWeaponRepository_Restore(t *testing.T) {
+	query := "UPDATE `weapons` SET `updated_at`=?,`deleted_at`=?,`name`=?,`normalized_name`=?,`damage`=?,`range`=?,`cost`=? WHERE `id` = ?"
+
+	tests := []struct {
+		name    string
+		mocks   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(sqlmock.AnyArg(), nil, "Turbo Laser", "turbolaser", 500, 20.5, 1000.0, 1).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given deleted weapon, should overwrite it and clear its deletion",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(sqlmock.AnyArg(), nil, "Turbo Laser", "turbolaser", 500, 20.5, 1000.0, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &weaponRepository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			err := r.Restore(context.Background(), entity.Weapon{Model: gorm.Model{ID: 1}, Name: "Turbo Laser", Damage: 500, Range: 20.5, Cost: 1000})
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestWeaponRepository_CountArmaments(t *testing.T) {
+	query := "SELECT count(*) FROM `armaments` JOIN space_ships ON space_ships.id = armaments.space_ship_id WHERE armaments.weapon_id = ? AND (armaments.deleted_at IS NULL OR armaments.deleted_at = space_ships.deleted_at)"
+
+	tests := []struct {
+		name    string
+		mocks   func(mock sqlmock.Sqlmock)
+		want    int64
+		wantErr error
+	}{
+		{
+			name: "Got error in Gorm query, should return non-nil error",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(2).
+					WillReturnError(assert.AnError)
+			},
+			want:    0,
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given weapon in use, should return how many armaments use it",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(3))
+			},
+			want:    3,
+			wantErr: nil,
+		},
+		{
+			name: "Given weapon only used by a deleted spaceship, should still count its armaments",
+			mocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+			},
+			want:    1,
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := setupMockLogger()
+			mockDB, mock := setupMockDB()
+			sqlDB, _ := mockDB.DB()
+			defer sqlDB.Close()
+
+			r := &weaponRepository{
+				db:     mockDB,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mock)
+
+			got, err := r.CountArmaments(context.Background(), 2)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
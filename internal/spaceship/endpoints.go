@@ -8,37 +8,52 @@ import (
 	"github.com/go-kit/kit/endpoint"
 
 	"github.com/wndisra/galactic-svc/internal/entity"
+	"github.com/wndisra/galactic-svc/internal/helpers"
 )
 
 type Service interface {
-	Create(ctx context.Context, req entity.SpaceShip) error
+	Create(ctx context.Context, req entity.SpaceShip) (entity.SpaceShip, error)
 	GetByID(ctx context.Context, id int64) (entity.SpaceShip, error)
-	Update(ctx context.Context, id int64, req entity.SpaceShip) error
-	Delete(ctx context.Context, id int64) error
-	GetAll(ctx context.Context, req entity.SpaceShip) ([]entity.SpaceShip, error)
+	Update(ctx context.Context, id int64, match entity.VersionMatch, patch entity.SpaceShipPatch) (uint, error)
+	Replace(ctx context.Context, id int64, match entity.VersionMatch, req entity.SpaceShip) (uint, error)
+	Delete(ctx context.Context, id int64, match entity.VersionMatch) error
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64, match entity.VersionMatch) error
+	GetAll(ctx context.Context, filter entity.SpaceShipFilter, opts entity.ListOptions) (entity.SpaceShipPage, error)
+	Bulk(ctx context.Context, req entity.SpaceShipBulk) (entity.SpaceShipBulkResult, error)
+	Export(ctx context.Context, fn func(spaceship entity.SpaceShip) error) error
+
+	GetArmaments(ctx context.Context, spaceshipID int64) ([]entity.Armament, error)
+	CreateArmament(ctx context.Context, spaceshipID int64, req entity.Armament) (entity.Armament, error)
+	UpdateArmament(ctx context.Context, spaceshipID int64, id int64, patch entity.ArmamentPatch) (entity.Armament, error)
+	DeleteArmament(ctx context.Context, spaceshipID int64, id int64) error
 }
 
 type CreateRequestModel struct {
-	Name      string
-	Class     string
-	Crew      int64
-	Image     string
-	Value     float64
-	Status    string
-	Armaments []armamentReqModel
+	Name      string             `json:"name" validate:"required,max=100"`
+	Class     string             `json:"class" validate:"required,spaceship_class"`
+	Crew      int64              `json:"crew" validate:"gte=0"`
+	Image     string             `json:"image" validate:"omitempty,http_url,max=255"`
+	Value     float64            `json:"value" validate:"gte=0"`
+	Status    string             `json:"status" validate:"required,spaceship_status"`
+	Armaments []armamentReqModel `json:"armament" validate:"max=50,dive"`
 }
 
 type armamentReqModel struct {
-	Title string
-	Qty   int
+	WeaponID uint `json:"weapon_id" validate:"required"`
+	Qty      int  `json:"qty" validate:"gte=1"`
+}
+
+func (r CreateRequestModel) Validate() error {
+	return validate.Validate(r)
 }
 
 func (r CreateRequestModel) ToEntity() entity.SpaceShip {
 	armaments := make([]entity.Armament, len(r.Armaments))
 	for i, armament := range r.Armaments {
 		armaments[i] = entity.Armament{
-			Title: armament.Title,
-			Qty:   armament.Qty,
+			WeaponID: armament.WeaponID,
+			Qty:      armament.Qty,
 		}
 	}
 
@@ -54,17 +69,23 @@ func (r CreateRequestModel) ToEntity() entity.SpaceShip {
 }
 
 type CreateResponseModel struct {
-	Success bool
+	SpaceShip entity.SpaceShip
 }
 
 // @BasePath    /
 // Create       godoc
-// @Description Create new spaceship.
+// @Description Create new spaceship, returned as fetched by ID.
 // @Tags        Spaceship
 // @Accept      json
 // @Produce     json
-// @Param       request body createRequest true "Request body (JSON)"
+// @Param       request         body   createRequest true  "Request body (JSON)"
+// @Param       Idempotency-Key header string        false "Replays the response to an earlier request with the same key"
 // @Success     201
+// @Header      201 {string} Location "Path of the spaceship"
+// @Header      201 {string} ETag "Version of the spaceship"
+// @Failure     400
+// @Failure     409
+// @Failure     422
 // @Failure     500
 // @Router      /spaceship [post]
 func MakeEndpointCreate(s Service) endpoint.Endpoint {
@@ -74,13 +95,13 @@ func MakeEndpointCreate(s Service) endpoint.Endpoint {
 			return nil, errors.New("MakeEndpointCreate(): failed cast request")
 		}
 
-		err = s.Create(ctx, req.ToEntity())
+		spaceship, err := s.Create(ctx, req.ToEntity())
 		if err != nil {
 			return nil, fmt.Errorf("MakeEndpointCreate(): %w", err)
 		}
 
 		return CreateResponseModel{
-			Success: true,
+			SpaceShip: spaceship,
 		}, nil
 	}
 }
@@ -96,11 +117,14 @@ type GetByIDResponseModel struct {
 // @BasePath    /
 // GetByID      godoc
 // @Description Fetch existing spaceship by a specific ID.
+// @Description The ETag header holds its version, to be sent back in If-Match when changing it.
 // @Tags        Spaceship
 // @Produce     json
 // @Param       id path string true "Spaceship ID (integer)"
 // @Success     200
+// @Header      200 {string} ETag "Version of the spaceship"
 // @Failure     400
+// @Failure     404
 // @Failure     500
 // @Router      /spaceship/{id} [get]
 func MakeEndpointGetByID(s Service) endpoint.Endpoint {
@@ -121,51 +145,115 @@ func MakeEndpointGetByID(s Service) endpoint.Endpoint {
 	}
 }
 
+// UpdateRequestModel is a JSON merge patch (RFC 7396) of a spaceship. Nil
+// fields were not sent by the client and are left untouched. Version is what
+// the client expects of the stored version through If-Match.
 type UpdateRequestModel struct {
 	ID        int64
-	Name      string
-	Class     string
-	Crew      int64
-	Image     string
-	Value     float64
-	Status    string
-	Armaments []armamentReqModel
+	Version   entity.VersionMatch
+	Name      *string
+	Class     *string
+	Crew      *int64
+	Image     *string
+	Value     *float64
+	Status    *string
+	Armaments *[]armamentReqModel
 }
 
-func (r UpdateRequestModel) ToEntity() entity.SpaceShip {
-	armaments := make([]entity.Armament, len(r.Armaments))
-	for i, armament := range r.Armaments {
-		armaments[i] = entity.Armament{
-			Title: armament.Title,
-			Qty:   armament.Qty,
-		}
+// Validate checks the fields present in the patch against the rules of a
+// full spaceship, see CreateRequestModel.
+func (r UpdateRequestModel) Validate() error {
+	var full CreateRequestModel
+	var present []string
+
+	if r.Name != nil {
+		full.Name = *r.Name
+		present = append(present, "name")
 	}
 
-	return entity.SpaceShip{
-		Name:      r.Name,
-		Class:     r.Class,
-		Crew:      r.Crew,
-		Image:     r.Image,
-		Value:     r.Value,
-		Status:    r.Status,
-		Armaments: armaments,
+	if r.Class != nil {
+		full.Class = *r.Class
+		present = append(present, "class")
+	}
+
+	if r.Crew != nil {
+		full.Crew = *r.Crew
+		present = append(present, "crew")
+	}
+
+	if r.Image != nil {
+		full.Image = *r.Image
+		present = append(present, "image")
+	}
+
+	if r.Value != nil {
+		full.Value = *r.Value
+		present = append(present, "value")
+	}
+
+	if r.Status != nil {
+		full.Status = *r.Status
+		present = append(present, "status")
+	}
+
+	if r.Armaments != nil {
+		full.Armaments = *r.Armaments
+		present = append(present, "armament")
 	}
+
+	return helpers.KeepFieldErrors(full.Validate(), present...)
+}
+
+func (r UpdateRequestModel) ToPatch() entity.SpaceShipPatch {
+	patch := entity.SpaceShipPatch{
+		Name:   r.Name,
+		Class:  r.Class,
+		Crew:   r.Crew,
+		Image:  r.Image,
+		Value:  r.Value,
+		Status: r.Status,
+	}
+
+	if r.Armaments != nil {
+		armaments := make([]entity.Armament, len(*r.Armaments))
+		for i, armament := range *r.Armaments {
+			armaments[i] = entity.Armament{
+				WeaponID: armament.WeaponID,
+				Qty:      armament.Qty,
+			}
+		}
+		patch.Armaments = &armaments
+	}
+
+	return patch
 }
 
 type UpdateResponseModel struct {
 	Success bool
+	Version uint
 }
 
 // @BasePath    /
 // Update       godoc
-// @Description Update existing spaceship by a specific ID.
+// @Description Partially update existing spaceship by a specific ID, following JSON merge patch (RFC 7396) semantics.
+// @Description Only the fields present in the body are changed, and sending "armament" replaces every armament.
+// @Description A null field is reset to its zero value.
 // @Tags        Spaceship
+// @Accept      application/merge-patch+json
 // @Accept      json
 // @Produce     json
-// @Param       id path string true "Spaceship ID (integer)"
-// @Param       request body updateRequest true "Request body (JSON)"
+// @Param       id              path   string        true  "Spaceship ID (integer)"
+// @Param       If-Match        header string        false "ETags of the spaceship, or *, the request fails unless one of them is current"
+// @Param       request         body   updateRequest true  "Request body (JSON)"
+// @Param       Idempotency-Key header string        false "Replays the response to an earlier request with the same key"
 // @Success     200
+// @Header      200 {string} ETag "Version of the spaceship"
 // @Failure     400
+// @Failure     404
+// @Failure     409
+// @Failure     412
+// @Failure     415
+// @Failure     422
 // @Failure     500
 // @Router      /spaceship/{id} [patch]
 func MakeEndpointUpdate(s Service) endpoint.Endpoint {
@@ -175,19 +263,83 @@ func MakeEndpointUpdate(s Service) endpoint.Endpoint {
 			return nil, errors.New("MakeEndpointUpdate(): failed cast request")
 		}
 
-		err = s.Update(ctx, req.ID, req.ToEntity())
+		version, err := s.Update(ctx, req.ID, req.Version, req.ToPatch())
 		if err != nil {
 			return nil, fmt.Errorf("MakeEndpointUpdate(): %w", err)
 		}
 
 		return UpdateResponseModel{
 			Success: true,
+			Version: version,
+		}, nil
+	}
+}
+
+// ReplaceRequestModel carries the whole new state of a spaceship, which
+// follows the same rules as a newly created one.
+type ReplaceRequestModel struct {
+	ID        int64
+	Version   entity.VersionMatch
+	SpaceShip CreateRequestModel
+}
+
+func (r ReplaceRequestModel) Validate() error {
+	return r.SpaceShip.Validate()
+}
+
+func (r ReplaceRequestModel) ToEntity() entity.SpaceShip {
+	return r.SpaceShip.ToEntity()
+}
+
+type ReplaceResponseModel struct {
+	Success bool
+	Version uint
+}
+
+// @BasePath    /
+// Replace      godoc
+// @Description Replace existing spaceship by a specific ID, armaments included.
+// @Tags        Spaceship
+// @Accept      json
+// @Produce     json
+// @Param       id              path   string        true  "Spaceship ID (integer)"
+// @Param       If-Match        header string        false "ETags of the spaceship, or *, the request fails unless one of them is current"
+// @Param       request         body   createRequest true  "Request body (JSON)"
+// @Param       Idempotency-Key header string        false "Replays the response to an earlier request with the same key"
+// @Success     200
+// @Header      200 {string} ETag "Version of the spaceship"
+// @Failure     400
+// @Failure     404
+// @Failure     409
+// @Failure     412
+// @Failure     422
+// @Failure     500
+// @Router      /spaceship/{id} [put]
+func MakeEndpointReplace(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req, ok := request.(ReplaceRequestModel)
+		if !ok {
+			return nil, errors.New("MakeEndpointReplace(): failed cast request")
+		}
+
+		version, err := s.Replace(ctx, req.ID, req.Version, req.ToEntity())
+		if err != nil {
+			return nil, fmt.Errorf("MakeEndpointReplace(): %w", err)
+		}
+
+		return ReplaceResponseModel{
+			Success: true,
+			Version: version,
 		}, nil
 	}
 }
 
+// DeleteByIDRequestModel soft deletes a spaceship, unless Purge asks for it
+// to be removed for good.
 type DeleteByIDRequestModel struct {
-	ID int64
+	ID      int64
+	Version entity.VersionMatch
+	Purge   bool
 }
 
 type DeleteByIDResponseModel struct {
@@ -196,12 +348,21 @@ type DeleteByIDResponseModel struct {
 
 // @BasePath    /
 // Delete       godoc
-// @Description Delete existing spaceship by a specific ID.
+// @Description Delete existing spaceship by a specific ID, along with its armaments.
+// @Description The spaceship is soft deleted and can be restored, unless purged. Purging requires the admin token.
 // @Tags        Spaceship
 // @Produce     json
-// @Param       id path string true "Spaceship ID (integer)"
+// @Param       id              path   string true  "Spaceship ID (integer)"
+// @Param       purge           query  bool   false "Remove the spaceship for good, even a soft deleted one"
+// @Param       If-Match        header string false "ETags of the spaceship, or *, the request fails unless one of them is current"
+// @Param       Authorization   header string false "Bearer admin token, required to purge"
+// @Param       Idempotency-Key header string false "Replays the response to an earlier request with the same key"
 // @Success     200
 // @Failure     400
+// @Failure     401
+// @Failure     404
+// @Failure     409
+// @Failure     412
 // @Failure     500
 // @Router      /spaceship/{id} [delete]
 func MakeEndpointDeleteByID(s Service) endpoint.Endpoint {
@@ -211,7 +372,11 @@ func MakeEndpointDeleteByID(s Service) endpoint.Endpoint {
 			return nil, errors.New("MakeEndpointDeleteByID(): failed cast request")
 		}
 
-		err = s.Delete(ctx, req.ID)
+		if req.Purge {
+			err = s.Purge(ctx, req.ID, req.Version)
+		} else {
+			err = s.Delete(ctx, req.ID, req.Version)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("MakeEndpointDeleteByID(): %w", err)
 		}
@@ -222,22 +387,332 @@ func MakeEndpointDeleteByID(s Service) endpoint.Endpoint {
 	}
 }
 
+type RestoreRequestModel struct {
+	ID int64
+}
+
+type RestoreResponseModel struct {
+	Success bool
+}
+
+// @BasePath    /
+// Restore      godoc
+// @Description Restore a soft deleted spaceship by a specific ID, along with the armaments deleted with it.
+// @Tags        Spaceship
+// @Produce     json
+// @Param       id              path   string true  "Spaceship ID (integer)"
+// @Param       Idempotency-Key header string false "Replays the response to an earlier request with the same key"
+// @Success     200
+// @Failure     400
+// @Failure     404
+// @Failure     409
+// @Failure     500
+// @Router      /spaceship/{id}/restore [post]
+func MakeEndpointRestore(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req, ok := request.(RestoreRequestModel)
+		if !ok {
+			return nil, errors.New("MakeEndpointRestore(): failed cast request")
+		}
+
+		err = s.Restore(ctx, req.ID)
+		if err != nil {
+			return nil, fmt.Errorf("MakeEndpointRestore(): %w", err)
+		}
+
+		return RestoreResponseModel{
+			Success: true,
+		}, nil
+	}
+}
+
+// BulkRequestModel lists spaceships to create, patch and delete at once, up to
+// 1000 of each. Items are validated like their single counterparts, and a
+// single invalid one rejects the whole request, whatever the mode.
+type BulkRequestModel struct {
+	Mode   string               `json:"mode" validate:"omitempty,bulk_mode"`
+	Create []CreateRequestModel `json:"create" validate:"max=1000"`
+	Update []UpdateRequestModel `json:"update" validate:"max=1000"`
+	Delete []int64              `json:"delete" validate:"max=1000,dive,gte=1"`
+}
+
+func (r BulkRequestModel) Validate() error {
+	var fields []helpers.FieldError
+	collect := func(err error) error {
+		var domainErr *helpers.Error
+		if err != nil && (!errors.As(err, &domainErr) || !errors.Is(err, helpers.ErrValidation)) {
+			return err
+		}
+
+		if err != nil {
+			fields = append(fields, domainErr.Fields...)
+		}
+
+		return nil
+	}
+
+	if err := collect(validate.Validate(r)); err != nil {
+		return err
+	}
+
+	for i, item := range r.Create {
+		if err := collect(helpers.PrefixFieldErrors(item.Validate(), fmt.Sprintf("create[%d]", i))); err != nil {
+			return err
+		}
+	}
+
+	for i, item := range r.Update {
+		if item.ID < 1 {
+			fields = append(fields, helpers.FieldError{Field: fmt.Sprintf("update[%d].id", i), Message: "must be 1 or greater"})
+		}
+
+		if err := collect(helpers.PrefixFieldErrors(item.Validate(), fmt.Sprintf("update[%d]", i))); err != nil {
+			return err
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return helpers.NewValidationError("request validation failed", fields...)
+}
+
+func (r BulkRequestModel) ToEntity() entity.SpaceShipBulk {
+	create := make([]entity.SpaceShip, len(r.Create))
+	for i, item := range r.Create {
+		create[i] = item.ToEntity()
+	}
+
+	update := make([]entity.SpaceShipUpdate, len(r.Update))
+	for i, item := range r.Update {
+		update[i] = entity.SpaceShipUpdate{
+			ID:      item.ID,
+			Version: item.Version,
+			Patch:   item.ToPatch(),
+		}
+	}
+
+	return entity.SpaceShipBulk{
+		Create:     create,
+		Update:     update,
+		Delete:     r.Delete,
+		BestEffort: r.Mode == "best_effort",
+	}
+}
+
+type BulkResponseModel struct {
+	Mode   string
+	Result entity.SpaceShipBulkResult
+}
+
+// @BasePath    /
+// Bulk         godoc
+// @Description Create, patch and delete many spaceships at once. Creations are inserted in batches.
+// @Description In atomic mode, the default, nothing is written unless every item succeeds.
+// @Description In best_effort mode, each item is applied on its own.
+// @Description Each item gets a result with its status, and a problem detail when it failed.
+// @Description An invalid item rejects the whole request with 422, whatever the mode.
+// @Tags        Spaceship
+// @Accept      json
+// @Produce     json
+// @Param       request         body   bulkRequest true  "Request body (JSON)"
+// @Param       Idempotency-Key header string      false "Replays the response to an earlier request with the same key"
+// @Success     200 {object} bulkResponse "Every item succeeded"
+// @Success     207 {object} bulkResponse "Some items failed"
+// @Failure     400
+// @Failure     409
+// @Failure     422
+// @Failure     500
+// @Router      /spaceship/bulk [post]
+func MakeEndpointBulk(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req, ok := request.(BulkRequestModel)
+		if !ok {
+			return nil, errors.New("MakeEndpointBulk(): failed cast request")
+		}
+
+		result, err := s.Bulk(ctx, req.ToEntity())
+		if err != nil {
+			return nil, fmt.Errorf("MakeEndpointBulk(): %w", err)
+		}
+
+		mode := req.Mode
+		if mode == "" {
+			mode = "atomic"
+		}
+
+		return BulkResponseModel{
+			Mode:   mode,
+			Result: result,
+		}, nil
+	}
+}
+
+type ExportRequestModel struct {
+	Format string
+}
+
+// ExportResponseModel streams the fleet: Export hands every spaceship over to
+// fn once the response is being written.
+type ExportResponseModel struct {
+	Format string
+	Export func(fn func(spaceship entity.SpaceShip) error) error
+}
+
+// @BasePath    /
+// Export       godoc
+// @Description Export every spaceship, armaments included, as CSV or NDJSON, streamed ordered by ID.
+// @Description CSV lists armaments in a single column, as weapon_id:qty pairs separated by semicolons.
+// @Description A failure midway aborts the stream, leaving it unterminated.
+// @Tags        Spaceship
+// @Produce     text/csv,application/x-ndjson
+// @Param       format query string false "Export format, ndjson by default" Enums(csv, ndjson)
+// @Success     200 {object} exportRecord "One per line"
+// @Failure     400
+// @Failure     500
+// @Router      /spaceship/export [get]
+func MakeEndpointExport(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req, ok := request.(ExportRequestModel)
+		if !ok {
+			return nil, errors.New("MakeEndpointExport(): failed cast request")
+		}
+
+		return ExportResponseModel{
+			Format: req.Format,
+			Export: func(fn func(spaceship entity.SpaceShip) error) error {
+				return s.Export(ctx, fn)
+			},
+		}, nil
+	}
+}
+
+// ImportRequestModel lists the spaceships read from an import file. Lines
+// which could not be read, or are invalid, carry their error instead.
+type ImportRequestModel struct {
+	Lines []ImportLineModel
+}
+
+type ImportLineModel struct {
+	Line      int
+	SpaceShip CreateRequestModel
+	Err       error
+}
+
+type ImportResponseModel struct {
+	Lines []ImportLineResult
+}
+
+type ImportLineResult struct {
+	Line int
+	ID   uint
+	Err  error
+}
+
+// @BasePath    /
+// Import       godoc
+// @Description Create spaceships from a CSV or NDJSON file, in the export format. IDs are ignored.
+// @Description The format is given by the format query param, or else by the Content-Type.
+// @Description Each line is applied on its own, the errors are reported with their line number.
+// @Tags        Spaceship
+// @Accept      text/csv,application/x-ndjson
+// @Produce     json
+// @Param       format          query  string false "Import format" Enums(csv, ndjson)
+// @Param       request         body   string true  "CSV or NDJSON file"
+// @Param       Idempotency-Key header string false "Replays the response to an earlier request with the same key"
+// @Success     200 {object} importResponse "Every line was imported"
+// @Success     207 {object} importResponse "Some lines failed"
+// @Failure     400
+// @Failure     409
+// @Failure     415
+// @Failure     500
+// @Router      /spaceship/import [post]
+func MakeEndpointImport(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req, ok := request.(ImportRequestModel)
+		if !ok {
+			return nil, errors.New("MakeEndpointImport(): failed cast request")
+		}
+
+		results := make([]ImportLineResult, len(req.Lines))
+		var spaceships []entity.SpaceShip
+		var indexes []int
+		for i, line := range req.Lines {
+			results[i] = ImportLineResult{Line: line.Line, Err: line.Err}
+			if line.Err != nil {
+				continue
+			}
+
+			spaceships = append(spaceships, line.SpaceShip.ToEntity())
+			indexes = append(indexes, i)
+		}
+
+		if len(spaceships) > 0 {
+			result, err := s.Bulk(ctx, entity.SpaceShipBulk{Create: spaceships, BestEffort: true})
+			if err != nil {
+				return nil, fmt.Errorf("MakeEndpointImport(): %w", err)
+			}
+
+			for i, item := range result.Create {
+				results[indexes[i]].ID = item.ID
+				results[indexes[i]].Err = item.Err
+			}
+		}
+
+		return ImportResponseModel{
+			Lines: results,
+		}, nil
+	}
+}
+
 type GetAllRequestModel struct {
-	Name   string
-	Class  string
-	Status string
+	Name     string
+	Classes  []string
+	Statuses []string
+	MinCrew  *int64
+	MaxCrew  *int64
+	MinValue *float64
+	MaxValue *float64
+
+	IncludeDeleted bool
+
+	Sort      []entity.SortField
+	Limit     int
+	Offset    int
+	Cursor    string
+	WithTotal bool
 }
 
-func (r GetAllRequestModel) ToEntity() entity.SpaceShip {
-	return entity.SpaceShip{
-		Name:   r.Name,
-		Class:  r.Class,
-		Status: r.Status,
+func (r GetAllRequestModel) ToFilter() entity.SpaceShipFilter {
+	return entity.SpaceShipFilter{
+		Name:     r.Name,
+		Classes:  r.Classes,
+		Statuses: r.Statuses,
+		MinCrew:  r.MinCrew,
+		MaxCrew:  r.MaxCrew,
+		MinValue: r.MinValue,
+		MaxValue: r.MaxValue,
+
+		IncludeDeleted: r.IncludeDeleted,
+	}
+}
+
+func (r GetAllRequestModel) ToListOptions() entity.ListOptions {
+	return entity.ListOptions{
+		Sort:      r.Sort,
+		Limit:     r.Limit,
+		Offset:    r.Offset,
+		Cursor:    r.Cursor,
+		WithTotal: r.WithTotal,
 	}
 }
 
 type GetAllResponseModel struct {
-	SpaceShip []entity.SpaceShip
+	SpaceShip  []entity.SpaceShip
+	NextCursor string
+	HasMore    bool
+	Total      *int64
 }
 
 // @BasePath    /
@@ -245,7 +720,21 @@ type GetAllResponseModel struct {
 // @Description Get all spaceships.
 // @Tags        Spaceship
 // @Produce     json
-// @Success     200
+// @Param       name            query string false "Partial match on the spaceship name"
+// @Param       class           query string false "Exact class, comma separated for several classes"
+// @Param       status          query string false "Exact status, comma separated for several statuses"
+// @Param       min_crew        query int    false "Minimum crew (inclusive)"
+// @Param       max_crew        query int    false "Maximum crew (inclusive)"
+// @Param       min_value       query number false "Minimum value (inclusive)"
+// @Param       max_value       query number false "Maximum value (inclusive)"
+// @Param       sort            query string false "Comma separated fields to sort by, prefixed with - for descending order (id, name, class, crew, value, status, created_at, updated_at)"
+// @Param       limit           query int    false "Page size, 20 by default and 100 at most"
+// @Param       offset          query int    false "Number of spaceships to skip, cannot be combined with cursor"
+// @Param       cursor          query string false "Opaque cursor taken from the next_cursor of a previous page"
+// @Param       include_total   query bool   false "Also count every spaceship matching the filters"
+// @Param       include_deleted query bool   false "Also list soft deleted spaceships"
+// @Success     200 {object} getAllResponse
+// @Failure     400
 // @Failure     500
 // @Router      /spaceship [get]
 func MakeEndpointGetAll(s Service) endpoint.Endpoint {
@@ -255,13 +744,225 @@ func MakeEndpointGetAll(s Service) endpoint.Endpoint {
 			return nil, errors.New("MakeEndpointGetAll(): failed cast request")
 		}
 
-		spaceships, err := s.GetAll(ctx, req.ToEntity())
+		page, err := s.GetAll(ctx, req.ToFilter(), req.ToListOptions())
 		if err != nil {
 			return nil, fmt.Errorf("MakeEndpointGetAll(): %w", err)
 		}
 
 		return GetAllResponseModel{
-			SpaceShip: spaceships,
+			SpaceShip:  page.SpaceShips,
+			NextCursor: page.NextCursor,
+			HasMore:    page.HasMore,
+			Total:      page.Total,
+		}, nil
+	}
+}
+
+type GetArmamentsRequestModel struct {
+	SpaceShipID int64
+}
+
+type GetArmamentsResponseModel struct {
+	Armaments []entity.Armament
+}
+
+// @BasePath    /
+// GetArmaments godoc
+// @Description Get every armament of an existing spaceship.
+// @Tags        Armament
+// @Produce     json
+// @Param       id path string true "Spaceship ID (integer)"
+// @Success     200 {array} armamentResponse
+// @Failure     400
+// @Failure     404
+// @Failure     500
+// @Router      /spaceship/{id}/armaments [get]
+func MakeEndpointGetArmaments(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req, ok := request.(GetArmamentsRequestModel)
+		if !ok {
+			return nil, errors.New("MakeEndpointGetArmaments(): failed cast request")
+		}
+
+		armaments, err := s.GetArmaments(ctx, req.SpaceShipID)
+		if err != nil {
+			return nil, fmt.Errorf("MakeEndpointGetArmaments(): %w", err)
+		}
+
+		return GetArmamentsResponseModel{
+			Armaments: armaments,
+		}, nil
+	}
+}
+
+type CreateArmamentRequestModel struct {
+	SpaceShipID int64
+	Armament    armamentReqModel
+}
+
+func (r CreateArmamentRequestModel) Validate() error {
+	return validate.Validate(r.Armament)
+}
+
+func (r CreateArmamentRequestModel) ToEntity() entity.Armament {
+	return entity.Armament{
+		WeaponID: r.Armament.WeaponID,
+		Qty:      r.Armament.Qty,
+	}
+}
+
+type CreateArmamentResponseModel struct {
+	Armament entity.Armament
+}
+
+// @BasePath      /
+// CreateArmament godoc
+// @Description   Add an armament to an existing spaceship.
+// @Tags          Armament
+// @Accept        json
+// @Produce       json
+// @Param         id              path   string      true  "Spaceship ID (integer)"
+// @Param         request         body   armamentReq true  "Request body (JSON)"
+// @Param         Idempotency-Key header string      false "Replays the response to an earlier request with the same key"
+// @Success       201 {object} armamentResponse
+// @Failure       400
+// @Failure       404
+// @Failure       409
+// @Failure       422
+// @Failure       500
+// @Router        /spaceship/{id}/armaments [post]
+func MakeEndpointCreateArmament(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req, ok := request.(CreateArmamentRequestModel)
+		if !ok {
+			return nil, errors.New("MakeEndpointCreateArmament(): failed cast request")
+		}
+
+		armament, err := s.CreateArmament(ctx, req.SpaceShipID, req.ToEntity())
+		if err != nil {
+			return nil, fmt.Errorf("MakeEndpointCreateArmament(): %w", err)
+		}
+
+		return CreateArmamentResponseModel{
+			Armament: armament,
+		}, nil
+	}
+}
+
+// UpdateArmamentRequestModel is a JSON merge patch (RFC 7396) of an armament.
+// Nil fields were not sent by the client and are left untouched.
+type UpdateArmamentRequestModel struct {
+	SpaceShipID int64
+	ArmamentID  int64
+	WeaponID    *uint
+	Qty         *int
+}
+
+// Validate checks the fields present in the patch against the rules of a
+// full armament, see armamentReqModel.
+func (r UpdateArmamentRequestModel) Validate() error {
+	var full armamentReqModel
+	var present []string
+
+	if r.WeaponID != nil {
+		full.WeaponID = *r.WeaponID
+		present = append(present, "weapon_id")
+	}
+
+	if r.Qty != nil {
+		full.Qty = *r.Qty
+		present = append(present, "qty")
+	}
+
+	return helpers.KeepFieldErrors(validate.Validate(full), present...)
+}
+
+func (r UpdateArmamentRequestModel) ToPatch() entity.ArmamentPatch {
+	return entity.ArmamentPatch{
+		WeaponID: r.WeaponID,
+		Qty:      r.Qty,
+	}
+}
+
+type UpdateArmamentResponseModel struct {
+	Armament entity.Armament
+}
+
+// @BasePath      /
+// UpdateArmament godoc
+// @Description   Partially update an armament of an existing spaceship, following JSON merge patch (RFC 7396) semantics.
+// @Description   The armament keeps its ID.
+// @Tags          Armament
+// @Accept        application/merge-patch+json
+// @Accept        json
+// @Produce       json
+// @Param         id              path   string                true  "Spaceship ID (integer)"
+// @Param         armamentId      path   string                true  "Armament ID (integer)"
+// @Param         request         body   updateArmamentRequest true  "Request body (JSON)"
+// @Param         Idempotency-Key header string                false "Replays the response to an earlier request with the same key"
+// @Success       200 {object} armamentResponse
+// @Failure       400
+// @Failure       404
+// @Failure       409
+// @Failure       415
+// @Failure       422
+// @Failure       500
+// @Router        /spaceship/{id}/armaments/{armamentId} [patch]
+func MakeEndpointUpdateArmament(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req, ok := request.(UpdateArmamentRequestModel)
+		if !ok {
+			return nil, errors.New("MakeEndpointUpdateArmament(): failed cast request")
+		}
+
+		armament, err := s.UpdateArmament(ctx, req.SpaceShipID, req.ArmamentID, req.ToPatch())
+		if err != nil {
+			return nil, fmt.Errorf("MakeEndpointUpdateArmament(): %w", err)
+		}
+
+		return UpdateArmamentResponseModel{
+			Armament: armament,
+		}, nil
+	}
+}
+
+type DeleteArmamentRequestModel struct {
+	SpaceShipID int64
+	ArmamentID  int64
+}
+
+type DeleteArmamentResponseModel struct {
+	Success bool
+}
+
+// @BasePath      /
+// DeleteArmament godoc
+// @Description   Delete an armament of an existing spaceship.
+// @Tags          Armament
+// @Produce       json
+// @Param         id              path   string true  "Spaceship ID (integer)"
+// @Param         armamentId      path   string true  "Armament ID (integer)"
+// @Param         Idempotency-Key header string false "Replays the response to an earlier request with the same key"
+// @Success       200
+// @Failure       400
+// @Failure       404
+// @Failure       409
+// @Failure       500
+// @Router        /spaceship/{id}/armaments/{armamentId} [delete]
+func MakeEndpointDeleteArmament(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req, ok := request.(DeleteArmamentRequestModel)
+		if !ok {
+			return nil, errors.New("MakeEndpointDeleteArmament(): failed cast request")
+		}
+
+		err = s.DeleteArmament(ctx, req.SpaceShipID, req.ArmamentID)
+		if err != nil {
+			return nil, fmt.Errorf("MakeEndpointDeleteArmament(): %w", err)
+		}
+
+		return DeleteArmamentResponseModel{
+			Success: true,
 		}, nil
 	}
 }
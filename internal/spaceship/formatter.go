@@ -1,28 +1,36 @@
 package spaceship
 
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+	"github.com/wndisra/galactic-svc/internal/helpers"
+)
+
 func formatCreateResponse(res CreateResponseModel) map[string]interface{} {
-	return map[string]interface{}{
-		"success": res.Success,
-	}
+	return formatSpaceShip(res.SpaceShip)
 }
 
 func formatGetByIDResponse(res GetByIDResponseModel) map[string]interface{} {
-	armaments := make([]armamentResponse, len(res.SpaceShip.Armaments))
-	for i, armament := range res.SpaceShip.Armaments {
-		armaments[i] = armamentResponse{
-			Title: armament.Title,
-			Qty:   armament.Qty,
-		}
-	}
+	return formatSpaceShip(res.SpaceShip)
+}
+
+func formatSpaceShip(spaceship entity.SpaceShip) map[string]interface{} {
+	armaments := formatArmaments(spaceship.Armaments)
 
 	return map[string]interface{}{
-		"id":       res.SpaceShip.ID,
-		"name":     res.SpaceShip.Name,
-		"class":    res.SpaceShip.Class,
-		"crew":     res.SpaceShip.Crew,
-		"image":    res.SpaceShip.Value,
-		"status":   res.SpaceShip.Status,
+		"id":       spaceship.ID,
+		"name":     spaceship.Name,
+		"class":    spaceship.Class,
+		"crew":     spaceship.Crew,
+		"image":    spaceship.Image,
+		"value":    spaceship.Value,
+		"status":   spaceship.Status,
 		"armament": armaments,
+		"version":  spaceship.Version,
 	}
 }
 
@@ -32,13 +40,122 @@ func formatUpdateResponse(res UpdateResponseModel) map[string]interface{} {
 	}
 }
 
+func formatReplaceResponse(res ReplaceResponseModel) map[string]interface{} {
+	return map[string]interface{}{
+		"success": res.Success,
+	}
+}
+
 func formatDeleteByIDResponse(res DeleteByIDResponseModel) map[string]interface{} {
 	return map[string]interface{}{
 		"success": res.Success,
 	}
 }
 
-func formatGetAllResponse(res GetAllResponseModel) map[string]interface{} {
+func formatRestoreResponse(res RestoreResponseModel) map[string]interface{} {
+	return map[string]interface{}{
+		"success": res.Success,
+	}
+}
+
+func formatBulkResponse(res BulkResponseModel) bulkResponse {
+	results := []bulkResultResponse{}
+	results = appendBulkResults(results, "create", http.StatusCreated, res.Result.Create)
+	results = appendBulkResults(results, "update", http.StatusOK, res.Result.Update)
+	results = appendBulkResults(results, "delete", http.StatusOK, res.Result.Delete)
+
+	return bulkResponse{
+		Mode:      res.Mode,
+		Committed: res.Result.Committed,
+		Results:   results,
+	}
+}
+
+func appendBulkResults(results []bulkResultResponse, op string, successStatus int, items []entity.BulkResult) []bulkResultResponse {
+	for i, item := range items {
+		result := bulkResultResponse{
+			Op:     op,
+			Index:  i,
+			ID:     int64(item.ID),
+			Status: successStatus,
+		}
+
+		if item.Err != nil {
+			problem := helpers.NewProblem(item.Err)
+			result.Status = problem.Status
+			result.Error = &problem
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func formatExportRecord(spaceship entity.SpaceShip) exportRecord {
+	armaments := make([]armamentReq, len(spaceship.Armaments))
+	for i, armament := range spaceship.Armaments {
+		armaments[i] = armamentReq{
+			WeaponID: armament.WeaponID,
+			Qty:      armament.Qty,
+		}
+	}
+
+	return exportRecord{
+		ID: int64(spaceship.ID),
+		createRequest: createRequest{
+			Name:      spaceship.Name,
+			Class:     spaceship.Class,
+			Crew:      spaceship.Crew,
+			Image:     spaceship.Image,
+			Value:     spaceship.Value,
+			Status:    spaceship.Status,
+			Armaments: armaments,
+		},
+	}
+}
+
+// formatCSVRecord lays a spaceship out in the order of csvColumns.
+func formatCSVRecord(spaceship entity.SpaceShip) []string {
+	armaments := make([]string, len(spaceship.Armaments))
+	for i, armament := range spaceship.Armaments {
+		armaments[i] = fmt.Sprintf("%d:%d", armament.WeaponID, armament.Qty)
+	}
+
+	return []string{
+		strconv.FormatUint(uint64(spaceship.ID), 10),
+		spaceship.Name,
+		spaceship.Class,
+		strconv.FormatInt(spaceship.Crew, 10),
+		spaceship.Image,
+		strconv.FormatFloat(spaceship.Value, 'f', -1, 64),
+		spaceship.Status,
+		strings.Join(armaments, ";"),
+	}
+}
+
+func formatImportResponse(res ImportResponseModel) importResponse {
+	formatted := importResponse{
+		Errors: []importErrorResponse{},
+	}
+
+	for _, line := range res.Lines {
+		if line.Err == nil {
+			formatted.Created++
+			continue
+		}
+
+		formatted.Failed++
+		formatted.Errors = append(formatted.Errors, importErrorResponse{
+			Line:  line.Line,
+			Error: helpers.NewProblem(line.Err),
+		})
+	}
+
+	return formatted
+}
+
+func formatGetAllResponse(res GetAllResponseModel) getAllResponse {
 	spaceships := make([]spaceShipResponse, len(res.SpaceShip))
 	for i, spaceship := range res.SpaceShip {
 		spaceships[i] = spaceShipResponse{
@@ -46,9 +163,52 @@ func formatGetAllResponse(res GetAllResponseModel) map[string]interface{} {
 			Name:   spaceship.Name,
 			Status: spaceship.Status,
 		}
+		if spaceship.DeletedAt.Valid {
+			deletedAt := spaceship.DeletedAt.Time
+			spaceships[i].DeletedAt = &deletedAt
+		}
 	}
 
+	return getAllResponse{
+		Data:       spaceships,
+		NextCursor: res.NextCursor,
+		HasMore:    res.HasMore,
+		Total:      res.Total,
+	}
+}
+
+func formatArmament(armament entity.Armament) armamentResponse {
+	return armamentResponse{
+		ID:       int64(armament.ID),
+		WeaponID: int64(armament.WeaponID),
+		Weapon:   armament.Weapon.Name,
+		Qty:      armament.Qty,
+	}
+}
+
+func formatArmaments(armaments []entity.Armament) []armamentResponse {
+	formatted := make([]armamentResponse, len(armaments))
+	for i, armament := range armaments {
+		formatted[i] = formatArmament(armament)
+	}
+
+	return formatted
+}
+
+func formatGetArmamentsResponse(res GetArmamentsResponseModel) []armamentResponse {
+	return formatArmaments(res.Armaments)
+}
+
+func formatCreateArmamentResponse(res CreateArmamentResponseModel) armamentResponse {
+	return formatArmament(res.Armament)
+}
+
+func formatUpdateArmamentResponse(res UpdateArmamentResponseModel) armamentResponse {
+	return formatArmament(res.Armament)
+}
+
+func formatDeleteArmamentResponse(res DeleteArmamentResponseModel) map[string]interface{} {
 	return map[string]interface{}{
-		"data": spaceships,
+		"success": res.Success,
 	}
 }
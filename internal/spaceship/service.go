@@ -2,41 +2,193 @@ package spaceship
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 
 	"github.com/wndisra/galactic-svc/internal/entity"
 	"github.com/wndisra/galactic-svc/internal/helpers"
+	"github.com/wndisra/galactic-svc/internal/logging"
 )
 
+var errSpaceShipNotFound = helpers.NewNotFoundError("spaceship not found")
+var errVersionMismatch = helpers.NewPreconditionFailedError("spaceship has been modified since it was fetched")
+var errSpaceShipNotDeleted = helpers.NewConflictError("spaceship is not deleted")
+var errArmamentNotFound = helpers.NewNotFoundError("armament not found")
+var errBulkRolledBack = helpers.NewConflictError("rolled back as another item of the batch failed")
+var errBulkInsertFailed = helpers.NewConflictError("failed to create the spaceships of the batch")
+
+// errBulkFailed rolls back an atomic bulk request, its items carry the errors.
+var errBulkFailed = errors.New("bulk request failed")
+
+// errUnknownWeapon is reported on the weapon_id field of an armament.
+const errUnknownWeapon = "must refer to a weapon of the catalog"
+
 type SpaceShipRepository interface {
-	Insert(ctx context.Context, req entity.SpaceShip) error
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	Insert(ctx context.Context, req entity.SpaceShip) (entity.SpaceShip, error)
+	InsertBatch(ctx context.Context, req []entity.SpaceShip) ([]entity.SpaceShip, error)
 	GetByID(ctx context.Context, id int64) (entity.SpaceShip, error)
+	GetByIDWithDeleted(ctx context.Context, id int64) (entity.SpaceShip, error)
 	Update(ctx context.Context, id int64, req entity.SpaceShip) error
-	Delete(ctx context.Context, id int64) error
-	GetAll(ctx context.Context, req entity.SpaceShip) ([]entity.SpaceShip, error)
-	DeleteArmaments(ctx context.Context, spaceshipID int64) error
+	Delete(ctx context.Context, id int64, version uint) error
+	Restore(ctx context.Context, id int64, deletedAt time.Time) error
+	Purge(ctx context.Context, id int64, version uint) error
+	GetAll(ctx context.Context, filter entity.SpaceShipFilter, opts entity.ListOptions) (entity.SpaceShipPage, error)
+	Export(ctx context.Context, fn func(spaceship entity.SpaceShip) error) error
+	BumpVersion(ctx context.Context, id int64) error
+}
+
+type ArmamentRepository interface {
+	GetAll(ctx context.Context, spaceshipID int64) ([]entity.Armament, error)
+	GetByID(ctx context.Context, spaceshipID int64, id int64) (entity.Armament, error)
+	Insert(ctx context.Context, req entity.Armament) (entity.Armament, error)
+	Update(ctx context.Context, req entity.Armament) error
+	Delete(ctx context.Context, spaceshipID int64, id int64) error
+}
+
+// WeaponRepository looks up the weapon catalog that armaments refer to.
+type WeaponRepository interface {
+	GetByIDs(ctx context.Context, ids []int64) ([]entity.Weapon, error)
 }
 
 type service struct {
-	repo   SpaceShipRepository
-	logger log.Logger
+	repo         SpaceShipRepository
+	armamentRepo ArmamentRepository
+	weaponRepo   WeaponRepository
+	logger       log.Logger
 }
 
-func NewService(repo SpaceShipRepository, logger log.Logger) *service {
+func NewService(repo SpaceShipRepository, armamentRepo ArmamentRepository, weaponRepo WeaponRepository, logger log.Logger) *service {
 	return &service{
-		repo:   repo,
-		logger: logger,
+		repo:         repo,
+		armamentRepo: armamentRepo,
+		weaponRepo:   weaponRepo,
+		logger:       logger,
 	}
 }
 
-func (s *service) Create(ctx context.Context, req entity.SpaceShip) error {
-	err := s.repo.Insert(ctx, req)
+// log returns the logger of the request ctx belongs to.
+func (s *service) log(ctx context.Context) log.Logger {
+	return logging.FromContext(ctx, s.logger)
+}
+
+// getWeapons fetches the weapons the armaments refer to, by ID. It also
+// returns the index of every armament whose weapon is not in the catalog.
+func (s *service) getWeapons(ctx context.Context, armaments []entity.Armament) (map[uint]entity.Weapon, []int, error) {
+	if len(armaments) == 0 {
+		return nil, nil, nil
+	}
+
+	ids := make([]int64, len(armaments))
+	for i, armament := range armaments {
+		ids[i] = int64(armament.WeaponID)
+	}
+
+	found, err := s.weaponRepo.GetByIDs(ctx, ids)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	return nil
+	weapons := make(map[uint]entity.Weapon, len(found))
+	for _, weapon := range found {
+		weapons[weapon.ID] = weapon
+	}
+
+	var unknown []int
+	for i, armament := range armaments {
+		if _, ok := weapons[armament.WeaponID]; !ok {
+			unknown = append(unknown, i)
+		}
+	}
+
+	return weapons, unknown, nil
+}
+
+// checkWeapons fails with a validation error on every armament of a spaceship
+// whose weapon is not in the catalog. Otherwise, it returns the weapons by ID.
+func (s *service) checkWeapons(ctx context.Context, armaments []entity.Armament) (map[uint]entity.Weapon, error) {
+	weapons, errs, err := s.checkBulkWeapons(ctx, []entity.SpaceShip{{Armaments: armaments}})
+	if err != nil {
+		return nil, err
+	}
+
+	if errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	return weapons, nil
+}
+
+// checkBulkWeapons is checkWeapons for many spaceships at once, with a single
+// lookup of the catalog. It returns the weapons found, and the error of each
+// spaceship, nil when all its weapons are known.
+func (s *service) checkBulkWeapons(ctx context.Context, spaceships []entity.SpaceShip) (map[uint]entity.Weapon, []error, error) {
+	var armaments []entity.Armament
+	var owners []int
+	offsets := make([]int, len(spaceships))
+	for i, spaceship := range spaceships {
+		offsets[i] = len(armaments)
+		armaments = append(armaments, spaceship.Armaments...)
+		for range spaceship.Armaments {
+			owners = append(owners, i)
+		}
+	}
+
+	weapons, unknown, err := s.getWeapons(ctx, armaments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fields := make([][]helpers.FieldError, len(spaceships))
+	for _, index := range unknown {
+		owner := owners[index]
+		fields[owner] = append(fields[owner], helpers.FieldError{
+			Field:   fmt.Sprintf("armament[%d].weapon_id", index-offsets[owner]),
+			Message: errUnknownWeapon,
+		})
+	}
+
+	errs := make([]error, len(spaceships))
+	for i := range fields {
+		if len(fields[i]) > 0 {
+			errs[i] = helpers.NewValidationError("unknown weapon", fields[i]...)
+		}
+	}
+
+	return weapons, errs, nil
+}
+
+// Create adds a spaceship and returns it as persisted, its armaments along
+// with their weapon.
+func (s *service) Create(ctx context.Context, req entity.SpaceShip) (entity.SpaceShip, error) {
+	var spaceship entity.SpaceShip
+
+	err := s.repo.WithTx(ctx, func(ctx context.Context) error {
+		weapons, err := s.checkWeapons(ctx, req.Armaments)
+		if err != nil {
+			return err
+		}
+
+		spaceship, err = s.repo.Insert(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		for i, armament := range spaceship.Armaments {
+			spaceship.Armaments[i].Weapon = weapons[armament.WeaponID]
+		}
+
+		return nil
+	})
+	if err != nil {
+		return entity.SpaceShip{}, err
+	}
+
+	return spaceship, nil
 }
 
 func (s *service) GetByID(ctx context.Context, id int64) (entity.SpaceShip, error) {
@@ -46,53 +198,546 @@ func (s *service) GetByID(ctx context.Context, id int64) (entity.SpaceShip, erro
 	}
 
 	if spaceship.ID == 0 {
-		return entity.SpaceShip{}, helpers.ErrBadRequest
+		return entity.SpaceShip{}, errSpaceShipNotFound
 	}
 
 	return spaceship, nil
 }
 
-func (s *service) Update(ctx context.Context, id int64, req entity.SpaceShip) error {
-	spaceship, err := s.repo.GetByID(ctx, id)
+// checkVersion fails when the client expects a version of the spaceship other
+// than the stored one. As for any If-Match header, a spaceship which does not
+// exist fails every precondition.
+func checkVersion(spaceship entity.SpaceShip, match entity.VersionMatch) error {
+	if match.IsZero() {
+		return nil
+	}
+
+	if spaceship.ID == 0 || !match.Matches(spaceship.Version) {
+		return errVersionMismatch
+	}
+
+	return nil
+}
+
+// expectedVersion is the version a change must still find the spaceship at
+// when writing, so that match keeps holding, or zero when there is no match.
+func expectedVersion(spaceship entity.SpaceShip, match entity.VersionMatch) uint {
+	if match.IsZero() {
+		return 0
+	}
+
+	return spaceship.Version
+}
+
+// Update applies patch to a spaceship and returns the version it moved on to.
+func (s *service) Update(ctx context.Context, id int64, match entity.VersionMatch, patch entity.SpaceShipPatch) (uint, error) {
+	var version uint
+
+	err := s.repo.WithTx(ctx, func(ctx context.Context) error {
+		spaceship, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = checkVersion(spaceship, match)
+		if err != nil {
+			return err
+		}
+
+		if spaceship.ID == 0 {
+			return errSpaceShipNotFound
+		}
+
+		// Armaments are only rewritten when the patch carries them.
+		current := spaceship.Armaments
+		if patch.Armaments != nil {
+			_, err = s.checkWeapons(ctx, *patch.Armaments)
+			if err != nil {
+				return err
+			}
+		}
+
+		updated := patch.Apply(spaceship)
+		updated.Armaments = nil
+
+		err = s.repo.Update(ctx, id, updated)
+		if err != nil {
+			return err
+		}
+
+		version = spaceship.Version + 1
+		if patch.Armaments == nil {
+			return nil
+		}
+
+		return s.syncArmaments(ctx, id, current, *patch.Armaments)
+	})
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	if spaceship.ID == 0 {
-		return helpers.ErrBadRequest
+	return version, nil
+}
+
+// Replace overwrites a spaceship, armaments included, and returns the version
+// it moved on to.
+func (s *service) Replace(ctx context.Context, id int64, match entity.VersionMatch, req entity.SpaceShip) (uint, error) {
+	var version uint
+
+	err := s.repo.WithTx(ctx, func(ctx context.Context) error {
+		spaceship, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = checkVersion(spaceship, match)
+		if err != nil {
+			return err
+		}
+
+		if spaceship.ID == 0 {
+			return errSpaceShipNotFound
+		}
+
+		_, err = s.checkWeapons(ctx, req.Armaments)
+		if err != nil {
+			return err
+		}
+
+		replacement := req
+		replacement.Armaments = nil
+		replacement.Version = spaceship.Version
+
+		err = s.repo.Update(ctx, id, replacement)
+		if err != nil {
+			return err
+		}
+
+		version = spaceship.Version + 1
+		return s.syncArmaments(ctx, id, spaceship.Armaments, req.Armaments)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// syncArmaments turns the current armaments of a spaceship into the wanted
+// ones, pairing them by weapon: paired armaments keep their ID and only get
+// their quantity updated, the others are inserted or deleted.
+func (s *service) syncArmaments(ctx context.Context, spaceshipID int64, current []entity.Armament, wanted []entity.Armament) error {
+	byWeapon := map[uint][]entity.Armament{}
+	for _, armament := range current {
+		byWeapon[armament.WeaponID] = append(byWeapon[armament.WeaponID], armament)
+	}
+
+	kept := map[uint]bool{}
+	for _, armament := range wanted {
+		candidates := byWeapon[armament.WeaponID]
+		if len(candidates) == 0 {
+			_, err := s.armamentRepo.Insert(ctx, entity.Armament{
+				SpaceShipID: uint(spaceshipID),
+				WeaponID:    armament.WeaponID,
+				Qty:         armament.Qty,
+			})
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		existing := candidates[0]
+		byWeapon[armament.WeaponID] = candidates[1:]
+		kept[existing.ID] = true
+
+		if existing.Qty == armament.Qty {
+			continue
+		}
+
+		existing.Qty = armament.Qty
+		err := s.armamentRepo.Update(ctx, existing)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, armament := range current {
+		if kept[armament.ID] {
+			continue
+		}
+
+		err := s.armamentRepo.Delete(ctx, spaceshipID, int64(armament.ID))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *service) Delete(ctx context.Context, id int64, match entity.VersionMatch) error {
+	return s.repo.WithTx(ctx, func(ctx context.Context) error {
+		spaceship, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = checkVersion(spaceship, match)
+		if err != nil {
+			return err
+		}
+
+		if spaceship.ID == 0 {
+			return errSpaceShipNotFound
+		}
+
+		return s.repo.Delete(ctx, id, expectedVersion(spaceship, match))
+	})
+}
+
+func (s *service) Restore(ctx context.Context, id int64) error {
+	return s.repo.WithTx(ctx, func(ctx context.Context) error {
+		spaceship, err := s.repo.GetByIDWithDeleted(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if spaceship.ID == 0 {
+			return errSpaceShipNotFound
+		}
+
+		if !spaceship.DeletedAt.Valid {
+			return errSpaceShipNotDeleted
+		}
+
+		return s.repo.Restore(ctx, id, spaceship.DeletedAt.Time)
+	})
+}
+
+// Purge permanently removes a spaceship, even a soft deleted one.
+func (s *service) Purge(ctx context.Context, id int64, match entity.VersionMatch) error {
+	return s.repo.WithTx(ctx, func(ctx context.Context) error {
+		spaceship, err := s.repo.GetByIDWithDeleted(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = checkVersion(spaceship, match)
+		if err != nil {
+			return err
+		}
+
+		if spaceship.ID == 0 {
+			return errSpaceShipNotFound
+		}
+
+		return s.repo.Purge(ctx, id, expectedVersion(spaceship, match))
+	})
+}
+
+// Bulk creates, patches and deletes spaceships at once. In atomic mode every
+// item is applied in a single transaction, rolled back when any of them fails.
+// In best effort mode each item is applied on its own.
+func (s *service) Bulk(ctx context.Context, req entity.SpaceShipBulk) (entity.SpaceShipBulkResult, error) {
+	result := entity.SpaceShipBulkResult{
+		Create: make([]entity.BulkResult, len(req.Create)),
+		Update: make([]entity.BulkResult, len(req.Update)),
+		Delete: make([]entity.BulkResult, len(req.Delete)),
+	}
+
+	for i, update := range req.Update {
+		result.Update[i].ID = uint(update.ID)
+	}
+
+	for i, id := range req.Delete {
+		result.Delete[i].ID = uint(id)
+	}
+
+	if req.BestEffort {
+		err := s.applyBulk(ctx, req, &result)
+		if err != nil {
+			return entity.SpaceShipBulkResult{}, err
+		}
+
+		// Only a batch whose every item failed wrote nothing; an empty one
+		// fully succeeded.
+		total := len(req.Create) + len(req.Update) + len(req.Delete)
+		failures := countBulkFailures(result)
+		result.Committed = failures == 0 || failures < total
+		return result, nil
+	}
+
+	err := s.repo.WithTx(ctx, func(ctx context.Context) error {
+		err := s.applyBulk(ctx, req, &result)
+		if err != nil {
+			return err
+		}
+
+		if countBulkFailures(result) > 0 {
+			return errBulkFailed
+		}
+
+		return nil
+	})
+	if errors.Is(err, errBulkFailed) {
+		rollBackBulk(&result)
+		return result, nil
 	}
 
-	err = s.repo.DeleteArmaments(ctx, id)
 	if err != nil {
-		return err
+		return entity.SpaceShipBulkResult{}, err
 	}
 
-	err = s.repo.Update(ctx, id, req)
+	result.Committed = true
+	return result, nil
+}
+
+// applyBulk applies every item of req, recording the outcome of each one in
+// result. Patches and deletions run in a transaction of their own, which is a
+// savepoint in atomic mode. The returned error is one that no single item can
+// be blamed for.
+func (s *service) applyBulk(ctx context.Context, req entity.SpaceShipBulk, result *entity.SpaceShipBulkResult) error {
+	_, errs, err := s.checkBulkWeapons(ctx, req.Create)
 	if err != nil {
 		return err
 	}
 
+	var valid []entity.SpaceShip
+	var indexes []int
+	for i, err := range errs {
+		if err != nil {
+			result.Create[i].Err = err
+			continue
+		}
+
+		valid = append(valid, req.Create[i])
+		indexes = append(indexes, i)
+	}
+
+	if len(valid) > 0 {
+		created, err := s.repo.InsertBatch(ctx, valid)
+		if err != nil && !req.BestEffort {
+			// The batch is rolled back as a whole, so the other items are
+			// not even attempted.
+			level.Error(s.log(ctx)).Log("msg", "spaceship.Bulk(): batch insert failed, rolling back", "count", len(valid), "err", err)
+			for _, i := range indexes {
+				result.Create[i].Err = errBulkInsertFailed
+			}
+
+			return nil
+		}
+
+		if err != nil {
+			level.Warn(s.log(ctx)).Log("msg", "spaceship.Bulk(): batch insert failed, inserting one by one", "count", len(valid), "err", err)
+			created = s.insertOneByOne(ctx, valid, indexes, result)
+		}
+
+		for i, spaceship := range created {
+			if spaceship.ID != 0 {
+				result.Create[indexes[i]].ID = spaceship.ID
+			}
+		}
+	}
+
+	for i, update := range req.Update {
+		_, result.Update[i].Err = s.Update(ctx, update.ID, update.Version, update.Patch)
+	}
+
+	for i, id := range req.Delete {
+		result.Delete[i].Err = s.Delete(ctx, id, entity.VersionMatch{})
+	}
+
 	return nil
 }
 
-func (s *service) Delete(ctx context.Context, id int64) error {
+// insertOneByOne inserts spaceships separately once their batch has failed, so
+// that the failure is blamed on the right ones. Spaceships left with a zero ID
+// have failed.
+func (s *service) insertOneByOne(ctx context.Context, spaceships []entity.SpaceShip, indexes []int, result *entity.SpaceShipBulkResult) []entity.SpaceShip {
+	created := make([]entity.SpaceShip, len(spaceships))
+	for i, spaceship := range spaceships {
+		inserted, err := s.repo.InsertBatch(ctx, []entity.SpaceShip{spaceship})
+		if err != nil {
+			result.Create[indexes[i]].Err = err
+			continue
+		}
+
+		created[i] = inserted[0]
+	}
+
+	return created
+}
+
+func countBulkFailures(result entity.SpaceShipBulkResult) int {
+	var failures int
+	for _, results := range [][]entity.BulkResult{result.Create, result.Update, result.Delete} {
+		for _, item := range results {
+			if item.Err != nil {
+				failures++
+			}
+		}
+	}
+
+	return failures
+}
+
+// rollBackBulk flags the items which succeeded before the transaction was
+// rolled back. Spaceships created in the meantime no longer exist.
+func rollBackBulk(result *entity.SpaceShipBulkResult) {
+	for i := range result.Create {
+		result.Create[i].ID = 0
+	}
+
+	for _, results := range [][]entity.BulkResult{result.Create, result.Update, result.Delete} {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Err = errBulkRolledBack
+			}
+		}
+	}
+}
+
+func (s *service) GetAll(ctx context.Context, filter entity.SpaceShipFilter, opts entity.ListOptions) (entity.SpaceShipPage, error) {
+	return s.repo.GetAll(ctx, filter, opts)
+}
+
+// Export hands every spaceship, armaments included, over to fn one at a time.
+func (s *service) Export(ctx context.Context, fn func(spaceship entity.SpaceShip) error) error {
+	return s.repo.Export(ctx, fn)
+}
+
+// checkSpaceShip fails when the spaceship does not exist, or is deleted.
+func (s *service) checkSpaceShip(ctx context.Context, id int64) error {
 	spaceship, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	if spaceship.ID == 0 {
-		return helpers.ErrBadRequest
+		return errSpaceShipNotFound
 	}
 
-	err = s.repo.Delete(ctx, id)
+	return nil
+}
+
+// getWeapon fetches the weapon of a single armament, failing with a
+// validation error when it is not in the catalog.
+func (s *service) getWeapon(ctx context.Context, id uint) (entity.Weapon, error) {
+	weapons, unknown, err := s.getWeapons(ctx, []entity.Armament{{WeaponID: id}})
 	if err != nil {
-		return err
+		return entity.Weapon{}, err
 	}
 
-	return nil
+	if len(unknown) > 0 {
+		return entity.Weapon{}, helpers.NewValidationError("unknown weapon", helpers.FieldError{Field: "weapon_id", Message: errUnknownWeapon})
+	}
+
+	return weapons[id], nil
+}
+
+func (s *service) GetArmaments(ctx context.Context, spaceshipID int64) ([]entity.Armament, error) {
+	err := s.checkSpaceShip(ctx, spaceshipID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.armamentRepo.GetAll(ctx, spaceshipID)
 }
 
-func (s *service) GetAll(ctx context.Context, req entity.SpaceShip) ([]entity.SpaceShip, error) {
-	return s.repo.GetAll(ctx, req)
+func (s *service) CreateArmament(ctx context.Context, spaceshipID int64, req entity.Armament) (entity.Armament, error) {
+	var armament entity.Armament
+
+	err := s.repo.WithTx(ctx, func(ctx context.Context) error {
+		err := s.checkSpaceShip(ctx, spaceshipID)
+		if err != nil {
+			return err
+		}
+
+		weapon, err := s.getWeapon(ctx, req.WeaponID)
+		if err != nil {
+			return err
+		}
+
+		req.SpaceShipID = uint(spaceshipID)
+		armament, err = s.armamentRepo.Insert(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		armament.Weapon = weapon
+		return s.repo.BumpVersion(ctx, spaceshipID)
+	})
+	if err != nil {
+		return entity.Armament{}, err
+	}
+
+	return armament, nil
+}
+
+// UpdateArmament changes an armament in place, so that its ID is kept.
+func (s *service) UpdateArmament(ctx context.Context, spaceshipID int64, id int64, patch entity.ArmamentPatch) (entity.Armament, error) {
+	var armament entity.Armament
+
+	err := s.repo.WithTx(ctx, func(ctx context.Context) error {
+		err := s.checkSpaceShip(ctx, spaceshipID)
+		if err != nil {
+			return err
+		}
+
+		armament, err = s.armamentRepo.GetByID(ctx, spaceshipID, id)
+		if err != nil {
+			return err
+		}
+
+		if armament.ID == 0 {
+			return errArmamentNotFound
+		}
+
+		armament = patch.Apply(armament)
+		if armament.Weapon.ID == 0 {
+			armament.Weapon, err = s.getWeapon(ctx, armament.WeaponID)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = s.armamentRepo.Update(ctx, armament)
+		if err != nil {
+			return err
+		}
+
+		return s.repo.BumpVersion(ctx, spaceshipID)
+	})
+	if err != nil {
+		return entity.Armament{}, err
+	}
+
+	return armament, nil
+}
+
+func (s *service) DeleteArmament(ctx context.Context, spaceshipID int64, id int64) error {
+	return s.repo.WithTx(ctx, func(ctx context.Context) error {
+		err := s.checkSpaceShip(ctx, spaceshipID)
+		if err != nil {
+			return err
+		}
+
+		armament, err := s.armamentRepo.GetByID(ctx, spaceshipID, id)
+		if err != nil {
+			return err
+		}
+
+		if armament.ID == 0 {
+			return errArmamentNotFound
+		}
+
+		err = s.armamentRepo.Delete(ctx, spaceshipID, id)
+		if err != nil {
+			return err
+		}
+
+		return s.repo.BumpVersion(ctx, spaceshipID)
+	})
 }
@@ -3,10 +3,12 @@ package spaceship
 import (
 	"context"
 	"testing"
+	"time"
 
 	kitlog "github.com/go-kit/log"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
 
 	"github.com/wndisra/galactic-svc/internal/entity"
 	"github.com/wndisra/galactic-svc/internal/helpers"
@@ -17,39 +19,83 @@ func setupMockLogger() kitlog.Logger {
 	return kitlog.NewNopLogger()
 }
 
+// runTx lets a mocked WithTx() call run the given function in place.
+func runTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func TestNewService(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	mockLogger := setupMockLogger()
 	mockRepo := mock_repo.NewMockSpaceShipRepository(ctrl)
+	mockArmamentRepo := mock_repo.NewMockArmamentRepository(ctrl)
+	mockWeaponRepo := mock_repo.NewMockWeaponRepository(ctrl)
 
 	expected := &service{
-		repo:   mockRepo,
-		logger: mockLogger,
+		repo:         mockRepo,
+		armamentRepo: mockArmamentRepo,
+		weaponRepo:   mockWeaponRepo,
+		logger:       mockLogger,
 	}
 
-	got := NewService(mockRepo, mockLogger)
+	got := NewService(mockRepo, mockArmamentRepo, mockWeaponRepo, mockLogger)
 	assert.NotNil(t, got)
 	assert.Equal(t, expected, got)
 }
 
 func TestService_Create(t *testing.T) {
+	weapon := entity.Weapon{Model: gorm.Model{ID: 1}, Name: "Turbo Laser"}
+	created := entity.SpaceShip{
+		Name:   "Devastator",
+		Class:  "Star Destroyer",
+		Crew:   1200,
+		Image:  "https://test",
+		Value:  100.99,
+		Status: "Operational",
+		Armaments: []entity.Armament{
+			{
+				Model:       gorm.Model{ID: 4},
+				SpaceShipID: 2,
+				WeaponID:    1,
+				Qty:         60,
+			},
+		},
+		Version: 1,
+	}
+	created.ID = 2
+
 	tests := []struct {
 		name    string
 		req     entity.SpaceShip
-		mocks   func(repo *mock_repo.MockSpaceShipRepository)
+		mocks   func(repo *mock_repo.MockSpaceShipRepository, weaponRepo *mock_repo.MockWeaponRepository)
+		wants   entity.SpaceShip
 		wantErr error
 	}{
 		{
 			name: "Got repo error, should return non-nil error",
 			req:  entity.SpaceShip{},
-			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
-				repo.EXPECT().Insert(context.Background(), entity.SpaceShip{}).Return(assert.AnError)
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().Insert(context.Background(), entity.SpaceShip{}).Return(entity.SpaceShip{}, assert.AnError)
 			},
+			wants:   entity.SpaceShip{},
 			wantErr: assert.AnError,
 		},
 		{
-			name: "Got repo success, should return nil error",
+			name: "Given unknown weapon, should return validation error",
+			req: entity.SpaceShip{
+				Name:      "Devastator",
+				Armaments: []entity.Armament{{WeaponID: 1, Qty: 60}, {WeaponID: 9, Qty: 2}},
+			},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{1, 9}).Return([]entity.Weapon{{Model: gorm.Model{ID: 1}}}, nil)
+			},
+			wantErr: helpers.NewValidationError("unknown weapon", helpers.FieldError{Field: "armament[1].weapon_id", Message: errUnknownWeapon}),
+		},
+		{
+			name: "Got repo success, should return the spaceship with its weapons and nil error",
 			req: entity.SpaceShip{
 				Name:   "Devastator",
 				Class:  "Star Destroyer",
@@ -59,12 +105,14 @@ func TestService_Create(t *testing.T) {
 				Status: "Operational",
 				Armaments: []entity.Armament{
 					{
-						Title: "Turbo Laser",
-						Qty:   60,
+						WeaponID: 1,
+						Qty:      60,
 					},
 				},
 			},
-			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{1}).Return([]entity.Weapon{weapon}, nil)
 				repo.EXPECT().Insert(context.Background(), entity.SpaceShip{
 					Name:   "Devastator",
 					Class:  "Star Destroyer",
@@ -74,12 +122,18 @@ func TestService_Create(t *testing.T) {
 					Status: "Operational",
 					Armaments: []entity.Armament{
 						{
-							Title: "Turbo Laser",
-							Qty:   60,
+							WeaponID: 1,
+							Qty:      60,
 						},
 					},
-				}).Return(nil)
+				}).Return(created, nil)
 			},
+			wants: func() entity.SpaceShip {
+				spaceship := created
+				spaceship.Armaments = []entity.Armament{created.Armaments[0]}
+				spaceship.Armaments[0].Weapon = weapon
+				return spaceship
+			}(),
 			wantErr: nil,
 		},
 	}
@@ -89,16 +143,19 @@ func TestService_Create(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			mockRepo := mock_repo.NewMockSpaceShipRepository(ctrl)
+			mockWeaponRepo := mock_repo.NewMockWeaponRepository(ctrl)
 			mockLogger := setupMockLogger()
 
 			s := &service{
-				repo:   mockRepo,
-				logger: mockLogger,
+				repo:       mockRepo,
+				weaponRepo: mockWeaponRepo,
+				logger:     mockLogger,
 			}
 
-			tt.mocks(mockRepo)
+			tt.mocks(mockRepo, mockWeaponRepo)
 
-			err := s.Create(context.Background(), tt.req)
+			got, err := s.Create(context.Background(), tt.req)
+			assert.Equal(t, tt.wants, got)
 			assert.Equal(t, tt.wantErr, err)
 		})
 	}
@@ -114,8 +171,8 @@ func TestService_GetByID(t *testing.T) {
 		Status: "Operational",
 		Armaments: []entity.Armament{
 			{
-				Title: "Turbo Laser",
-				Qty:   60,
+				WeaponID: 1,
+				Qty:      60,
 			},
 		},
 	}
@@ -142,7 +199,7 @@ func TestService_GetByID(t *testing.T) {
 			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
 				repo.EXPECT().GetByID(context.Background(), int64(3)).Return(entity.SpaceShip{}, nil)
 			},
-			wantErr: helpers.ErrBadRequest,
+			wantErr: errSpaceShipNotFound,
 		},
 		{
 			name: "Got repo success, should return non-empty struct and nil error",
@@ -186,24 +243,48 @@ func TestService_Update(t *testing.T) {
 		Status: "Operational",
 		Armaments: []entity.Armament{
 			{
-				Title: "Turbo Laser",
-				Qty:   60,
+				Model:    gorm.Model{ID: 10},
+				WeaponID: 1,
+				Qty:      60,
 			},
 		},
 	}
 	spaceship.ID = 2
+	spaceship.Version = 4
+
+	// Armaments are not rewritten unless patched.
+	updated := spaceship
+	updated.Armaments = nil
+
+	name := "Executor"
+	crew := int64(0)
+	armaments := []entity.Armament{
+		{
+			WeaponID: 2,
+			Qty:      10,
+		},
+	}
+	rearmed := []entity.Armament{
+		{
+			WeaponID: 1,
+			Qty:      80,
+		},
+	}
 
 	tests := []struct {
-		name    string
-		id      int64
-		req     entity.SpaceShip
-		mocks   func(repo *mock_repo.MockSpaceShipRepository)
-		wantErr error
+		name        string
+		id          int64
+		match       entity.VersionMatch
+		patch       entity.SpaceShipPatch
+		mocks       func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository)
+		wantVersion uint
+		wantErr     error
 	}{
 		{
 			name: "Got GetByID() repo error, should return non-nil error",
 			id:   1,
-			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
 				repo.EXPECT().GetByID(context.Background(), int64(1)).Return(entity.SpaceShip{}, assert.AnError)
 			},
 			wantErr: assert.AnError,
@@ -211,67 +292,269 @@ func TestService_Update(t *testing.T) {
 		{
 			name: "Got GetByID() repo success but not found, should return non-nil error",
 			id:   3,
-			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
 				repo.EXPECT().GetByID(context.Background(), int64(3)).Return(entity.SpaceShip{}, nil)
 			},
-			wantErr: helpers.ErrBadRequest,
+			wantErr: errSpaceShipNotFound,
+		},
+		{
+			name:  "Given outdated version, should return precondition failed error",
+			id:    2,
+			match: entity.MatchVersion(3),
+			patch: entity.SpaceShipPatch{Name: &name},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+			},
+			wantErr: errVersionMismatch,
+		},
+		{
+			name:  "Given patch with unknown weapon, should return validation error before touching the armaments",
+			id:    2,
+			patch: entity.SpaceShipPatch{Armaments: &armaments},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{2}).Return(nil, nil)
+			},
+			wantErr: helpers.NewValidationError("unknown weapon", helpers.FieldError{Field: "armament[0].weapon_id", Message: errUnknownWeapon}),
+		},
+		{
+			name:  "Got Insert() armament repo error, should return non-nil error",
+			id:    2,
+			patch: entity.SpaceShipPatch{Armaments: &armaments},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{2}).Return([]entity.Weapon{{Model: gorm.Model{ID: 2}}}, nil)
+				repo.EXPECT().Update(context.Background(), int64(2), updated).Return(nil)
+				armamentRepo.EXPECT().Insert(context.Background(), entity.Armament{SpaceShipID: 2, WeaponID: 2, Qty: 10}).Return(entity.Armament{}, assert.AnError)
+			},
+			wantErr: assert.AnError,
 		},
 		{
-			name: "Got GetByID() repo success but DeleteArmaments() repo error, should return non-nil error",
+			name: "Got Update() repo error, should return non-nil error",
 			id:   2,
-			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				repo.EXPECT().Update(context.Background(), int64(2), updated).Return(assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name:  "Given patch without armaments, should only update the patched fields",
+			id:    2,
+			patch: entity.SpaceShipPatch{Name: &name, Crew: &crew},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+
+				want := updated
+				want.Name = "Executor"
+				want.Crew = 0
+				repo.EXPECT().Update(context.Background(), int64(2), want).Return(nil)
+			},
+			wantVersion: 5,
+			wantErr:     nil,
+		},
+		{
+			name:  "Given patch with other weapons and current version, should insert and delete the changed armaments",
+			id:    2,
+			match: entity.MatchVersion(4),
+			patch: entity.SpaceShipPatch{Armaments: &armaments},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{2}).Return([]entity.Weapon{{Model: gorm.Model{ID: 2}}}, nil)
+				repo.EXPECT().Update(context.Background(), int64(2), updated).Return(nil)
+				armamentRepo.EXPECT().Insert(context.Background(), entity.Armament{SpaceShipID: 2, WeaponID: 2, Qty: 10}).Return(entity.Armament{}, nil)
+				armamentRepo.EXPECT().Delete(context.Background(), int64(2), int64(10)).Return(nil)
+			},
+			wantVersion: 5,
+			wantErr:     nil,
+		},
+		{
+			name:  "Given patch with the same weapons, should update the quantities in place",
+			id:    2,
+			patch: entity.SpaceShipPatch{Armaments: &rearmed},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
 				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
-				repo.EXPECT().DeleteArmaments(context.Background(), int64(2)).Return(assert.AnError)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{1}).Return([]entity.Weapon{{Model: gorm.Model{ID: 1}}}, nil)
+				repo.EXPECT().Update(context.Background(), int64(2), updated).Return(nil)
+				armamentRepo.EXPECT().Update(context.Background(), entity.Armament{Model: gorm.Model{ID: 10}, WeaponID: 1, Qty: 80}).Return(nil)
+			},
+			wantVersion: 5,
+			wantErr:     nil,
+		},
+		{
+			name:  "Given patch with unchanged armaments, should not write them",
+			id:    2,
+			patch: entity.SpaceShipPatch{Armaments: &spaceship.Armaments},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{1}).Return([]entity.Weapon{{Model: gorm.Model{ID: 1}}}, nil)
+				repo.EXPECT().Update(context.Background(), int64(2), updated).Return(nil)
+			},
+			wantVersion: 5,
+			wantErr:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repo.NewMockSpaceShipRepository(ctrl)
+			mockArmamentRepo := mock_repo.NewMockArmamentRepository(ctrl)
+			mockWeaponRepo := mock_repo.NewMockWeaponRepository(ctrl)
+			mockLogger := setupMockLogger()
+
+			s := &service{
+				repo:         mockRepo,
+				armamentRepo: mockArmamentRepo,
+				weaponRepo:   mockWeaponRepo,
+				logger:       mockLogger,
+			}
+
+			tt.mocks(mockRepo, mockArmamentRepo, mockWeaponRepo)
+
+			version, err := s.Update(context.Background(), tt.id, tt.match, tt.patch)
+			assert.Equal(t, tt.wantVersion, version)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_Replace(t *testing.T) {
+	spaceship := entity.SpaceShip{
+		Name:   "Devastator",
+		Class:  "Star Destroyer",
+		Crew:   1200,
+		Image:  "https://test",
+		Value:  100.99,
+		Status: "Operational",
+		Armaments: []entity.Armament{
+			{
+				Model:    gorm.Model{ID: 10},
+				WeaponID: 1,
+				Qty:      60,
+			},
+		},
+	}
+	spaceship.ID = 2
+	spaceship.Version = 4
+
+	replacement := entity.SpaceShip{
+		Name:   "Executor",
+		Class:  "Dreadnought",
+		Crew:   0,
+		Value:  500,
+		Status: "Under Repair",
+		Armaments: []entity.Armament{
+			{
+				WeaponID: 2,
+				Qty:      10,
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		id          int64
+		match       entity.VersionMatch
+		req         entity.SpaceShip
+		mocks       func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository)
+		wantVersion uint
+		wantErr     error
+	}{
+		{
+			name: "Got GetByID() repo error, should return non-nil error",
+			id:   1,
+			req:  replacement,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(1)).Return(entity.SpaceShip{}, assert.AnError)
 			},
 			wantErr: assert.AnError,
 		},
+		{
+			name: "Got GetByID() repo success but not found, should return non-nil error",
+			id:   3,
+			req:  replacement,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(3)).Return(entity.SpaceShip{}, nil)
+			},
+			wantErr: errSpaceShipNotFound,
+		},
+		{
+			name:  "Given outdated version, should return precondition failed error",
+			id:    2,
+			match: entity.MatchVersion(3),
+			req:   replacement,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+			},
+			wantErr: errVersionMismatch,
+		},
 		{
 			name: "Got Update() repo error, should return non-nil error",
 			id:   2,
-			req:  entity.SpaceShip{},
-			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+			req:  replacement,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
 				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
-				repo.EXPECT().DeleteArmaments(context.Background(), int64(2)).Return(nil)
-				repo.EXPECT().Update(context.Background(), int64(2), entity.SpaceShip{}).Return(assert.AnError)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{2}).Return([]entity.Weapon{{Model: gorm.Model{ID: 2}}}, nil)
+
+				want := replacement
+				want.Armaments = nil
+				want.Version = 4
+				repo.EXPECT().Update(context.Background(), int64(2), want).Return(assert.AnError)
 			},
 			wantErr: assert.AnError,
 		},
 		{
-			name: "Got repo success, should return nil error",
+			name: "Got Delete() armament repo error, should return non-nil error",
 			id:   2,
-			req: entity.SpaceShip{
-				Name:   "Devastator",
-				Class:  "Star Destroyer",
-				Crew:   1200,
-				Image:  "https://test",
-				Value:  100.99,
-				Status: "Operational",
-				Armaments: []entity.Armament{
-					{
-						Title: "Turbo Laser",
-						Qty:   60,
-					},
-				},
+			req:  replacement,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{2}).Return([]entity.Weapon{{Model: gorm.Model{ID: 2}}}, nil)
+
+				want := replacement
+				want.Armaments = nil
+				want.Version = 4
+				repo.EXPECT().Update(context.Background(), int64(2), want).Return(nil)
+				armamentRepo.EXPECT().Insert(context.Background(), entity.Armament{SpaceShipID: 2, WeaponID: 2, Qty: 10}).Return(entity.Armament{}, nil)
+				armamentRepo.EXPECT().Delete(context.Background(), int64(2), int64(10)).Return(assert.AnError)
 			},
-			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+			wantErr: assert.AnError,
+		},
+		{
+			name:  "Got repo success, should replace the whole spaceship based on the stored version",
+			id:    2,
+			match: entity.MatchVersion(4),
+			req:   replacement,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
 				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
-				repo.EXPECT().DeleteArmaments(context.Background(), int64(2)).Return(nil)
-				repo.EXPECT().Update(context.Background(), int64(2), entity.SpaceShip{
-					Name:   "Devastator",
-					Class:  "Star Destroyer",
-					Crew:   1200,
-					Image:  "https://test",
-					Value:  100.99,
-					Status: "Operational",
-					Armaments: []entity.Armament{
-						{
-							Title: "Turbo Laser",
-							Qty:   60,
-						},
-					},
-				}).Return(nil)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{2}).Return([]entity.Weapon{{Model: gorm.Model{ID: 2}}}, nil)
+
+				want := replacement
+				want.Armaments = nil
+				want.Version = 4
+				repo.EXPECT().Update(context.Background(), int64(2), want).Return(nil)
+				armamentRepo.EXPECT().Insert(context.Background(), entity.Armament{SpaceShipID: 2, WeaponID: 2, Qty: 10}).Return(entity.Armament{}, nil)
+				armamentRepo.EXPECT().Delete(context.Background(), int64(2), int64(10)).Return(nil)
 			},
-			wantErr: nil,
+			wantVersion: 5,
+			wantErr:     nil,
 		},
 	}
 
@@ -280,16 +563,21 @@ func TestService_Update(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			mockRepo := mock_repo.NewMockSpaceShipRepository(ctrl)
+			mockArmamentRepo := mock_repo.NewMockArmamentRepository(ctrl)
+			mockWeaponRepo := mock_repo.NewMockWeaponRepository(ctrl)
 			mockLogger := setupMockLogger()
 
 			s := &service{
-				repo:   mockRepo,
-				logger: mockLogger,
+				repo:         mockRepo,
+				armamentRepo: mockArmamentRepo,
+				weaponRepo:   mockWeaponRepo,
+				logger:       mockLogger,
 			}
 
-			tt.mocks(mockRepo)
+			tt.mocks(mockRepo, mockArmamentRepo, mockWeaponRepo)
 
-			err := s.Update(context.Background(), tt.id, tt.req)
+			version, err := s.Replace(context.Background(), tt.id, tt.match, tt.req)
+			assert.Equal(t, tt.wantVersion, version)
 			assert.Equal(t, tt.wantErr, err)
 		})
 	}
@@ -305,16 +593,18 @@ func TestService_Delete(t *testing.T) {
 		Status: "Operational",
 		Armaments: []entity.Armament{
 			{
-				Title: "Turbo Laser",
-				Qty:   60,
+				WeaponID: 1,
+				Qty:      60,
 			},
 		},
 	}
 	spaceship.ID = 2
+	spaceship.Version = 4
 
 	tests := []struct {
 		name    string
 		id      int64
+		match   entity.VersionMatch
 		mocks   func(repo *mock_repo.MockSpaceShipRepository)
 		wantErr error
 	}{
@@ -322,6 +612,7 @@ func TestService_Delete(t *testing.T) {
 			name: "Got GetByID() repo error, should return non-nil error",
 			id:   1,
 			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
 				repo.EXPECT().GetByID(context.Background(), int64(1)).Return(entity.SpaceShip{}, assert.AnError)
 			},
 			wantErr: assert.AnError,
@@ -330,25 +621,81 @@ func TestService_Delete(t *testing.T) {
 			name: "Got GetByID() repo success but not found, should return non-nil error",
 			id:   3,
 			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(3)).Return(entity.SpaceShip{}, nil)
+			},
+			wantErr: errSpaceShipNotFound,
+		},
+		{
+			name:  "Given outdated version, should return precondition failed error",
+			id:    2,
+			match: entity.MatchVersion(3),
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+			},
+			wantErr: errVersionMismatch,
+		},
+		{
+			name:  "Given any version of a missing spaceship, should return precondition failed error",
+			id:    3,
+			match: entity.VersionMatch{Any: true},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
 				repo.EXPECT().GetByID(context.Background(), int64(3)).Return(entity.SpaceShip{}, nil)
 			},
-			wantErr: helpers.ErrBadRequest,
+			wantErr: errVersionMismatch,
+		},
+		{
+			name:  "Given any version of a stored spaceship, should delete it at its stored version",
+			id:    2,
+			match: entity.VersionMatch{Any: true},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				repo.EXPECT().Delete(context.Background(), int64(2), uint(4)).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name:  "Given versions among which the stored one, should delete it at its stored version",
+			id:    2,
+			match: entity.VersionMatch{Versions: []uint{3, 4}},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				repo.EXPECT().Delete(context.Background(), int64(2), uint(4)).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name:  "Given versions missing the stored one, should return precondition failed error",
+			id:    2,
+			match: entity.VersionMatch{Versions: []uint{2, 3}},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+			},
+			wantErr: errVersionMismatch,
 		},
 		{
 			name: "Got Delete() repo error, should return non-nil error",
 			id:   2,
 			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
 				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
-				repo.EXPECT().Delete(context.Background(), int64(2)).Return(assert.AnError)
+				repo.EXPECT().Delete(context.Background(), int64(2), uint(0)).Return(assert.AnError)
 			},
 			wantErr: assert.AnError,
 		},
 		{
-			name: "Got repo success, should return nil error",
-			id:   2,
+			name:  "Got repo success, should return nil error",
+			id:    2,
+			match: entity.MatchVersion(4),
 			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
 				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
-				repo.EXPECT().Delete(context.Background(), int64(2)).Return(nil)
+				repo.EXPECT().Delete(context.Background(), int64(2), uint(4)).Return(nil)
 			},
 			wantErr: nil,
 		},
@@ -368,60 +715,73 @@ func TestService_Delete(t *testing.T) {
 
 			tt.mocks(mockRepo)
 
-			err := s.Delete(context.Background(), tt.id)
+			err := s.Delete(context.Background(), tt.id, tt.match)
 			assert.Equal(t, tt.wantErr, err)
 		})
 	}
 }
 
-func TestService_GetAll(t *testing.T) {
-	// spaceship := entity.SpaceShip{
-	// 	Name:   "Devastator",
-	// 	Class:  "Star Destroyer",
-	// 	Crew:   1200,
-	// 	Image:  "https://test",
-	// 	Value:  100.99,
-	// 	Status: "Operational",
-	// 	Armaments: []entity.Armament{
-	// 		{
-	// 			Title: "Turbo Laser",
-	// 			Qty:   60,
-	// 		},
-	// 	},
-	// }
-	// spaceship.ID = 2
+func TestService_Restore(t *testing.T) {
+	deletedAt := time.Date(2023, 11, 2, 10, 0, 0, 0, time.UTC)
+
+	deleted := entity.SpaceShip{Name: "Devastator"}
+	deleted.ID = 2
+	deleted.DeletedAt = gorm.DeletedAt{Time: deletedAt, Valid: true}
+
+	active := entity.SpaceShip{Name: "Executor"}
+	active.ID = 4
 
 	tests := []struct {
 		name    string
-		req     entity.SpaceShip
+		id      int64
 		mocks   func(repo *mock_repo.MockSpaceShipRepository)
-		wants   []entity.SpaceShip
 		wantErr error
 	}{
 		{
-			name: "Got GetAll() repo error, should return empty slice and non-nil error",
-			req:  entity.SpaceShip{},
+			name: "Got GetByIDWithDeleted() repo error, should return non-nil error",
+			id:   1,
 			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
-				repo.EXPECT().GetAll(context.Background(), entity.SpaceShip{}).Return([]entity.SpaceShip{}, assert.AnError)
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByIDWithDeleted(context.Background(), int64(1)).Return(entity.SpaceShip{}, assert.AnError)
 			},
-			wants:   []entity.SpaceShip{},
 			wantErr: assert.AnError,
 		},
 		{
-			name: "Got repo success, should return non-empty slice and nil error",
-			req: entity.SpaceShip{
-				Name:   "Devas",
-				Class:  "Star Destroyer",
-				Status: "Operational",
+			name: "Got GetByIDWithDeleted() repo success but not found, should return non-nil error",
+			id:   3,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByIDWithDeleted(context.Background(), int64(3)).Return(entity.SpaceShip{}, nil)
 			},
+			wantErr: errSpaceShipNotFound,
+		},
+		{
+			name: "Given spaceship not deleted, should return conflict error",
+			id:   4,
 			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
-				repo.EXPECT().GetAll(context.Background(), entity.SpaceShip{
-					Name:   "Devas",
-					Class:  "Star Destroyer",
-					Status: "Operational",
-				}).Return([]entity.SpaceShip{}, nil)
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByIDWithDeleted(context.Background(), int64(4)).Return(active, nil)
+			},
+			wantErr: errSpaceShipNotDeleted,
+		},
+		{
+			name: "Got Restore() repo error, should return non-nil error",
+			id:   2,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByIDWithDeleted(context.Background(), int64(2)).Return(deleted, nil)
+				repo.EXPECT().Restore(context.Background(), int64(2), deletedAt).Return(assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Got repo success, should return nil error",
+			id:   2,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByIDWithDeleted(context.Background(), int64(2)).Return(deleted, nil)
+				repo.EXPECT().Restore(context.Background(), int64(2), deletedAt).Return(nil)
 			},
-			wants:   []entity.SpaceShip{},
 			wantErr: nil,
 		},
 	}
@@ -440,8 +800,788 @@ func TestService_GetAll(t *testing.T) {
 
 			tt.mocks(mockRepo)
 
-			got, err := s.GetAll(context.Background(), tt.req)
-			assert.Equal(t, tt.wants, got)
+			err := s.Restore(context.Background(), tt.id)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_Purge(t *testing.T) {
+	spaceship := entity.SpaceShip{Name: "Devastator"}
+	spaceship.ID = 2
+	spaceship.Version = 4
+	spaceship.DeletedAt = gorm.DeletedAt{Time: time.Date(2023, 11, 2, 10, 0, 0, 0, time.UTC), Valid: true}
+
+	tests := []struct {
+		name    string
+		id      int64
+		match   entity.VersionMatch
+		mocks   func(repo *mock_repo.MockSpaceShipRepository)
+		wantErr error
+	}{
+		{
+			name: "Got GetByIDWithDeleted() repo error, should return non-nil error",
+			id:   1,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByIDWithDeleted(context.Background(), int64(1)).Return(entity.SpaceShip{}, assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Got GetByIDWithDeleted() repo success but not found, should return non-nil error",
+			id:   3,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByIDWithDeleted(context.Background(), int64(3)).Return(entity.SpaceShip{}, nil)
+			},
+			wantErr: errSpaceShipNotFound,
+		},
+		{
+			name:  "Given outdated version, should return precondition failed error",
+			id:    2,
+			match: entity.MatchVersion(3),
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByIDWithDeleted(context.Background(), int64(2)).Return(spaceship, nil)
+			},
+			wantErr: errVersionMismatch,
+		},
+		{
+			name: "Got Purge() repo error, should return non-nil error",
+			id:   2,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByIDWithDeleted(context.Background(), int64(2)).Return(spaceship, nil)
+				repo.EXPECT().Purge(context.Background(), int64(2), uint(0)).Return(assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name:  "Given soft deleted spaceship, should purge it and return nil error",
+			id:    2,
+			match: entity.MatchVersion(4),
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByIDWithDeleted(context.Background(), int64(2)).Return(spaceship, nil)
+				repo.EXPECT().Purge(context.Background(), int64(2), uint(4)).Return(nil)
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repo.NewMockSpaceShipRepository(ctrl)
+			mockLogger := setupMockLogger()
+
+			s := &service{
+				repo:   mockRepo,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mockRepo)
+
+			err := s.Purge(context.Background(), tt.id, tt.match)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_GetAll(t *testing.T) {
+	// spaceship := entity.SpaceShip{
+	// 	Name:   "Devastator",
+	// 	Class:  "Star Destroyer",
+	// 	Crew:   1200,
+	// 	Image:  "https://test",
+	// 	Value:  100.99,
+	// 	Status: "Operational",
+	// 	Armaments: []entity.Armament{
+	// 		{
+	// 			WeaponID: 1,
+	// 			Qty:   60,
+	// 		},
+	// 	},
+	// }
+	// spaceship.ID = 2
+
+	tests := []struct {
+		name    string
+		req     entity.SpaceShipFilter
+		opts    entity.ListOptions
+		mocks   func(repo *mock_repo.MockSpaceShipRepository)
+		wants   entity.SpaceShipPage
+		wantErr error
+	}{
+		{
+			name: "Got GetAll() repo error, should return empty page and non-nil error",
+			req:  entity.SpaceShipFilter{},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().GetAll(context.Background(), entity.SpaceShipFilter{}, entity.ListOptions{}).Return(entity.SpaceShipPage{}, assert.AnError)
+			},
+			wants:   entity.SpaceShipPage{},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Got repo success, should return non-empty page and nil error",
+			req: entity.SpaceShipFilter{
+				Name:     "Devas",
+				Classes:  []string{"Star Destroyer"},
+				Statuses: []string{"Operational"},
+			},
+			opts: entity.ListOptions{Limit: 10, Cursor: "abc"},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().GetAll(context.Background(), entity.SpaceShipFilter{
+					Name:     "Devas",
+					Classes:  []string{"Star Destroyer"},
+					Statuses: []string{"Operational"},
+				}, entity.ListOptions{Limit: 10, Cursor: "abc"}).Return(entity.SpaceShipPage{SpaceShips: []entity.SpaceShip{{Name: "Devastator"}}, HasMore: true, NextCursor: "def"}, nil)
+			},
+			wants:   entity.SpaceShipPage{SpaceShips: []entity.SpaceShip{{Name: "Devastator"}}, HasMore: true, NextCursor: "def"},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repo.NewMockSpaceShipRepository(ctrl)
+			mockLogger := setupMockLogger()
+
+			s := &service{
+				repo:   mockRepo,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mockRepo)
+
+			got, err := s.GetAll(context.Background(), tt.req, tt.opts)
+			assert.Equal(t, tt.wants, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_Export(t *testing.T) {
+	spaceship := entity.SpaceShip{Name: "Devastator", Status: "Operational"}
+	spaceship.ID = 2
+
+	tests := []struct {
+		name    string
+		mocks   func(repo *mock_repo.MockSpaceShipRepository)
+		wants   []entity.SpaceShip
+		wantErr error
+	}{
+		{
+			name: "Got Export() repo error, should return non-nil error",
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().Export(context.Background(), gomock.Any()).Return(assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Got repo success, should hand every spaceship over to fn",
+			mocks: func(repo *mock_repo.MockSpaceShipRepository) {
+				repo.EXPECT().Export(context.Background(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(entity.SpaceShip) error) error {
+					return fn(spaceship)
+				})
+			},
+			wants:   []entity.SpaceShip{spaceship},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repo.NewMockSpaceShipRepository(ctrl)
+			mockLogger := setupMockLogger()
+
+			s := &service{
+				repo:   mockRepo,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mockRepo)
+
+			var got []entity.SpaceShip
+			err := s.Export(context.Background(), func(spaceship entity.SpaceShip) error {
+				got = append(got, spaceship)
+				return nil
+			})
+			assert.Equal(t, tt.wants, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_Bulk(t *testing.T) {
+	spaceship := entity.SpaceShip{Name: "Devastator", Status: "Operational"}
+	spaceship.ID = 2
+	spaceship.Version = 4
+
+	create := []entity.SpaceShip{
+		{Name: "Executor", Armaments: []entity.Armament{{WeaponID: 1, Qty: 10}}},
+		{Name: "Avenger", Armaments: []entity.Armament{{WeaponID: 9, Qty: 2}}},
+		{Name: "Chimaera"},
+	}
+	turboLaser := entity.Weapon{Model: gorm.Model{ID: 1}}
+
+	name := "Exactor"
+	update := []entity.SpaceShipUpdate{
+		{ID: 2, Patch: entity.SpaceShipPatch{Name: &name}},
+		{ID: 3, Patch: entity.SpaceShipPatch{Name: &name}},
+	}
+
+	renamed := spaceship
+	renamed.Name = "Exactor"
+	renamed.Armaments = nil
+
+	unknownWeapon := helpers.NewValidationError("unknown weapon", helpers.FieldError{Field: "armament[0].weapon_id", Message: errUnknownWeapon})
+
+	tests := []struct {
+		name    string
+		req     entity.SpaceShipBulk
+		mocks   func(repo *mock_repo.MockSpaceShipRepository, weaponRepo *mock_repo.MockWeaponRepository)
+		want    entity.SpaceShipBulkResult
+		wantErr error
+	}{
+		{
+			name: "Given atomic request with every item succeeding, should commit all of them",
+			req: entity.SpaceShipBulk{
+				Create: []entity.SpaceShip{create[0], create[2]},
+				Update: update[:1],
+				Delete: []int64{2},
+			},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx).Times(3)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{1}).Return([]entity.Weapon{turboLaser}, nil)
+				repo.EXPECT().InsertBatch(context.Background(), []entity.SpaceShip{create[0], create[2]}).
+					Return([]entity.SpaceShip{{Model: gorm.Model{ID: 10}}, {Model: gorm.Model{ID: 11}}}, nil)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil).Times(2)
+				repo.EXPECT().Update(context.Background(), int64(2), renamed).Return(nil)
+				repo.EXPECT().Delete(context.Background(), int64(2), uint(0)).Return(nil)
+			},
+			want: entity.SpaceShipBulkResult{
+				Create:    []entity.BulkResult{{ID: 10}, {ID: 11}},
+				Update:    []entity.BulkResult{{ID: 2}},
+				Delete:    []entity.BulkResult{{ID: 2}},
+				Committed: true,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Given atomic request with failing items, should roll back and flag every other item",
+			req: entity.SpaceShipBulk{
+				Create: create,
+				Update: update,
+			},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx).Times(3)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{1, 9}).Return([]entity.Weapon{turboLaser}, nil)
+				repo.EXPECT().InsertBatch(context.Background(), []entity.SpaceShip{create[0], create[2]}).
+					Return([]entity.SpaceShip{{Model: gorm.Model{ID: 10}}, {Model: gorm.Model{ID: 11}}}, nil)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				repo.EXPECT().Update(context.Background(), int64(2), renamed).Return(nil)
+				repo.EXPECT().GetByID(context.Background(), int64(3)).Return(entity.SpaceShip{}, nil)
+			},
+			want: entity.SpaceShipBulkResult{
+				Create: []entity.BulkResult{{Err: errBulkRolledBack}, {Err: unknownWeapon}, {Err: errBulkRolledBack}},
+				Update: []entity.BulkResult{{ID: 2, Err: errBulkRolledBack}, {ID: 3, Err: errSpaceShipNotFound}},
+				Delete: []entity.BulkResult{},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Given atomic request with failing batch, should roll back and flag every item",
+			req: entity.SpaceShipBulk{
+				Create: create,
+				Update: update[:1],
+				Delete: []int64{2},
+			},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{1, 9}).Return([]entity.Weapon{turboLaser}, nil)
+				repo.EXPECT().InsertBatch(context.Background(), []entity.SpaceShip{create[0], create[2]}).Return(nil, assert.AnError)
+			},
+			want: entity.SpaceShipBulkResult{
+				Create: []entity.BulkResult{{Err: errBulkInsertFailed}, {Err: unknownWeapon}, {Err: errBulkInsertFailed}},
+				Update: []entity.BulkResult{{ID: 2, Err: errBulkRolledBack}},
+				Delete: []entity.BulkResult{{ID: 2, Err: errBulkRolledBack}},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Given best effort request with failing batch, should insert the spaceships one by one",
+			req: entity.SpaceShipBulk{
+				Create:     create,
+				Delete:     []int64{3},
+				BestEffort: true,
+			},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{1, 9}).Return([]entity.Weapon{turboLaser}, nil)
+				repo.EXPECT().InsertBatch(context.Background(), []entity.SpaceShip{create[0], create[2]}).Return(nil, assert.AnError)
+				repo.EXPECT().InsertBatch(context.Background(), []entity.SpaceShip{create[0]}).Return(nil, assert.AnError)
+				repo.EXPECT().InsertBatch(context.Background(), []entity.SpaceShip{create[2]}).Return([]entity.SpaceShip{{Model: gorm.Model{ID: 11}}}, nil)
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(3)).Return(entity.SpaceShip{}, nil)
+			},
+			want: entity.SpaceShipBulkResult{
+				Create:    []entity.BulkResult{{Err: assert.AnError}, {Err: unknownWeapon}, {ID: 11}},
+				Update:    []entity.BulkResult{},
+				Delete:    []entity.BulkResult{{ID: 3, Err: errSpaceShipNotFound}},
+				Committed: true,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Given empty best effort request, should report it committed",
+			req:  entity.SpaceShipBulk{BestEffort: true},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+			},
+			want: entity.SpaceShipBulkResult{
+				Create:    []entity.BulkResult{},
+				Update:    []entity.BulkResult{},
+				Delete:    []entity.BulkResult{},
+				Committed: true,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Given best effort request whose every item fails, should not report it committed",
+			req: entity.SpaceShipBulk{
+				Delete:     []int64{3},
+				BestEffort: true,
+			},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(3)).Return(entity.SpaceShip{}, nil)
+			},
+			want: entity.SpaceShipBulkResult{
+				Create:    []entity.BulkResult{},
+				Update:    []entity.BulkResult{},
+				Delete:    []entity.BulkResult{{ID: 3, Err: errSpaceShipNotFound}},
+				Committed: false,
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repo.NewMockSpaceShipRepository(ctrl)
+			mockWeaponRepo := mock_repo.NewMockWeaponRepository(ctrl)
+			mockLogger := setupMockLogger()
+
+			s := &service{
+				repo:       mockRepo,
+				weaponRepo: mockWeaponRepo,
+				logger:     mockLogger,
+			}
+
+			tt.mocks(mockRepo, mockWeaponRepo)
+
+			got, err := s.Bulk(context.Background(), tt.req)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_GetArmaments(t *testing.T) {
+	spaceship := entity.SpaceShip{Name: "Devastator"}
+	spaceship.ID = 2
+
+	armaments := []entity.Armament{{WeaponID: 1, Qty: 60, SpaceShipID: 2}}
+	armaments[0].ID = 5
+
+	tests := []struct {
+		name    string
+		id      int64
+		mocks   func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository)
+		want    []entity.Armament
+		wantErr error
+	}{
+		{
+			name: "Got GetByID() repo success but not found, should return non-nil error",
+			id:   3,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository) {
+				repo.EXPECT().GetByID(context.Background(), int64(3)).Return(entity.SpaceShip{}, nil)
+			},
+			want:    nil,
+			wantErr: errSpaceShipNotFound,
+		},
+		{
+			name: "Got GetAll() armament repo error, should return non-nil error",
+			id:   2,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository) {
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				armamentRepo.EXPECT().GetAll(context.Background(), int64(2)).Return(nil, assert.AnError)
+			},
+			want:    nil,
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Got repo success, should return the armaments with nil error",
+			id:   2,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository) {
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				armamentRepo.EXPECT().GetAll(context.Background(), int64(2)).Return(armaments, nil)
+			},
+			want:    armaments,
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repo.NewMockSpaceShipRepository(ctrl)
+			mockArmamentRepo := mock_repo.NewMockArmamentRepository(ctrl)
+			mockLogger := setupMockLogger()
+
+			s := &service{
+				repo:         mockRepo,
+				armamentRepo: mockArmamentRepo,
+				logger:       mockLogger,
+			}
+
+			tt.mocks(mockRepo, mockArmamentRepo)
+
+			got, err := s.GetArmaments(context.Background(), tt.id)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_CreateArmament(t *testing.T) {
+	spaceship := entity.SpaceShip{Name: "Devastator"}
+	spaceship.ID = 2
+
+	req := entity.Armament{WeaponID: 2, Qty: 10}
+	created := entity.Armament{WeaponID: 2, Qty: 10, SpaceShipID: 2}
+	created.ID = 6
+
+	weapon := entity.Weapon{Name: "Ion Cannon"}
+	weapon.ID = 2
+
+	withWeapon := created
+	withWeapon.Weapon = weapon
+
+	tests := []struct {
+		name    string
+		id      int64
+		mocks   func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository)
+		want    entity.Armament
+		wantErr error
+	}{
+		{
+			name: "Got GetByID() repo success but not found, should return non-nil error",
+			id:   3,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(3)).Return(entity.SpaceShip{}, nil)
+			},
+			want:    entity.Armament{},
+			wantErr: errSpaceShipNotFound,
+		},
+		{
+			name: "Given unknown weapon, should return validation error",
+			id:   2,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{2}).Return(nil, nil)
+			},
+			want:    entity.Armament{},
+			wantErr: helpers.NewValidationError("unknown weapon", helpers.FieldError{Field: "weapon_id", Message: errUnknownWeapon}),
+		},
+		{
+			name: "Got Insert() armament repo error, should return non-nil error",
+			id:   2,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{2}).Return([]entity.Weapon{weapon}, nil)
+				armamentRepo.EXPECT().Insert(context.Background(), entity.Armament{WeaponID: 2, Qty: 10, SpaceShipID: 2}).Return(entity.Armament{}, assert.AnError)
+			},
+			want:    entity.Armament{},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Got BumpVersion() repo error, should return non-nil error",
+			id:   2,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{2}).Return([]entity.Weapon{weapon}, nil)
+				armamentRepo.EXPECT().Insert(context.Background(), entity.Armament{WeaponID: 2, Qty: 10, SpaceShipID: 2}).Return(created, nil)
+				repo.EXPECT().BumpVersion(context.Background(), int64(2)).Return(assert.AnError)
+			},
+			want:    entity.Armament{},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Got repo success, should return the created armament with nil error",
+			id:   2,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{2}).Return([]entity.Weapon{weapon}, nil)
+				armamentRepo.EXPECT().Insert(context.Background(), entity.Armament{WeaponID: 2, Qty: 10, SpaceShipID: 2}).Return(created, nil)
+				repo.EXPECT().BumpVersion(context.Background(), int64(2)).Return(nil)
+			},
+			want:    withWeapon,
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repo.NewMockSpaceShipRepository(ctrl)
+			mockArmamentRepo := mock_repo.NewMockArmamentRepository(ctrl)
+			mockWeaponRepo := mock_repo.NewMockWeaponRepository(ctrl)
+			mockLogger := setupMockLogger()
+
+			s := &service{
+				repo:         mockRepo,
+				armamentRepo: mockArmamentRepo,
+				weaponRepo:   mockWeaponRepo,
+				logger:       mockLogger,
+			}
+
+			tt.mocks(mockRepo, mockArmamentRepo, mockWeaponRepo)
+
+			got, err := s.CreateArmament(context.Background(), tt.id, req)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_UpdateArmament(t *testing.T) {
+	spaceship := entity.SpaceShip{Name: "Devastator"}
+	spaceship.ID = 2
+
+	armament := entity.Armament{WeaponID: 1, Qty: 60, SpaceShipID: 2}
+	armament.ID = 5
+	armament.Weapon = entity.Weapon{Name: "Turbo Laser"}
+	armament.Weapon.ID = 1
+
+	ionCannon := entity.Weapon{Name: "Ion Cannon"}
+	ionCannon.ID = 2
+
+	qty := 80
+	updated := armament
+	updated.Qty = 80
+
+	weaponID := uint(2)
+	rearmed := armament
+	rearmed.WeaponID = 2
+	rearmed.Weapon = ionCannon
+
+	tests := []struct {
+		name       string
+		armamentID int64
+		patch      entity.ArmamentPatch
+		mocks      func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository)
+		want       entity.Armament
+		wantErr    error
+	}{
+		{
+			name:       "Got GetByID() armament repo success but not found, should return non-nil error",
+			armamentID: 9,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				armamentRepo.EXPECT().GetByID(context.Background(), int64(2), int64(9)).Return(entity.Armament{}, nil)
+			},
+			want:    entity.Armament{},
+			wantErr: errArmamentNotFound,
+		},
+		{
+			name:       "Got Update() armament repo error, should return non-nil error",
+			armamentID: 5,
+			patch:      entity.ArmamentPatch{Qty: &qty},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				armamentRepo.EXPECT().GetByID(context.Background(), int64(2), int64(5)).Return(armament, nil)
+				armamentRepo.EXPECT().Update(context.Background(), updated).Return(assert.AnError)
+			},
+			want:    entity.Armament{},
+			wantErr: assert.AnError,
+		},
+		{
+			name:       "Got BumpVersion() repo error, should return non-nil error",
+			armamentID: 5,
+			patch:      entity.ArmamentPatch{Qty: &qty},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				armamentRepo.EXPECT().GetByID(context.Background(), int64(2), int64(5)).Return(armament, nil)
+				armamentRepo.EXPECT().Update(context.Background(), updated).Return(nil)
+				repo.EXPECT().BumpVersion(context.Background(), int64(2)).Return(assert.AnError)
+			},
+			want:    entity.Armament{},
+			wantErr: assert.AnError,
+		},
+		{
+			name:       "Got repo success, should update the armament in place and return it",
+			armamentID: 5,
+			patch:      entity.ArmamentPatch{Qty: &qty},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				armamentRepo.EXPECT().GetByID(context.Background(), int64(2), int64(5)).Return(armament, nil)
+				armamentRepo.EXPECT().Update(context.Background(), updated).Return(nil)
+				repo.EXPECT().BumpVersion(context.Background(), int64(2)).Return(nil)
+			},
+			want:    updated,
+			wantErr: nil,
+		},
+		{
+			name:       "Given unknown weapon, should return validation error",
+			armamentID: 5,
+			patch:      entity.ArmamentPatch{WeaponID: &weaponID},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				armamentRepo.EXPECT().GetByID(context.Background(), int64(2), int64(5)).Return(armament, nil)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{2}).Return(nil, nil)
+			},
+			want:    entity.Armament{},
+			wantErr: helpers.NewValidationError("unknown weapon", helpers.FieldError{Field: "weapon_id", Message: errUnknownWeapon}),
+		},
+		{
+			name:       "Given another weapon, should update the armament and return it with its new weapon",
+			armamentID: 5,
+			patch:      entity.ArmamentPatch{WeaponID: &weaponID},
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository, weaponRepo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				armamentRepo.EXPECT().GetByID(context.Background(), int64(2), int64(5)).Return(armament, nil)
+				weaponRepo.EXPECT().GetByIDs(context.Background(), []int64{2}).Return([]entity.Weapon{ionCannon}, nil)
+				armamentRepo.EXPECT().Update(context.Background(), rearmed).Return(nil)
+				repo.EXPECT().BumpVersion(context.Background(), int64(2)).Return(nil)
+			},
+			want:    rearmed,
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repo.NewMockSpaceShipRepository(ctrl)
+			mockArmamentRepo := mock_repo.NewMockArmamentRepository(ctrl)
+			mockWeaponRepo := mock_repo.NewMockWeaponRepository(ctrl)
+			mockLogger := setupMockLogger()
+
+			s := &service{
+				repo:         mockRepo,
+				armamentRepo: mockArmamentRepo,
+				weaponRepo:   mockWeaponRepo,
+				logger:       mockLogger,
+			}
+
+			tt.mocks(mockRepo, mockArmamentRepo, mockWeaponRepo)
+
+			got, err := s.UpdateArmament(context.Background(), 2, tt.armamentID, tt.patch)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_DeleteArmament(t *testing.T) {
+	spaceship := entity.SpaceShip{Name: "Devastator"}
+	spaceship.ID = 2
+
+	armament := entity.Armament{WeaponID: 1, Qty: 60, SpaceShipID: 2}
+	armament.ID = 5
+
+	tests := []struct {
+		name       string
+		armamentID int64
+		mocks      func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository)
+		wantErr    error
+	}{
+		{
+			name:       "Got GetByID() armament repo success but not found, should return non-nil error",
+			armamentID: 9,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				armamentRepo.EXPECT().GetByID(context.Background(), int64(2), int64(9)).Return(entity.Armament{}, nil)
+			},
+			wantErr: errArmamentNotFound,
+		},
+		{
+			name:       "Got Delete() armament repo error, should return non-nil error",
+			armamentID: 5,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				armamentRepo.EXPECT().GetByID(context.Background(), int64(2), int64(5)).Return(armament, nil)
+				armamentRepo.EXPECT().Delete(context.Background(), int64(2), int64(5)).Return(assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name:       "Got BumpVersion() repo error, should return non-nil error",
+			armamentID: 5,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				armamentRepo.EXPECT().GetByID(context.Background(), int64(2), int64(5)).Return(armament, nil)
+				armamentRepo.EXPECT().Delete(context.Background(), int64(2), int64(5)).Return(nil)
+				repo.EXPECT().BumpVersion(context.Background(), int64(2)).Return(assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name:       "Got repo success, should return nil error",
+			armamentID: 5,
+			mocks: func(repo *mock_repo.MockSpaceShipRepository, armamentRepo *mock_repo.MockArmamentRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(2)).Return(spaceship, nil)
+				armamentRepo.EXPECT().GetByID(context.Background(), int64(2), int64(5)).Return(armament, nil)
+				armamentRepo.EXPECT().Delete(context.Background(), int64(2), int64(5)).Return(nil)
+				repo.EXPECT().BumpVersion(context.Background(), int64(2)).Return(nil)
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repo.NewMockSpaceShipRepository(ctrl)
+			mockArmamentRepo := mock_repo.NewMockArmamentRepository(ctrl)
+			mockLogger := setupMockLogger()
+
+			s := &service{
+				repo:         mockRepo,
+				armamentRepo: mockArmamentRepo,
+				logger:       mockLogger,
+			}
+
+			tt.mocks(mockRepo, mockArmamentRepo)
+
+			err := s.DeleteArmament(context.Background(), 2, tt.armamentID)
 			assert.Equal(t, tt.wantErr, err)
 		})
 	}
@@ -0,0 +1,171 @@
+package spaceship
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+	"github.com/wndisra/galactic-svc/internal/tracing"
+)
+
+// tracingService traces the calls to a Service, each in a span carrying the
+// spaceship IDs and filters it was called with.
+type tracingService struct {
+	next Service
+}
+
+// NewTracingService returns s with its calls traced.
+func NewTracingService(s Service) Service {
+	return tracingService{next: s}
+}
+
+const (
+	spaceshipIDKey = attribute.Key("spaceship.id")
+	armamentIDKey  = attribute.Key("armament.id")
+)
+
+// filterAttributes describes the filters which are set.
+func filterAttributes(filter entity.SpaceShipFilter, opts entity.ListOptions) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.Bool("spaceship.filter.include_deleted", filter.IncludeDeleted),
+		attribute.Int("spaceship.list.limit", opts.Limit),
+		attribute.Bool("spaceship.list.cursor", opts.Cursor != ""),
+	}
+
+	if filter.Name != "" {
+		attrs = append(attrs, attribute.String("spaceship.filter.name", filter.Name))
+	}
+	if len(filter.Classes) > 0 {
+		attrs = append(attrs, attribute.StringSlice("spaceship.filter.classes", filter.Classes))
+	}
+	if len(filter.Statuses) > 0 {
+		attrs = append(attrs, attribute.StringSlice("spaceship.filter.statuses", filter.Statuses))
+	}
+	if filter.MinCrew != nil {
+		attrs = append(attrs, attribute.Int64("spaceship.filter.min_crew", *filter.MinCrew))
+	}
+	if filter.MaxCrew != nil {
+		attrs = append(attrs, attribute.Int64("spaceship.filter.max_crew", *filter.MaxCrew))
+	}
+	if filter.MinValue != nil {
+		attrs = append(attrs, attribute.Float64("spaceship.filter.min_value", *filter.MinValue))
+	}
+	if filter.MaxValue != nil {
+		attrs = append(attrs, attribute.Float64("spaceship.filter.max_value", *filter.MaxValue))
+	}
+	if opts.Offset > 0 {
+		attrs = append(attrs, attribute.Int("spaceship.list.offset", opts.Offset))
+	}
+
+	return attrs
+}
+
+func (t tracingService) Create(ctx context.Context, req entity.SpaceShip) (res entity.SpaceShip, err error) {
+	ctx, span := tracing.Start(ctx, "spaceship.Service.Create")
+	defer func() {
+		span.SetAttributes(spaceshipIDKey.Int64(int64(res.ID)))
+		tracing.End(span, err)
+	}()
+
+	return t.next.Create(ctx, req)
+}
+
+func (t tracingService) GetByID(ctx context.Context, id int64) (res entity.SpaceShip, err error) {
+	ctx, span := tracing.Start(ctx, "spaceship.Service.GetByID", spaceshipIDKey.Int64(id))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.GetByID(ctx, id)
+}
+
+func (t tracingService) Update(ctx context.Context, id int64, match entity.VersionMatch, patch entity.SpaceShipPatch) (res uint, err error) {
+	ctx, span := tracing.Start(ctx, "spaceship.Service.Update", spaceshipIDKey.Int64(id))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.Update(ctx, id, match, patch)
+}
+
+func (t tracingService) Replace(ctx context.Context, id int64, match entity.VersionMatch, req entity.SpaceShip) (res uint, err error) {
+	ctx, span := tracing.Start(ctx, "spaceship.Service.Replace", spaceshipIDKey.Int64(id))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.Replace(ctx, id, match, req)
+}
+
+func (t tracingService) Delete(ctx context.Context, id int64, match entity.VersionMatch) (err error) {
+	ctx, span := tracing.Start(ctx, "spaceship.Service.Delete", spaceshipIDKey.Int64(id))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.Delete(ctx, id, match)
+}
+
+func (t tracingService) Restore(ctx context.Context, id int64) (err error) {
+	ctx, span := tracing.Start(ctx, "spaceship.Service.Restore", spaceshipIDKey.Int64(id))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.Restore(ctx, id)
+}
+
+func (t tracingService) Purge(ctx context.Context, id int64, match entity.VersionMatch) (err error) {
+	ctx, span := tracing.Start(ctx, "spaceship.Service.Purge", spaceshipIDKey.Int64(id))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.Purge(ctx, id, match)
+}
+
+func (t tracingService) GetAll(ctx context.Context, filter entity.SpaceShipFilter, opts entity.ListOptions) (res entity.SpaceShipPage, err error) {
+	ctx, span := tracing.Start(ctx, "spaceship.Service.GetAll", filterAttributes(filter, opts)...)
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.GetAll(ctx, filter, opts)
+}
+
+func (t tracingService) Bulk(ctx context.Context, req entity.SpaceShipBulk) (res entity.SpaceShipBulkResult, err error) {
+	ctx, span := tracing.Start(ctx, "spaceship.Service.Bulk",
+		attribute.Int("spaceship.bulk.create", len(req.Create)),
+		attribute.Int("spaceship.bulk.update", len(req.Update)),
+		attribute.Int64Slice("spaceship.bulk.delete", req.Delete),
+		attribute.Bool("spaceship.bulk.best_effort", req.BestEffort),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.Bulk(ctx, req)
+}
+
+func (t tracingService) Export(ctx context.Context, fn func(spaceship entity.SpaceShip) error) (err error) {
+	ctx, span := tracing.Start(ctx, "spaceship.Service.Export")
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.Export(ctx, fn)
+}
+
+func (t tracingService) GetArmaments(ctx context.Context, spaceshipID int64) (res []entity.Armament, err error) {
+	ctx, span := tracing.Start(ctx, "spaceship.Service.GetArmaments", spaceshipIDKey.Int64(spaceshipID))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.GetArmaments(ctx, spaceshipID)
+}
+
+func (t tracingService) CreateArmament(ctx context.Context, spaceshipID int64, req entity.Armament) (res entity.Armament, err error) {
+	ctx, span := tracing.Start(ctx, "spaceship.Service.CreateArmament", spaceshipIDKey.Int64(spaceshipID))
+	defer func() {
+		span.SetAttributes(armamentIDKey.Int64(int64(res.ID)))
+		tracing.End(span, err)
+	}()
+
+	return t.next.CreateArmament(ctx, spaceshipID, req)
+}
+
+func (t tracingService) UpdateArmament(ctx context.Context, spaceshipID int64, id int64, patch entity.ArmamentPatch) (res entity.Armament, err error) {
+	ctx, span := tracing.Start(ctx, "spaceship.Service.UpdateArmament", spaceshipIDKey.Int64(spaceshipID), armamentIDKey.Int64(id))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.UpdateArmament(ctx, spaceshipID, id, patch)
+}
+
+func (t tracingService) DeleteArmament(ctx context.Context, spaceshipID int64, id int64) (err error) {
+	ctx, span := tracing.Start(ctx, "spaceship.Service.DeleteArmament", spaceshipIDKey.Int64(spaceshipID), armamentIDKey.Int64(id))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.DeleteArmament(ctx, spaceshipID, id)
+}
@@ -1,63 +1,174 @@
 package spaceship
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	ht "github.com/go-kit/kit/transport/http"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/julienschmidt/httprouter"
 
+	"github.com/wndisra/galactic-svc/internal/entity"
 	"github.com/wndisra/galactic-svc/internal/helpers"
+	"github.com/wndisra/galactic-svc/internal/logging"
+	"github.com/wndisra/galactic-svc/internal/metrics"
+	"github.com/wndisra/galactic-svc/internal/tracing"
 )
 
-func RegisterRoutes(router *httprouter.Router, s Service) {
+// RegisterRoutes adds the spaceship routes to router. Requests bearing
+// adminToken may use the admin only features, such as purging a spaceship.
+// Mutating requests bearing an Idempotency-Key header go through idempotency.
+// The main endpoints are instrumented by m, unless it is nil.
+func RegisterRoutes(router *httprouter.Router, s Service, adminToken string, idempotency *helpers.Idempotency, m *metrics.Metrics) {
 	opts := []ht.ServerOption{
+		ht.ServerBefore(helpers.AdminAuth(adminToken)),
 		ht.ServerErrorEncoder(helpers.EncodeError),
 	}
 
+	mutatingOpts := []ht.ServerOption{
+		ht.ServerBefore(helpers.AdminAuth(adminToken), idempotency.Before),
+		ht.ServerErrorEncoder(helpers.EncodeError),
+	}
+
+	// Each handler traces its requests in a span of its own, continuing the
+	// trace of the caller.
+	traced := func(name string, opts []ht.ServerOption) []ht.ServerOption {
+		return append(tracing.ServerOptions(name), opts...)
+	}
+
 	createHandler := ht.NewServer(
-		MakeEndpointCreate(s),
+		m.Endpoint("spaceship.create")(idempotency.Middleware(MakeEndpointCreate(s))),
 		decodeCreateRequest,
-		encodeCreateResponse,
-		opts...,
+		idempotency.Encode(encodeCreateResponse),
+		traced("spaceship.create", mutatingOpts)...,
 	)
 
 	getByIDHandler := ht.NewServer(
-		MakeEndpointGetByID(s),
+		m.Endpoint("spaceship.get_by_id")(MakeEndpointGetByID(s)),
 		decodeGetByIDRequest,
 		encodeGetByIDResponse,
-		opts...,
+		traced("spaceship.get_by_id", opts)...,
 	)
 
 	updateHandler := ht.NewServer(
-		MakeEndpointUpdate(s),
+		m.Endpoint("spaceship.update")(idempotency.Middleware(MakeEndpointUpdate(s))),
 		decodeUpdateRequest,
-		encodeUpdateResponse,
-		opts...,
+		idempotency.Encode(encodeUpdateResponse),
+		traced("spaceship.update", mutatingOpts)...,
+	)
+
+	replaceHandler := ht.NewServer(
+		idempotency.Middleware(MakeEndpointReplace(s)),
+		decodeReplaceRequest,
+		idempotency.Encode(encodeReplaceResponse),
+		traced("spaceship.replace", mutatingOpts)...,
 	)
 
 	deleteByIDHandler := ht.NewServer(
-		MakeEndpointDeleteByID(s),
+		m.Endpoint("spaceship.delete_by_id")(idempotency.Middleware(MakeEndpointDeleteByID(s))),
 		decodeDeleteByIDRequest,
-		encodeDeleteByIDResponse,
-		opts...,
+		idempotency.Encode(encodeDeleteByIDResponse),
+		traced("spaceship.delete_by_id", mutatingOpts)...,
+	)
+
+	restoreHandler := ht.NewServer(
+		idempotency.Middleware(MakeEndpointRestore(s)),
+		decodeRestoreRequest,
+		idempotency.Encode(encodeRestoreResponse),
+		traced("spaceship.restore", mutatingOpts)...,
+	)
+
+	bulkHandler := ht.NewServer(
+		idempotency.Middleware(MakeEndpointBulk(s)),
+		decodeBulkRequest,
+		idempotency.Encode(encodeBulkResponse),
+		traced("spaceship.bulk", mutatingOpts)...,
+	)
+
+	exportHandler := ht.NewServer(
+		MakeEndpointExport(s),
+		decodeExportRequest,
+		encodeExportResponse,
+		traced("spaceship.export", opts)...,
+	)
+
+	importHandler := ht.NewServer(
+		idempotency.Middleware(MakeEndpointImport(s)),
+		decodeImportRequest,
+		idempotency.Encode(encodeImportResponse),
+		traced("spaceship.import", mutatingOpts)...,
 	)
 
 	getAllHandler := ht.NewServer(
-		MakeEndpointGetAll(s),
+		m.Endpoint("spaceship.get_all")(MakeEndpointGetAll(s)),
 		decodeGetAllRequest,
 		encodeGetAllResponse,
-		opts...,
+		traced("spaceship.get_all", opts)...,
 	)
 
+	getArmamentsHandler := ht.NewServer(
+		MakeEndpointGetArmaments(s),
+		decodeGetArmamentsRequest,
+		encodeGetArmamentsResponse,
+		traced("spaceship.get_armaments", opts)...,
+	)
+
+	createArmamentHandler := ht.NewServer(
+		idempotency.Middleware(MakeEndpointCreateArmament(s)),
+		decodeCreateArmamentRequest,
+		idempotency.Encode(encodeCreateArmamentResponse),
+		traced("spaceship.create_armament", mutatingOpts)...,
+	)
+
+	updateArmamentHandler := ht.NewServer(
+		idempotency.Middleware(MakeEndpointUpdateArmament(s)),
+		decodeUpdateArmamentRequest,
+		idempotency.Encode(encodeUpdateArmamentResponse),
+		traced("spaceship.update_armament", mutatingOpts)...,
+	)
+
+	deleteArmamentHandler := ht.NewServer(
+		idempotency.Middleware(MakeEndpointDeleteArmament(s)),
+		decodeDeleteArmamentRequest,
+		idempotency.Encode(encodeDeleteArmamentResponse),
+		traced("spaceship.delete_armament", mutatingOpts)...,
+	)
+
+	// httprouter cannot tell a static segment from the :id wildcard, so the
+	// bulk, import and export routes are served by the /spaceship/:id ones.
+	// Registering POST /spaceship/:id for them hides it from the router's own
+	// 405, which the other IDs get from methodNotAllowed instead.
+	getHandler := staticSegments("id", map[string]http.Handler{"export": exportHandler}, getByIDHandler)
+	postHandler := staticSegments("id", map[string]http.Handler{"bulk": bulkHandler, "import": importHandler},
+		methodNotAllowed(http.MethodDelete, http.MethodGet, http.MethodOptions, http.MethodPatch, http.MethodPut))
+
 	router.Handler(http.MethodPost, "/spaceship", createHandler)
-	router.Handler(http.MethodGet, "/spaceship/:id", getByIDHandler)
-	router.Handler(http.MethodPatch, "/spaceship", updateHandler)
+	router.Handler(http.MethodGet, "/spaceship/:id", getHandler)
+	router.Handler(http.MethodPost, "/spaceship/:id", postHandler)
+	router.Handler(http.MethodPatch, "/spaceship/:id", updateHandler)
+	router.Handler(http.MethodPut, "/spaceship/:id", replaceHandler)
 	router.Handler(http.MethodDelete, "/spaceship/:id", deleteByIDHandler)
+	router.Handler(http.MethodPost, "/spaceship/:id/restore", restoreHandler)
 	router.Handler(http.MethodGet, "/spaceship", getAllHandler)
+
+	router.Handler(http.MethodGet, "/spaceship/:id/armaments", getArmamentsHandler)
+	router.Handler(http.MethodPost, "/spaceship/:id/armaments", createArmamentHandler)
+	router.Handler(http.MethodPatch, "/spaceship/:id/armaments/:armamentId", updateArmamentHandler)
+	router.Handler(http.MethodDelete, "/spaceship/:id/armaments/:armamentId", deleteArmamentHandler)
 }
 
 type createRequest struct {
@@ -71,35 +182,50 @@ type createRequest struct {
 }
 
 type armamentReq struct {
-	Title string `json:"title"`
-	Qty   int    `json:"qty"`
+	WeaponID uint `json:"weapon_id"`
+	Qty      int  `json:"qty"`
 }
 
 type armamentResponse struct {
-	Title string `json:"title"`
-	Qty   int    `json:"qty"`
+	ID       int64  `json:"id"`
+	WeaponID int64  `json:"weapon_id"`
+	Weapon   string `json:"weapon"`
+	Qty      int    `json:"qty"`
+}
+
+func (r createRequest) toModel() CreateRequestModel {
+	return CreateRequestModel{
+		Name:      r.Name,
+		Class:     r.Class,
+		Crew:      r.Crew,
+		Image:     r.Image,
+		Value:     r.Value,
+		Status:    r.Status,
+		Armaments: toArmamentReqModels(r.Armaments),
+	}
+}
+
+func toArmamentReqModels(req []armamentReq) []armamentReqModel {
+	armaments := make([]armamentReqModel, len(req))
+	for i, armament := range req {
+		armaments[i] = armamentReqModel(armament)
+	}
+
+	return armaments
 }
 
 func decodeCreateRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
 	var req createRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		return nil, fmt.Errorf("decodeCreateRequest(): %s", err)
+	if err := helpers.DecodeJSON(r.Body, &req); err != nil {
+		return nil, err
 	}
 
-	armaments := make([]armamentReqModel, len(req.Armaments))
-	for i, armament := range req.Armaments {
-		armaments[i] = armamentReqModel(armament)
+	model := req.toModel()
+	if err := model.Validate(); err != nil {
+		return nil, err
 	}
 
-	return CreateRequestModel{
-		Name:      req.Name,
-		Class:     req.Class,
-		Crew:      req.Crew,
-		Image:     req.Image,
-		Value:     req.Value,
-		Status:    req.Status,
-		Armaments: armaments,
-	}, nil
+	return model, nil
 }
 
 func encodeCreateResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
@@ -110,22 +236,17 @@ func encodeCreateResponse(ctx context.Context, w http.ResponseWriter, response i
 
 	formatted := formatCreateResponse(res)
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/spaceship/%d", res.SpaceShip.ID))
+	w.Header().Set("ETag", formatETag(res.SpaceShip.Version))
 	w.WriteHeader(http.StatusCreated)
 
 	return json.NewEncoder(w).Encode(formatted)
 }
 
 func decodeGetByIDRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
-	params := httprouter.ParamsFromContext(ctx)
-
-	idPath := params.ByName("id")
-	if idPath == ":id" || idPath == "" {
-		return nil, helpers.ErrInvalidPathParam
-	}
-
-	id, err := strconv.ParseInt(idPath, 10, 64)
+	id, err := decodeIDParam(ctx)
 	if err != nil {
-		return nil, helpers.ErrInvalidPathParam
+		return nil, err
 	}
 
 	return GetByIDRequestModel{
@@ -141,41 +262,88 @@ func encodeGetByIDResponse(ctx context.Context, w http.ResponseWriter, response
 
 	formatted := formatGetByIDResponse(res)
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", formatETag(res.SpaceShip.Version))
 	return json.NewEncoder(w).Encode(formatted)
 }
 
 type updateRequest struct {
-	ID        int64         `json:"id"`
-	Name      string        `json:"name"`
-	Class     string        `json:"class"`
-	Crew      int64         `json:"crew"`
-	Image     string        `json:"image"`
-	Value     float64       `json:"value"`
-	Status    string        `json:"status"`
-	Armaments []armamentReq `json:"armament"`
+	Name      *string        `json:"name"`
+	Class     *string        `json:"class"`
+	Crew      *int64         `json:"crew"`
+	Image     *string        `json:"image"`
+	Value     *float64       `json:"value"`
+	Status    *string        `json:"status"`
+	Armaments *[]armamentReq `json:"armament"`
+}
+
+// resetNull applies the RFC 7396 meaning of a null member, removing the
+// value, which resets the field to its zero value.
+func (r *updateRequest) resetNull(member string) {
+	switch member {
+	case "name":
+		r.Name = new(string)
+	case "class":
+		r.Class = new(string)
+	case "crew":
+		r.Crew = new(int64)
+	case "image":
+		r.Image = new(string)
+	case "value":
+		r.Value = new(float64)
+	case "status":
+		r.Status = new(string)
+	case "armament":
+		r.Armaments = &[]armamentReq{}
+	}
+}
+
+func (r updateRequest) toModel(id int64, version entity.VersionMatch) UpdateRequestModel {
+	model := UpdateRequestModel{
+		ID:      id,
+		Version: version,
+		Name:    r.Name,
+		Class:   r.Class,
+		Crew:    r.Crew,
+		Image:   r.Image,
+		Value:   r.Value,
+		Status:  r.Status,
+	}
+
+	if r.Armaments != nil {
+		armaments := toArmamentReqModels(*r.Armaments)
+		model.Armaments = &armaments
+	}
+
+	return model
 }
 
 func decodeUpdateRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	id, err := decodeIDParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := decodeIfMatch(r)
+	if err != nil {
+		return nil, err
+	}
+
 	var req updateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		return nil, fmt.Errorf("decodeUpdateRequest(): %s", err)
+	nulls, err := helpers.DecodeMergePatch(r, &req)
+	if err != nil {
+		return nil, err
 	}
 
-	armaments := make([]armamentReqModel, len(req.Armaments))
-	for i, armament := range req.Armaments {
-		armaments[i] = armamentReqModel(armament)
+	for _, member := range nulls {
+		req.resetNull(member)
 	}
 
-	return UpdateRequestModel{
-		ID:        req.ID,
-		Name:      req.Name,
-		Class:     req.Class,
-		Crew:      req.Crew,
-		Image:     req.Image,
-		Value:     req.Value,
-		Status:    req.Status,
-		Armaments: armaments,
-	}, nil
+	model := req.toModel(id, version)
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+
+	return model, nil
 }
 
 func encodeUpdateResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
@@ -186,25 +354,77 @@ func encodeUpdateResponse(ctx context.Context, w http.ResponseWriter, response i
 
 	formatted := formatUpdateResponse(res)
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", formatETag(res.Version))
+
+	return json.NewEncoder(w).Encode(formatted)
+}
+
+func decodeReplaceRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	id, err := decodeIDParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := decodeIfMatch(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req createRequest
+	if err := helpers.DecodeJSON(r.Body, &req); err != nil {
+		return nil, err
+	}
+
+	model := ReplaceRequestModel{
+		ID:        id,
+		Version:   version,
+		SpaceShip: req.toModel(),
+	}
+
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func encodeReplaceResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res, ok := response.(ReplaceResponseModel)
+	if !ok {
+		return fmt.Errorf("encodeReplaceResponse(): failed cast response")
+	}
+
+	formatted := formatReplaceResponse(res)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", formatETag(res.Version))
 
 	return json.NewEncoder(w).Encode(formatted)
 }
 
 func decodeDeleteByIDRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
-	params := httprouter.ParamsFromContext(ctx)
+	id, err := decodeIDParam(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	idPath := params.ByName("id")
-	if idPath == ":id" || idPath == "" {
-		return nil, helpers.ErrInvalidPathParam
+	version, err := decodeIfMatch(r)
+	if err != nil {
+		return nil, err
 	}
 
-	id, err := strconv.ParseInt(idPath, 10, 64)
+	purge, err := parseBoolQuery(r.URL.Query(), "purge")
 	if err != nil {
-		return nil, helpers.ErrInvalidPathParam
+		return nil, err
+	}
+
+	if purge && !helpers.IsAdmin(ctx) {
+		return nil, helpers.NewUnauthorizedError("admin token required")
 	}
 
 	return DeleteByIDRequestModel{
-		ID: id,
+		ID:      id,
+		Version: version,
+		Purge:   purge,
 	}, nil
 }
 
@@ -219,22 +439,644 @@ func encodeDeleteByIDResponse(ctx context.Context, w http.ResponseWriter, respon
 	return json.NewEncoder(w).Encode(formatted)
 }
 
+func decodeRestoreRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	id, err := decodeIDParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return RestoreRequestModel{
+		ID: id,
+	}, nil
+}
+
+func encodeRestoreResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res, ok := response.(RestoreResponseModel)
+	if !ok {
+		return fmt.Errorf("encodeRestoreResponse(): failed cast response")
+	}
+
+	formatted := formatRestoreResponse(res)
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(formatted)
+}
+
+type bulkRequest struct {
+	Mode   string            `json:"mode" enums:"atomic,best_effort"`
+	Create []createRequest   `json:"create"`
+	Update []json.RawMessage `json:"update" swaggertype:"array,object"` // of bulkUpdateRequest
+	Delete []int64           `json:"delete"`
+}
+
+// bulkUpdateRequest is a JSON merge patch of the spaceship it names. Version
+// plays the part of the If-Match header.
+type bulkUpdateRequest struct {
+	ID      int64 `json:"id"`
+	Version uint  `json:"version"`
+	updateRequest
+}
+
+type bulkResultResponse struct {
+	Op     string           `json:"op"`
+	Index  int              `json:"index"`
+	ID     int64            `json:"id,omitempty"`
+	Status int              `json:"status"`
+	Error  *helpers.Problem `json:"error,omitempty"`
+}
+
+type bulkResponse struct {
+	Mode      string               `json:"mode"`
+	Committed bool                 `json:"committed"`
+	Results   []bulkResultResponse `json:"results"`
+}
+
+func decodeBulkRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var req bulkRequest
+	if err := helpers.DecodeJSON(r.Body, &req); err != nil {
+		return nil, err
+	}
+
+	create := make([]CreateRequestModel, len(req.Create))
+	for i, item := range req.Create {
+		create[i] = item.toModel()
+	}
+
+	// Update items are merge patches, decoded one by one to spot their nulls.
+	update := make([]UpdateRequestModel, len(req.Update))
+	for i, raw := range req.Update {
+		var item bulkUpdateRequest
+		nulls, err := helpers.DecodeMergePatchBody(raw, &item)
+		if err != nil {
+			return nil, helpers.PrefixFieldErrors(err, fmt.Sprintf("update[%d]", i))
+		}
+
+		for _, member := range nulls {
+			item.resetNull(member)
+		}
+
+		update[i] = item.toModel(item.ID, entity.MatchVersion(item.Version))
+	}
+
+	model := BulkRequestModel{
+		Mode:   req.Mode,
+		Create: create,
+		Update: update,
+		Delete: req.Delete,
+	}
+
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func encodeBulkResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res, ok := response.(BulkResponseModel)
+	if !ok {
+		return fmt.Errorf("encodeBulkResponse(): failed cast response")
+	}
+
+	formatted := formatBulkResponse(res)
+
+	status := http.StatusOK
+	for _, result := range formatted.Results {
+		if result.Error != nil {
+			status = http.StatusMultiStatus
+			break
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	return json.NewEncoder(w).Encode(formatted)
+}
+
+// staticSegments hands the requests whose path param is one of the static
+// segments over to its handler, and the others over to next.
+func staticSegments(name string, segments map[string]http.Handler, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if handler, ok := segments[httprouter.ParamsFromContext(r.Context()).ByName(name)]; ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// methodNotAllowed answers 405 the way httprouter does, listing the allowed
+// methods in the Allow header.
+func methodNotAllowed(allowed ...string) http.Handler {
+	allow := strings.Join(allowed, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", allow)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	})
+}
+
+const (
+	// exportFlushEvery is the number of spaceships sent to the client at once.
+	exportFlushEvery = 100
+
+	maxImportLines    = 10000
+	maxImportLineSize = 1 << 20
+)
+
+var errTooManyImportLines = helpers.NewBadRequestError(fmt.Sprintf("import is limited to %d lines", maxImportLines))
+
+// csvColumns is the header of CSV exports, and the columns a CSV import may
+// use, in any order.
+var csvColumns = []string{"id", "name", "class", "crew", "image", "value", "status", "armament"}
+
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"ndjson": "application/x-ndjson",
+}
+
+var importFormats = map[string]string{
+	"text/csv":             "csv",
+	"application/x-ndjson": "ndjson",
+	"application/ndjson":   "ndjson",
+}
+
+// exportRecord is a spaceship as exported, and imported back, one per line.
+// The ID is ignored on import.
+type exportRecord struct {
+	ID int64 `json:"id,omitempty"`
+	createRequest
+}
+
+type importErrorResponse struct {
+	Line  int             `json:"line"`
+	Error helpers.Problem `json:"error"`
+}
+
+type importResponse struct {
+	Created int                   `json:"created"`
+	Failed  int                   `json:"failed"`
+	Errors  []importErrorResponse `json:"errors"`
+}
+
+func decodeExportRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "ndjson"
+	}
+
+	if _, ok := exportContentTypes[format]; !ok {
+		return nil, invalidQueryParam("format")
+	}
+
+	return ExportRequestModel{
+		Format: format,
+	}, nil
+}
+
+// encodeExportResponse writes the spaceships as they are read. The status is
+// sent along with the first of them, so that a failure to read any still gets
+// a problem detail of its own. Past that point the response is aborted
+// instead, so that the client cannot mistake it for a complete export.
+func encodeExportResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res, ok := response.(ExportResponseModel)
+	if !ok {
+		return fmt.Errorf("encodeExportResponse(): failed cast response")
+	}
+
+	writer := newExportWriter(res.Format, w)
+
+	var started bool
+	start := func() error {
+		if started {
+			return nil
+		}
+
+		started = true
+		w.Header().Set("Content-Type", exportContentTypes[res.Format])
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="spaceships.%s"`, res.Format))
+		w.WriteHeader(http.StatusOK)
+
+		return writer.Begin()
+	}
+
+	flush := func() error {
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+
+		return nil
+	}
+
+	var count int
+	err := res.Export(func(spaceship entity.SpaceShip) error {
+		if err := start(); err != nil {
+			return err
+		}
+
+		if err := writer.Write(spaceship); err != nil {
+			return err
+		}
+
+		count++
+		if count%exportFlushEvery == 0 {
+			return flush()
+		}
+
+		return nil
+	})
+	if err == nil {
+		if err = start(); err == nil {
+			err = flush()
+		}
+	}
+
+	if err != nil && started {
+		level.Error(logging.FromContext(ctx, log.NewNopLogger())).Log("msg", "spaceship.encodeExportResponse(): aborting export already under way", "exported", count, "err", err)
+		panic(http.ErrAbortHandler)
+	}
+
+	return err
+}
+
+// exportWriter writes spaceships in one of the export formats. Begin writes
+// what comes ahead of the first spaceship.
+type exportWriter interface {
+	Begin() error
+	Write(spaceship entity.SpaceShip) error
+	Flush() error
+}
+
+func newExportWriter(format string, w io.Writer) exportWriter {
+	if format == "csv" {
+		return csvExportWriter{writer: csv.NewWriter(w)}
+	}
+
+	buffer := bufio.NewWriter(w)
+	return ndjsonExportWriter{buffer: buffer, encoder: json.NewEncoder(buffer)}
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+func (e csvExportWriter) Begin() error {
+	return e.writer.Write(csvColumns)
+}
+
+func (e csvExportWriter) Write(spaceship entity.SpaceShip) error {
+	return e.writer.Write(formatCSVRecord(spaceship))
+}
+
+func (e csvExportWriter) Flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+type ndjsonExportWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (e ndjsonExportWriter) Begin() error {
+	return nil
+}
+
+func (e ndjsonExportWriter) Write(spaceship entity.SpaceShip) error {
+	return e.encoder.Encode(formatExportRecord(spaceship))
+}
+
+func (e ndjsonExportWriter) Flush() error {
+	return e.buffer.Flush()
+}
+
+// decodeImportRequest reads an import file in the format named by the format
+// query param, or else by the Content-Type. Lines are validated one by one,
+// only a file which cannot be read as a whole is rejected.
+func decodeImportRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	format := r.URL.Query().Get("format")
+	if format != "" {
+		if _, ok := exportContentTypes[format]; !ok {
+			return nil, invalidQueryParam("format")
+		}
+	} else {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		format = importFormats[mediaType]
+	}
+
+	var lines []ImportLineModel
+	switch format {
+	case "csv":
+		lines, err = decodeCSVImport(r.Body)
+	case "ndjson":
+		lines, err = decodeNDJSONImport(r.Body)
+	default:
+		return nil, &helpers.Error{Kind: helpers.ErrUnsupportedMediaType, Message: "body must be text/csv or application/x-ndjson"}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ImportRequestModel{
+		Lines: lines,
+	}, nil
+}
+
+// decodeCSVImport reads a CSV file whose first line names its columns.
+func decodeCSVImport(body io.Reader) ([]ImportLineModel, error) {
+	reader := csv.NewReader(body)
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, &helpers.Error{Kind: helpers.ErrBadRequest, Message: "malformed CSV: " + err.Error(), Err: err}
+	}
+
+	for _, column := range header {
+		if !slices.Contains(csvColumns, column) {
+			return nil, helpers.NewBadRequestError(fmt.Sprintf("unknown CSV column %q", column))
+		}
+	}
+
+	var lines []ImportLineModel
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return lines, nil
+		}
+
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr) && errors.Is(err, csv.ErrFieldCount):
+			lines = append(lines, ImportLineModel{
+				Line: parseErr.StartLine,
+				Err:  helpers.NewBadRequestError(fmt.Sprintf("line has %d fields, the header %d", len(record), len(header))),
+			})
+		case err != nil:
+			return nil, &helpers.Error{Kind: helpers.ErrBadRequest, Message: "malformed CSV: " + err.Error(), Err: err}
+		default:
+			line, _ := reader.FieldPos(0)
+			lines = append(lines, decodeCSVLine(line, header, record))
+		}
+
+		if len(lines) > maxImportLines {
+			return nil, errTooManyImportLines
+		}
+	}
+}
+
+// decodeCSVLine reads a spaceship from a CSV record. Values of the wrong type
+// are reported along with the validation errors.
+func decodeCSVLine(line int, header []string, record []string) ImportLineModel {
+	var req CreateRequestModel
+	var fields []helpers.FieldError
+
+	for i, column := range header {
+		value := record[i]
+
+		switch column {
+		case "name":
+			req.Name = value
+		case "class":
+			req.Class = value
+		case "crew":
+			crew, err := strconv.ParseInt(value, 10, 64)
+			if err != nil && value != "" {
+				fields = append(fields, helpers.FieldError{Field: column, Message: "must be an integer"})
+			}
+
+			req.Crew = crew
+		case "image":
+			req.Image = value
+		case "value":
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil && value != "" {
+				fields = append(fields, helpers.FieldError{Field: column, Message: "must be a number"})
+			}
+
+			req.Value = number
+		case "status":
+			req.Status = value
+		case "armament":
+			armaments, ok := parseCSVArmaments(value)
+			if !ok {
+				fields = append(fields, helpers.FieldError{Field: column, Message: "must be weapon_id:qty pairs separated by semicolons"})
+			}
+
+			req.Armaments = armaments
+		}
+	}
+
+	return newImportLine(line, req, fields)
+}
+
+func parseCSVArmaments(value string) ([]armamentReqModel, bool) {
+	if strings.TrimSpace(value) == "" {
+		return nil, true
+	}
+
+	pairs := strings.Split(value, ";")
+	armaments := make([]armamentReqModel, len(pairs))
+	for i, pair := range pairs {
+		weaponID, qty, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found {
+			return nil, false
+		}
+
+		id, err := strconv.ParseUint(weaponID, 10, 0)
+		if err != nil {
+			return nil, false
+		}
+
+		n, err := strconv.Atoi(qty)
+		if err != nil {
+			return nil, false
+		}
+
+		armaments[i] = armamentReqModel{WeaponID: uint(id), Qty: n}
+	}
+
+	return armaments, true
+}
+
+// decodeNDJSONImport reads a file of JSON objects, one per line. Blank lines
+// are skipped.
+func decodeNDJSONImport(body io.Reader) ([]ImportLineModel, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(nil, maxImportLineSize)
+
+	var lines []ImportLineModel
+	var line int
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var req exportRecord
+		if err := helpers.DecodeJSON(bytes.NewReader(scanner.Bytes()), &req); err != nil {
+			lines = append(lines, ImportLineModel{Line: line, Err: err})
+		} else {
+			lines = append(lines, newImportLine(line, req.toModel(), nil))
+		}
+
+		if len(lines) > maxImportLines {
+			return nil, errTooManyImportLines
+		}
+	}
+
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		return nil, helpers.NewBadRequestError(fmt.Sprintf("line %d is longer than %d bytes", line+1, maxImportLineSize))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, &helpers.Error{Kind: helpers.ErrBadRequest, Message: "unreadable body", Err: err}
+	}
+
+	return lines, nil
+}
+
+// newImportLine validates a spaceship read from an import file, adding the
+// errors already found while reading it.
+func newImportLine(line int, req CreateRequestModel, fields []helpers.FieldError) ImportLineModel {
+	err := req.Validate()
+
+	var domainErr *helpers.Error
+	if err != nil && (!errors.As(err, &domainErr) || !errors.Is(err, helpers.ErrValidation)) {
+		return ImportLineModel{Line: line, Err: err}
+	}
+
+	if err != nil {
+		fields = append(fields, domainErr.Fields...)
+	}
+
+	if len(fields) > 0 {
+		return ImportLineModel{Line: line, Err: helpers.NewValidationError("request validation failed", fields...)}
+	}
+
+	return ImportLineModel{Line: line, SpaceShip: req}
+}
+
+func encodeImportResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res, ok := response.(ImportResponseModel)
+	if !ok {
+		return fmt.Errorf("encodeImportResponse(): failed cast response")
+	}
+
+	formatted := formatImportResponse(res)
+
+	status := http.StatusOK
+	if formatted.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	return json.NewEncoder(w).Encode(formatted)
+}
+
 type spaceShipResponse struct {
-	ID     int64  `json:"id"`
-	Name   string `json:"name"`
-	Status string `json:"status"`
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Status    string     `json:"status"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type getAllResponse struct {
+	Data       []spaceShipResponse `json:"data"`
+	NextCursor string              `json:"next_cursor"`
+	HasMore    bool                `json:"has_more"`
+	Total      *int64              `json:"total,omitempty"`
 }
 
 func decodeGetAllRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
 	queryValues := r.URL.Query()
-	name := queryValues.Get("name")
-	class := queryValues.Get("class")
-	status := queryValues.Get("status")
+
+	minCrew, err := parseIntQuery(queryValues, "min_crew")
+	if err != nil {
+		return nil, err
+	}
+
+	maxCrew, err := parseIntQuery(queryValues, "max_crew")
+	if err != nil {
+		return nil, err
+	}
+
+	minValue, err := parseFloatQuery(queryValues, "min_value")
+	if err != nil {
+		return nil, err
+	}
+
+	maxValue, err := parseFloatQuery(queryValues, "max_value")
+	if err != nil {
+		return nil, err
+	}
+
+	sort, err := parseSortQuery(queryValues, "sort")
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int64(entity.DefaultListLimit)
+	if queryValues.Get("limit") != "" {
+		value, err := parseIntQuery(queryValues, "limit")
+		if err != nil || *value < 1 || *value > entity.MaxListLimit {
+			return nil, invalidQueryParam("limit")
+		}
+		limit = *value
+	}
+
+	var offset int64
+	if queryValues.Get("offset") != "" {
+		value, err := parseIntQuery(queryValues, "offset")
+		if err != nil || *value < 0 {
+			return nil, invalidQueryParam("offset")
+		}
+		offset = *value
+	}
+
+	cursor := queryValues.Get("cursor")
+	if cursor != "" && offset > 0 {
+		return nil, &helpers.Error{Kind: helpers.ErrInvalidQueryParam, Message: "cursor and offset cannot be combined"}
+	}
+
+	withTotal, err := parseBoolQuery(queryValues, "include_total")
+	if err != nil {
+		return nil, err
+	}
+
+	includeDeleted, err := parseBoolQuery(queryValues, "include_deleted")
+	if err != nil {
+		return nil, err
+	}
 
 	return GetAllRequestModel{
-		Name:   name,
-		Class:  class,
-		Status: status,
+		Name:     queryValues.Get("name"),
+		Classes:  parseListQuery(queryValues, "class"),
+		Statuses: parseListQuery(queryValues, "status"),
+		MinCrew:  minCrew,
+		MaxCrew:  maxCrew,
+		MinValue: minValue,
+		MaxValue: maxValue,
+
+		IncludeDeleted: includeDeleted,
+
+		Sort:      sort,
+		Limit:     int(limit),
+		Offset:    int(offset),
+		Cursor:    cursor,
+		WithTotal: withTotal,
 	}, nil
 }
 
@@ -248,3 +1090,315 @@ func encodeGetAllResponse(ctx context.Context, w http.ResponseWriter, response i
 	w.Header().Set("Content-Type", "application/json")
 	return json.NewEncoder(w).Encode(formatted)
 }
+
+type updateArmamentRequest struct {
+	WeaponID *uint `json:"weapon_id"`
+	Qty      *int  `json:"qty"`
+}
+
+func decodeGetArmamentsRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	id, err := decodeIDParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetArmamentsRequestModel{
+		SpaceShipID: id,
+	}, nil
+}
+
+func encodeGetArmamentsResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res, ok := response.(GetArmamentsResponseModel)
+	if !ok {
+		return fmt.Errorf("encodeGetArmamentsResponse(): failed cast response")
+	}
+
+	formatted := formatGetArmamentsResponse(res)
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(formatted)
+}
+
+func decodeCreateArmamentRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	id, err := decodeIDParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req armamentReq
+	if err := helpers.DecodeJSON(r.Body, &req); err != nil {
+		return nil, err
+	}
+
+	model := CreateArmamentRequestModel{
+		SpaceShipID: id,
+		Armament:    armamentReqModel(req),
+	}
+
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func encodeCreateArmamentResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res, ok := response.(CreateArmamentResponseModel)
+	if !ok {
+		return fmt.Errorf("encodeCreateArmamentResponse(): failed cast response")
+	}
+
+	formatted := formatCreateArmamentResponse(res)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	return json.NewEncoder(w).Encode(formatted)
+}
+
+func decodeUpdateArmamentRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	id, err := decodeIDParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	armamentID, err := decodeArmamentIDParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req updateArmamentRequest
+	nulls, err := helpers.DecodeMergePatch(r, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every armament field is required, so removing one resets it to an
+	// invalid zero value.
+	for _, member := range nulls {
+		switch member {
+		case "weapon_id":
+			req.WeaponID = new(uint)
+		case "qty":
+			req.Qty = new(int)
+		}
+	}
+
+	model := UpdateArmamentRequestModel{
+		SpaceShipID: id,
+		ArmamentID:  armamentID,
+		WeaponID:    req.WeaponID,
+		Qty:         req.Qty,
+	}
+
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func encodeUpdateArmamentResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res, ok := response.(UpdateArmamentResponseModel)
+	if !ok {
+		return fmt.Errorf("encodeUpdateArmamentResponse(): failed cast response")
+	}
+
+	formatted := formatUpdateArmamentResponse(res)
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(formatted)
+}
+
+func decodeDeleteArmamentRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	id, err := decodeIDParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	armamentID, err := decodeArmamentIDParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeleteArmamentRequestModel{
+		SpaceShipID: id,
+		ArmamentID:  armamentID,
+	}, nil
+}
+
+func encodeDeleteArmamentResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res, ok := response.(DeleteArmamentResponseModel)
+	if !ok {
+		return fmt.Errorf("encodeDeleteArmamentResponse(): failed cast response")
+	}
+
+	formatted := formatDeleteArmamentResponse(res)
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(formatted)
+}
+
+func decodeIDParam(ctx context.Context) (int64, error) {
+	return decodeIntParam(ctx, "id")
+}
+
+func decodeArmamentIDParam(ctx context.Context) (int64, error) {
+	return decodeIntParam(ctx, "armamentId")
+}
+
+func decodeIntParam(ctx context.Context, name string) (int64, error) {
+	params := httprouter.ParamsFromContext(ctx)
+
+	idPath := params.ByName(name)
+	if idPath == ":"+name || idPath == "" {
+		return 0, helpers.ErrInvalidPathParam
+	}
+
+	id, err := strconv.ParseInt(idPath, 10, 64)
+	if err != nil {
+		return 0, helpers.ErrInvalidPathParam
+	}
+
+	return id, nil
+}
+
+// formatETag turns a spaceship version into a strong entity tag.
+func formatETag(version uint) string {
+	return strconv.Quote(strconv.FormatUint(uint64(version), 10))
+}
+
+// decodeIfMatch returns the spaceship versions the If-Match header expects,
+// one for each entity tag of its list, or any existing one for "*". If-Match
+// uses the strong comparison, so weak or foreign entity tags can never match:
+// a list made of them only fails right away.
+func decodeIfMatch(r *http.Request) (entity.VersionMatch, error) {
+	var match entity.VersionMatch
+
+	var tags []string
+	for _, value := range r.Header.Values("If-Match") {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	if len(tags) == 0 {
+		return match, nil
+	}
+
+	for _, tag := range tags {
+		if tag == "*" {
+			return entity.VersionMatch{Any: true}, nil
+		}
+
+		// Weak tags start with W/, and formatETag only quotes digits.
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+
+		version, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 0)
+		if err != nil || version == 0 {
+			continue
+		}
+
+		match.Versions = append(match.Versions, uint(version))
+	}
+
+	if len(match.Versions) == 0 {
+		return match, errVersionMismatch
+	}
+
+	return match, nil
+}
+
+func invalidQueryParam(key string) error {
+	return &helpers.Error{Kind: helpers.ErrInvalidQueryParam, Message: fmt.Sprintf("invalid value for query param %q", key)}
+}
+
+// parseListQuery collects every value of a query param, accepting both
+// repeated params (?class=a&class=b) and comma separated ones (?class=a,b).
+func parseListQuery(values url.Values, key string) []string {
+	var list []string
+	for _, value := range values[key] {
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+
+	return list
+}
+
+func parseIntQuery(values url.Values, key string) (*int64, error) {
+	raw := values.Get(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, invalidQueryParam(key)
+	}
+
+	return &value, nil
+}
+
+func parseFloatQuery(values url.Values, key string) (*float64, error) {
+	raw := values.Get(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, invalidQueryParam(key)
+	}
+
+	return &value, nil
+}
+
+func parseBoolQuery(values url.Values, key string) (bool, error) {
+	raw := values.Get(key)
+	if raw == "" {
+		return false, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, invalidQueryParam(key)
+	}
+
+	return value, nil
+}
+
+// parseSortQuery reads a sort spec such as "-value,name", where a leading "-"
+// sorts the field in descending order. Field names are checked against the
+// whitelist by the repository.
+func parseSortQuery(values url.Values, key string) ([]entity.SortField, error) {
+	raw := values.Get(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var fields []entity.SortField
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+
+		field := entity.SortField{Field: strings.TrimPrefix(item, "+")}
+		if strings.HasPrefix(item, "-") {
+			field = entity.SortField{Field: strings.TrimPrefix(item, "-"), Desc: true}
+		}
+
+		if field.Field == "" {
+			return nil, invalidQueryParam(key)
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
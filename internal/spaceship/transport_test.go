@@ -0,0 +1,156 @@
+package spaceship
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+)
+
+func TestRegisterRoutes_StaticSegments(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		body      string
+		wantCode  int
+		wantAllow string
+	}{
+		{
+			name:     "Given POST to the bulk segment, should be served by the bulk route",
+			method:   http.MethodPost,
+			path:     "/spaceship/bulk",
+			body:     "{",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Given POST to the import segment, should be served by the import route",
+			method:   http.MethodPost,
+			path:     "/spaceship/import?format=xml",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:      "Given POST to a spaceship ID, should return method not allowed like the router does",
+			method:    http.MethodPost,
+			path:      "/spaceship/42",
+			wantCode:  http.StatusMethodNotAllowed,
+			wantAllow: "DELETE, GET, OPTIONS, PATCH, PUT",
+		},
+		{
+			name:      "Given POST to an unknown segment, should return method not allowed like the router does",
+			method:    http.MethodPost,
+			path:      "/spaceship/export",
+			wantCode:  http.StatusMethodNotAllowed,
+			wantAllow: "DELETE, GET, OPTIONS, PATCH, PUT",
+		},
+	}
+
+	router := httprouter.New()
+	RegisterRoutes(router, nil, "", nil, nil)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantAllow, w.Header().Get("Allow"))
+		})
+	}
+}
+
+func TestDecodeIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  []string
+		want    entity.VersionMatch
+		wantErr error
+	}{
+		{
+			name: "Given no header, should return no precondition",
+			want: entity.VersionMatch{},
+		},
+		{
+			name:   "Given a single entity tag, should return its version",
+			header: []string{`"4"`},
+			want:   entity.VersionMatch{Versions: []uint{4}},
+		},
+		{
+			name:   "Given a list of entity tags, should return every version",
+			header: []string{`"3", "4"`, `"7"`},
+			want:   entity.VersionMatch{Versions: []uint{3, 4, 7}},
+		},
+		{
+			name:   "Given a wildcard, should only require the spaceship to exist",
+			header: []string{"*"},
+			want:   entity.VersionMatch{Any: true},
+		},
+		{
+			name:   "Given weak and foreign entity tags along with a valid one, should skip them",
+			header: []string{`W/"3", "abc", "4"`},
+			want:   entity.VersionMatch{Versions: []uint{4}},
+		},
+		{
+			name:    "Given only entity tags that can never match, should return precondition failed error",
+			header:  []string{`W/"3", "abc", 4`},
+			want:    entity.VersionMatch{},
+			wantErr: errVersionMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodDelete, "/spaceship/2", nil)
+			for _, value := range tt.header {
+				r.Header.Add("If-Match", value)
+			}
+
+			got, err := decodeIfMatch(r)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestEncodeUpdateAndReplaceResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		encode   func(w http.ResponseWriter) error
+		wantBody string
+	}{
+		{
+			name: "Given updated spaceship, should return its new version as entity tag",
+			encode: func(w http.ResponseWriter) error {
+				return encodeUpdateResponse(context.Background(), w, UpdateResponseModel{Success: true, Version: 5})
+			},
+			wantBody: `{"success":true}`,
+		},
+		{
+			name: "Given replaced spaceship, should return its new version as entity tag",
+			encode: func(w http.ResponseWriter) error {
+				return encodeReplaceResponse(context.Background(), w, ReplaceResponseModel{Success: true, Version: 5})
+			},
+			wantBody: `{"success":true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			err := tt.encode(w)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, `"5"`, w.Header().Get("ETag"))
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
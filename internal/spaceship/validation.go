@@ -0,0 +1,17 @@
+package spaceship
+
+import (
+	"github.com/wndisra/galactic-svc/internal/entity"
+	"github.com/wndisra/galactic-svc/internal/helpers"
+)
+
+var validate = newValidator()
+
+func newValidator() *helpers.Validator {
+	v := helpers.NewValidator()
+	v.RegisterEnum("spaceship_class", entity.SpaceShipClasses...)
+	v.RegisterEnum("spaceship_status", entity.SpaceShipStatuses...)
+	v.RegisterEnum("bulk_mode", entity.BulkModes...)
+
+	return v
+}
@@ -0,0 +1,355 @@
+package spaceship
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wndisra/galactic-svc/internal/helpers"
+)
+
+func TestCreateRequestModel_Validate(t *testing.T) {
+	valid := CreateRequestModel{
+		Name:   "Devastator",
+		Class:  "Star Destroyer",
+		Crew:   1200,
+		Image:  "https://test",
+		Value:  100.99,
+		Status: "Operational",
+		Armaments: []armamentReqModel{
+			{
+				WeaponID: 1,
+				Qty:      60,
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		req        func() CreateRequestModel
+		wantFields []helpers.FieldError
+	}{
+		{
+			name:       "Given valid request, should return nil error",
+			req:        func() CreateRequestModel { return valid },
+			wantFields: nil,
+		},
+		{
+			name: "Given empty request, should report every required field",
+			req:  func() CreateRequestModel { return CreateRequestModel{} },
+			wantFields: []helpers.FieldError{
+				{Field: "name", Message: "is required"},
+				{Field: "class", Message: "is required"},
+				{Field: "status", Message: "is required"},
+			},
+		},
+		{
+			name: "Given out of range values, should report each of them",
+			req: func() CreateRequestModel {
+				req := valid
+				req.Name = strings.Repeat("a", 101)
+				req.Class = "Death Star"
+				req.Crew = -1
+				req.Image = "ftp://test"
+				req.Value = -0.5
+				req.Status = "Lost"
+				req.Armaments = []armamentReqModel{{WeaponID: 1, Qty: 0}}
+				return req
+			},
+			wantFields: []helpers.FieldError{
+				{Field: "name", Message: "must be at most 100 characters long"},
+				{Field: "class", Message: "must be one of: Star Destroyer, Dreadnought, Cruiser, Frigate, Corvette, Freighter, Transport, Starfighter, Shuttle"},
+				{Field: "crew", Message: "must be 0 or greater"},
+				{Field: "image", Message: "must be a valid http or https URL"},
+				{Field: "value", Message: "must be 0 or greater"},
+				{Field: "status", Message: "must be one of: Operational, Damaged, Under Repair, Destroyed, Decommissioned"},
+				{Field: "armament[0].qty", Message: "must be 1 or greater"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req().Validate()
+			if tt.wantFields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var domainErr *helpers.Error
+			assert.True(t, errors.As(err, &domainErr))
+			assert.ErrorIs(t, err, helpers.ErrValidation)
+			assert.Equal(t, tt.wantFields, domainErr.Fields)
+		})
+	}
+}
+
+func TestUpdateRequestModel_Validate(t *testing.T) {
+	empty := ""
+	crew := int64(0)
+	status := "Lost"
+
+	tests := []struct {
+		name       string
+		req        UpdateRequestModel
+		wantFields []helpers.FieldError
+	}{
+		{
+			name:       "Given empty patch, should return nil error",
+			req:        UpdateRequestModel{ID: 2},
+			wantFields: nil,
+		},
+		{
+			name:       "Given valid zero values, should return nil error",
+			req:        UpdateRequestModel{ID: 2, Crew: &crew, Image: &empty},
+			wantFields: nil,
+		},
+		{
+			name: "Given invalid fields, should only report the patched ones",
+			req: UpdateRequestModel{
+				ID:        2,
+				Name:      &empty,
+				Status:    &status,
+				Armaments: &[]armamentReqModel{{WeaponID: 0, Qty: 1}},
+			},
+			wantFields: []helpers.FieldError{
+				{Field: "name", Message: "is required"},
+				{Field: "status", Message: "must be one of: Operational, Damaged, Under Repair, Destroyed, Decommissioned"},
+				{Field: "armament[0].weapon_id", Message: "is required"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantFields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var domainErr *helpers.Error
+			assert.True(t, errors.As(err, &domainErr))
+			assert.ErrorIs(t, err, helpers.ErrValidation)
+			assert.Equal(t, tt.wantFields, domainErr.Fields)
+		})
+	}
+}
+
+func TestBulkRequestModel_Validate(t *testing.T) {
+	empty := ""
+
+	valid := CreateRequestModel{
+		Name:   "Devastator",
+		Class:  "Star Destroyer",
+		Status: "Operational",
+	}
+
+	tests := []struct {
+		name       string
+		req        BulkRequestModel
+		wantFields []helpers.FieldError
+	}{
+		{
+			name: "Given valid request, should return nil error",
+			req: BulkRequestModel{
+				Mode:   "best_effort",
+				Create: []CreateRequestModel{valid},
+				Update: []UpdateRequestModel{{ID: 2}},
+				Delete: []int64{3},
+			},
+			wantFields: nil,
+		},
+		{
+			name: "Given invalid items, should report each of them under its index",
+			req: BulkRequestModel{
+				Mode:   "whatever",
+				Create: []CreateRequestModel{valid, {Name: "Executor", Class: "Dreadnought"}},
+				Update: []UpdateRequestModel{{ID: 0, Name: &empty}},
+				Delete: []int64{3, 0},
+			},
+			wantFields: []helpers.FieldError{
+				{Field: "mode", Message: "must be one of: atomic, best_effort"},
+				{Field: "delete[1]", Message: "must be 1 or greater"},
+				{Field: "create[1].status", Message: "is required"},
+				{Field: "update[0].id", Message: "must be 1 or greater"},
+				{Field: "update[0].name", Message: "is required"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantFields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var domainErr *helpers.Error
+			assert.True(t, errors.As(err, &domainErr))
+			assert.ErrorIs(t, err, helpers.ErrValidation)
+			assert.Equal(t, tt.wantFields, domainErr.Fields)
+		})
+	}
+}
+
+func TestDecodeImportRequest(t *testing.T) {
+	devastator := CreateRequestModel{
+		Name:      "Devastator",
+		Class:     "Star Destroyer",
+		Crew:      1200,
+		Value:     100.99,
+		Status:    "Operational",
+		Armaments: []armamentReqModel{{WeaponID: 1, Qty: 60}, {WeaponID: 2, Qty: 10}},
+	}
+
+	type wantLine struct {
+		line      int
+		spaceship CreateRequestModel
+		fields    []helpers.FieldError
+		kind      error
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		query       string
+		body        string
+		want        []wantLine
+		wantErr     error
+	}{
+		{
+			name:        "Given CSV file, should read each line and report its errors",
+			contentType: "text/csv",
+			body: "id,name,class,crew,value,status,armament\n" +
+				"7,Devastator,Star Destroyer,1200,100.99,Operational,1:60;2:10\n" +
+				"8,Executor,,many,,Operational,laser\n" +
+				"9,Tantive IV\n",
+			want: []wantLine{
+				{line: 2, spaceship: devastator},
+				{line: 3, fields: []helpers.FieldError{
+					{Field: "crew", Message: "must be an integer"},
+					{Field: "armament", Message: "must be weapon_id:qty pairs separated by semicolons"},
+					{Field: "class", Message: "is required"},
+				}},
+				{line: 4, kind: helpers.ErrBadRequest},
+			},
+		},
+		{
+			name:        "Given NDJSON file, should read each line and report its errors",
+			contentType: "application/x-ndjson",
+			body: `{"id":7,"name":"Devastator","class":"Star Destroyer","crew":1200,"value":100.99,"status":"Operational","armament":[{"weapon_id":1,"qty":60},{"weapon_id":2,"qty":10}]}` + "\n" +
+				"\n" +
+				`{"name":"Executor","class":"Star Destroyer","status":"Operational","speed":12}` + "\n" +
+				`{"name":"Executor","class":"Star Destroyer","status":"Lost"}` + "\n" +
+				`{"name":`,
+			want: []wantLine{
+				{line: 1, spaceship: devastator},
+				{line: 3, fields: []helpers.FieldError{{Field: "speed", Message: "is not a known field"}}},
+				{line: 4, fields: []helpers.FieldError{{Field: "status", Message: "must be one of: Operational, Damaged, Under Repair, Destroyed, Decommissioned"}}},
+				{line: 5, kind: helpers.ErrBadRequest},
+			},
+		},
+		{
+			name:  "Given format query param, should override the Content-Type",
+			query: "?format=csv",
+			body:  "name\n",
+			want:  nil,
+		},
+		{
+			name:        "Given unknown CSV column, should reject the file",
+			contentType: "text/csv",
+			body:        "name,speed\nDevastator,12\n",
+			wantErr:     helpers.ErrBadRequest,
+		},
+		{
+			name:    "Given unknown format, should return invalid query param error",
+			query:   "?format=xml",
+			wantErr: helpers.ErrInvalidQueryParam,
+		},
+		{
+			name:        "Given unsupported Content-Type, should return unsupported media type error",
+			contentType: "application/json",
+			wantErr:     helpers.ErrUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/spaceship/import"+tt.query, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+
+			req, err := decodeImportRequest(context.Background(), r)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			lines := req.(ImportRequestModel).Lines
+			assert.Len(t, lines, len(tt.want))
+
+			for i, want := range tt.want {
+				assert.Equal(t, want.line, lines[i].Line)
+
+				switch {
+				case want.kind != nil:
+					assert.ErrorIs(t, lines[i].Err, want.kind)
+				case want.fields != nil:
+					var domainErr *helpers.Error
+					assert.True(t, errors.As(lines[i].Err, &domainErr))
+					assert.Equal(t, want.fields, domainErr.Fields)
+				default:
+					assert.NoError(t, lines[i].Err)
+					assert.Equal(t, want.spaceship, lines[i].SpaceShip)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateArmamentRequestModel_Validate(t *testing.T) {
+	weaponID := uint(0)
+	qty := 0
+
+	tests := []struct {
+		name       string
+		req        UpdateArmamentRequestModel
+		wantFields []helpers.FieldError
+	}{
+		{
+			name:       "Given empty patch, should return nil error",
+			req:        UpdateArmamentRequestModel{SpaceShipID: 2, ArmamentID: 5},
+			wantFields: nil,
+		},
+		{
+			name: "Given invalid fields, should report each of them",
+			req:  UpdateArmamentRequestModel{SpaceShipID: 2, ArmamentID: 5, WeaponID: &weaponID, Qty: &qty},
+			wantFields: []helpers.FieldError{
+				{Field: "weapon_id", Message: "is required"},
+				{Field: "qty", Message: "must be 1 or greater"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantFields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var domainErr *helpers.Error
+			assert.True(t, errors.As(err, &domainErr))
+			assert.ErrorIs(t, err, helpers.ErrValidation)
+			assert.Equal(t, tt.wantFields, domainErr.Fields)
+		})
+	}
+}
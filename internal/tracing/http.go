@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	ht "github.com/go-kit/kit/transport/http"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type spanKey struct{}
+
+// ServerOptions trace the requests served by a go-kit handler in a span
+// called name. The span continues the trace of the traceparent header, if
+// any, and ends once the response is written.
+func ServerOptions(name string) []ht.ServerOption {
+	return []ht.ServerOption{
+		ht.ServerBefore(startSpan(name)),
+		ht.ServerFinalizer(endSpan),
+	}
+}
+
+func startSpan(name string) ht.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethod(r.Method),
+				semconv.URLPath(r.URL.Path),
+				semconv.UserAgentOriginal(r.UserAgent()),
+			),
+		)
+
+		return context.WithValue(ctx, spanKey{}, span)
+	}
+}
+
+func endSpan(ctx context.Context, code int, r *http.Request) {
+	span, ok := ctx.Value(spanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCode(code))
+	if code >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(code))
+	}
+
+	span.End()
+}
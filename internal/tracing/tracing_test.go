@@ -0,0 +1,259 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	ht "github.com/go-kit/kit/transport/http"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/wndisra/galactic-svc/internal/config"
+	"github.com/wndisra/galactic-svc/internal/helpers"
+)
+
+// setupExporter records the spans of the test in memory, and returns a
+// function flushing and listing them.
+func setupExporter(t *testing.T) func() tracetest.SpanStubs {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewProvider(exporter, 1)
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previous)
+	})
+
+	return func() tracetest.SpanStubs {
+		provider.ForceFlush(context.Background())
+		return exporter.GetSpans()
+	}
+}
+
+func setupMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{Conn: db, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gormDB.Use(GormPlugin()); err != nil {
+		t.Fatal(err)
+	}
+
+	return gormDB, mock
+}
+
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+
+	return attrs
+}
+
+func TestServerOptions(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+
+	tests := []struct {
+		name        string
+		traceparent string
+		queryErr    error
+		wantCode    int
+		wantStatus  codes.Code
+	}{
+		{
+			name:        "Given a traceparent header, should continue its trace down to the queries",
+			traceparent: "00-" + traceID + "-" + spanID + "-01",
+			wantCode:    http.StatusOK,
+			wantStatus:  codes.Unset,
+		},
+		{
+			name:       "Given no traceparent header, should start a trace",
+			wantCode:   http.StatusOK,
+			wantStatus: codes.Unset,
+		},
+		{
+			name:       "Got error from the database, should fail the spans",
+			queryErr:   assert.AnError,
+			wantCode:   http.StatusInternalServerError,
+			wantStatus: codes.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := setupExporter(t)
+			db, mock := setupMockDB(t)
+
+			query := mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `space_ships` WHERE crew > ?")).WithArgs(10)
+			if tt.queryErr != nil {
+				query.WillReturnError(tt.queryErr)
+			} else {
+				query.WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+			}
+
+			handler := ht.NewServer(
+				func(ctx context.Context, request interface{}) (interface{}, error) {
+					var count int64
+					err := db.WithContext(ctx).Table("space_ships").Where("crew > ?", 10).Count(&count).Error
+					return count, err
+				},
+				func(ctx context.Context, r *http.Request) (interface{}, error) { return nil, nil },
+				ht.EncodeJSONResponse,
+				append(ServerOptions("spaceship.count"), ht.ServerErrorEncoder(helpers.EncodeError))...,
+			)
+
+			r := httptest.NewRequest(http.MethodGet, "/spaceship/count", nil)
+			if tt.traceparent != "" {
+				r.Header.Set("traceparent", tt.traceparent)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tt.wantCode, w.Code)
+
+			spans := recorded()
+			if !assert.Len(t, spans, 2) {
+				return
+			}
+
+			querySpan, serverSpan := spans[0], spans[1]
+
+			assert.Equal(t, "spaceship.count", serverSpan.Name)
+			assert.Equal(t, trace.SpanKindServer, serverSpan.SpanKind)
+			assert.Equal(t, tt.wantStatus, serverSpan.Status.Code)
+			assert.Equal(t, int64(tt.wantCode), attributes(serverSpan)["http.status_code"].AsInt64())
+			if tt.traceparent != "" {
+				assert.Equal(t, traceID, serverSpan.SpanContext.TraceID().String())
+				assert.Equal(t, spanID, serverSpan.Parent.SpanID().String())
+				assert.True(t, serverSpan.Parent.IsRemote())
+			} else {
+				assert.False(t, serverSpan.Parent.IsValid())
+			}
+
+			assert.Equal(t, "db.query", querySpan.Name)
+			assert.Equal(t, trace.SpanKindClient, querySpan.SpanKind)
+			assert.Equal(t, serverSpan.SpanContext.SpanID(), querySpan.Parent.SpanID())
+			assert.Equal(t, serverSpan.SpanContext.TraceID(), querySpan.SpanContext.TraceID())
+			assert.Equal(t, tt.wantStatus, querySpan.Status.Code)
+
+			attrs := attributes(querySpan)
+			assert.Equal(t, "space_ships", attrs["db.sql.table"].AsString())
+			assert.Equal(t, "SELECT count(*) FROM `space_ships` WHERE crew > ?", attrs["db.statement"].AsString())
+		})
+	}
+}
+
+func TestGormPlugin_RecordNotFound(t *testing.T) {
+	recorded := setupExporter(t)
+	db, mock := setupMockDB(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `space_ships` WHERE id = ?")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	var row struct{ ID int64 }
+	err := db.WithContext(context.Background()).Table("space_ships").Where("id = ?", 1).Take(&row).Error
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	if spans := recorded(); assert.Len(t, spans, 1) {
+		span := spans[0]
+		assert.Equal(t, codes.Unset, span.Status.Code)
+		assert.Empty(t, span.Events)
+	}
+}
+
+func TestEnd(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus codes.Code
+		wantEvents int
+	}{
+		{
+			name:       "Given no error, should leave the status unset",
+			wantStatus: codes.Unset,
+		},
+		{
+			name:       "Given a client error, should record it without failing the span",
+			err:        helpers.NewNotFoundError("spaceship not found"),
+			wantStatus: codes.Unset,
+			wantEvents: 1,
+		},
+		{
+			name:       "Given a server error, should record it and fail the span",
+			err:        errors.New("connection refused"),
+			wantStatus: codes.Error,
+			wantEvents: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := setupExporter(t)
+
+			_, span := Start(context.Background(), "spaceship.Service.GetByID", attribute.Int64("spaceship.id", 1))
+			End(span, tt.err)
+
+			spans := recorded()
+			if assert.Len(t, spans, 1) {
+				assert.Equal(t, tt.wantStatus, spans[0].Status.Code)
+				assert.Len(t, spans[0].Events, tt.wantEvents)
+				assert.Equal(t, int64(1), attributes(spans[0])["spaceship.id"].AsInt64())
+			}
+		})
+	}
+}
+
+func TestNewExporter(t *testing.T) {
+	tests := []struct {
+		name     string
+		exporter string
+		wantNil  bool
+		wantErr  bool
+	}{
+		{name: "Given none, should return no exporter", exporter: "none", wantNil: true},
+		{name: "Given stdout, should return an exporter", exporter: "stdout"},
+		{name: "Given otlp, should return an exporter", exporter: "otlp"},
+		{name: "Given an unknown exporter, should return non-nil error", exporter: "zipkin", wantNil: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default().Tracing
+			cfg.Exporter = tt.exporter
+
+			got, err := NewExporter(context.Background(), cfg)
+
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantNil, got == nil)
+		})
+	}
+}
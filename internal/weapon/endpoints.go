@@ -0,0 +1,270 @@
+package weapon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+	"github.com/wndisra/galactic-svc/internal/helpers"
+)
+
+type Service interface {
+	Create(ctx context.Context, req entity.Weapon) (entity.Weapon, error)
+	GetByID(ctx context.Context, id int64) (entity.Weapon, error)
+	GetAll(ctx context.Context) ([]entity.Weapon, error)
+	Update(ctx context.Context, id int64, patch entity.WeaponPatch) (entity.Weapon, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type CreateRequestModel struct {
+	Name   string  `json:"name" validate:"required,max=100"`
+	Damage int64   `json:"damage" validate:"gte=0"`
+	Range  float64 `json:"range" validate:"gte=0"`
+	Cost   float64 `json:"cost" validate:"gte=0"`
+}
+
+func (r CreateRequestModel) Validate() error {
+	return validate.Validate(r)
+}
+
+func (r CreateRequestModel) ToEntity() entity.Weapon {
+	return entity.Weapon{
+		Name:   r.Name,
+		Damage: r.Damage,
+		Range:  r.Range,
+		Cost:   r.Cost,
+	}
+}
+
+type CreateResponseModel struct {
+	Weapon entity.Weapon
+}
+
+// @BasePath    /
+// Create       godoc
+// @Description Add a weapon to the catalog. Names must be unique once normalized, ignoring case, spaces and punctuation.
+// @Tags        Weapon
+// @Accept      json
+// @Produce     json
+// @Param       request body createRequest true "Request body (JSON)"
+// @Success     201 {object} weaponResponse
+// @Failure     400
+// @Failure     409
+// @Failure     422
+// @Failure     500
+// @Router      /weapon [post]
+func MakeEndpointCreate(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req, ok := request.(CreateRequestModel)
+		if !ok {
+			return nil, errors.New("MakeEndpointCreate(): failed cast request")
+		}
+
+		weapon, err := s.Create(ctx, req.ToEntity())
+		if err != nil {
+			return nil, fmt.Errorf("MakeEndpointCreate(): %w", err)
+		}
+
+		return CreateResponseModel{
+			Weapon: weapon,
+		}, nil
+	}
+}
+
+type GetByIDRequestModel struct {
+	ID int64
+}
+
+type GetByIDResponseModel struct {
+	Weapon entity.Weapon
+}
+
+// @BasePath    /
+// GetByID      godoc
+// @Description Fetch a weapon of the catalog by a specific ID.
+// @Tags        Weapon
+// @Produce     json
+// @Param       id path string true "Weapon ID (integer)"
+// @Success     200 {object} weaponResponse
+// @Failure     400
+// @Failure     404
+// @Failure     500
+// @Router      /weapon/{id} [get]
+func MakeEndpointGetByID(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req, ok := request.(GetByIDRequestModel)
+		if !ok {
+			return nil, errors.New("MakeEndpointGetByID(): failed cast request")
+		}
+
+		weapon, err := s.GetByID(ctx, req.ID)
+		if err != nil {
+			return nil, fmt.Errorf("MakeEndpointGetByID(): %w", err)
+		}
+
+		return GetByIDResponseModel{
+			Weapon: weapon,
+		}, nil
+	}
+}
+
+type GetAllRequestModel struct{}
+
+type GetAllResponseModel struct {
+	Weapons []entity.Weapon
+}
+
+// @BasePath    /
+// GetAll       godoc
+// @Description Get the whole weapon catalog, sorted by name.
+// @Tags        Weapon
+// @Produce     json
+// @Success     200 {array} weaponResponse
+// @Failure     500
+// @Router      /weapon [get]
+func MakeEndpointGetAll(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		_, ok := request.(GetAllRequestModel)
+		if !ok {
+			return nil, errors.New("MakeEndpointGetAll(): failed cast request")
+		}
+
+		weapons, err := s.GetAll(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("MakeEndpointGetAll(): %w", err)
+		}
+
+		return GetAllResponseModel{
+			Weapons: weapons,
+		}, nil
+	}
+}
+
+// UpdateRequestModel is a JSON merge patch (RFC 7396) of a weapon. Nil fields
+// were not sent by the client and are left untouched.
+type UpdateRequestModel struct {
+	ID     int64
+	Name   *string
+	Damage *int64
+	Range  *float64
+	Cost   *float64
+}
+
+// Validate checks the fields present in the patch against the rules of a
+// full weapon, see CreateRequestModel.
+func (r UpdateRequestModel) Validate() error {
+	var full CreateRequestModel
+	var present []string
+
+	if r.Name != nil {
+		full.Name = *r.Name
+		present = append(present, "name")
+	}
+
+	if r.Damage != nil {
+		full.Damage = *r.Damage
+		present = append(present, "damage")
+	}
+
+	if r.Range != nil {
+		full.Range = *r.Range
+		present = append(present, "range")
+	}
+
+	if r.Cost != nil {
+		full.Cost = *r.Cost
+		present = append(present, "cost")
+	}
+
+	return helpers.KeepFieldErrors(full.Validate(), present...)
+}
+
+func (r UpdateRequestModel) ToPatch() entity.WeaponPatch {
+	return entity.WeaponPatch{
+		Name:   r.Name,
+		Damage: r.Damage,
+		Range:  r.Range,
+		Cost:   r.Cost,
+	}
+}
+
+type UpdateResponseModel struct {
+	Weapon entity.Weapon
+}
+
+// @BasePath    /
+// Update       godoc
+// @Description Partially update a weapon of the catalog, following JSON merge patch (RFC 7396) semantics.
+// @Description Armaments referring to the weapon see the change.
+// @Tags        Weapon
+// @Accept      application/merge-patch+json
+// @Accept      json
+// @Produce     json
+// @Param       id      path string        true "Weapon ID (integer)"
+// @Param       request body updateRequest true "Request body (JSON)"
+// @Success     200 {object} weaponResponse
+// @Failure     400
+// @Failure     404
+// @Failure     409
+// @Failure     415
+// @Failure     422
+// @Failure     500
+// @Router      /weapon/{id} [patch]
+func MakeEndpointUpdate(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req, ok := request.(UpdateRequestModel)
+		if !ok {
+			return nil, errors.New("MakeEndpointUpdate(): failed cast request")
+		}
+
+		weapon, err := s.Update(ctx, req.ID, req.ToPatch())
+		if err != nil {
+			return nil, fmt.Errorf("MakeEndpointUpdate(): %w", err)
+		}
+
+		return UpdateResponseModel{
+			Weapon: weapon,
+		}, nil
+	}
+}
+
+type DeleteByIDRequestModel struct {
+	ID int64
+}
+
+type DeleteByIDResponseModel struct {
+	Success bool
+}
+
+// @BasePath    /
+// Delete       godoc
+// @Description Remove a weapon from the catalog, as long as no armament uses it.
+// @Tags        Weapon
+// @Produce     json
+// @Param       id path string true "Weapon ID (integer)"
+// @Success     200
+// @Failure     400
+// @Failure     404
+// @Failure     409
+// @Failure     500
+// @Router      /weapon/{id} [delete]
+func MakeEndpointDeleteByID(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req, ok := request.(DeleteByIDRequestModel)
+		if !ok {
+			return nil, errors.New("MakeEndpointDeleteByID(): failed cast request")
+		}
+
+		err = s.Delete(ctx, req.ID)
+		if err != nil {
+			return nil, fmt.Errorf("MakeEndpointDeleteByID(): %w", err)
+		}
+
+		return DeleteByIDResponseModel{
+			Success: true,
+		}, nil
+	}
+}
@@ -0,0 +1,40 @@
+package weapon
+
+import "github.com/wndisra/galactic-svc/internal/entity"
+
+func formatWeapon(weapon entity.Weapon) weaponResponse {
+	return weaponResponse{
+		ID:     int64(weapon.ID),
+		Name:   weapon.Name,
+		Damage: weapon.Damage,
+		Range:  weapon.Range,
+		Cost:   weapon.Cost,
+	}
+}
+
+func formatCreateResponse(res CreateResponseModel) weaponResponse {
+	return formatWeapon(res.Weapon)
+}
+
+func formatGetByIDResponse(res GetByIDResponseModel) weaponResponse {
+	return formatWeapon(res.Weapon)
+}
+
+func formatGetAllResponse(res GetAllResponseModel) []weaponResponse {
+	weapons := make([]weaponResponse, len(res.Weapons))
+	for i, weapon := range res.Weapons {
+		weapons[i] = formatWeapon(weapon)
+	}
+
+	return weapons
+}
+
+func formatUpdateResponse(res UpdateResponseModel) weaponResponse {
+	return formatWeapon(res.Weapon)
+}
+
+func formatDeleteByIDResponse(res DeleteByIDResponseModel) map[string]interface{} {
+	return map[string]interface{}{
+		"success": res.Success,
+	}
+}
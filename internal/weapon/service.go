@@ -0,0 +1,151 @@
+package weapon
+
+import (
+	"context"
+
+	"github.com/go-kit/log"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+	"github.com/wndisra/galactic-svc/internal/helpers"
+)
+
+var errWeaponNotFound = helpers.NewNotFoundError("weapon not found")
+var errWeaponExists = helpers.NewConflictError("a weapon with the same name already exists")
+var errWeaponInUse = helpers.NewConflictError("weapon is still used by armaments")
+
+type WeaponRepository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	Insert(ctx context.Context, req entity.Weapon) (entity.Weapon, error)
+	GetByID(ctx context.Context, id int64) (entity.Weapon, error)
+	GetByName(ctx context.Context, name string) (entity.Weapon, error)
+	GetAll(ctx context.Context) ([]entity.Weapon, error)
+	Update(ctx context.Context, req entity.Weapon) error
+	Restore(ctx context.Context, req entity.Weapon) error
+	Delete(ctx context.Context, id int64) error
+	CountArmaments(ctx context.Context, id int64) (int64, error)
+}
+
+type service struct {
+	repo   WeaponRepository
+	logger log.Logger
+}
+
+func NewService(repo WeaponRepository, logger log.Logger) *service {
+	return &service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Create adds a weapon to the catalog. Names are compared once normalized,
+// see entity.NormalizeWeaponName(). A deleted weapon of the same name is
+// brought back under its former ID rather than created anew.
+func (s *service) Create(ctx context.Context, req entity.Weapon) (entity.Weapon, error) {
+	var weapon entity.Weapon
+
+	err := s.repo.WithTx(ctx, func(ctx context.Context) error {
+		existing, err := s.repo.GetByName(ctx, req.Name)
+		if err != nil {
+			return err
+		}
+
+		if existing.ID != 0 && !existing.DeletedAt.Valid {
+			return errWeaponExists
+		}
+
+		if existing.ID == 0 {
+			weapon, err = s.repo.Insert(ctx, req)
+			return err
+		}
+
+		req.ID = existing.ID
+		if err := s.repo.Restore(ctx, req); err != nil {
+			return err
+		}
+
+		weapon, err = s.repo.GetByID(ctx, int64(existing.ID))
+		return err
+	})
+	if err != nil {
+		return entity.Weapon{}, err
+	}
+
+	return weapon, nil
+}
+
+func (s *service) GetByID(ctx context.Context, id int64) (entity.Weapon, error) {
+	weapon, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return entity.Weapon{}, err
+	}
+
+	if weapon.ID == 0 {
+		return entity.Weapon{}, errWeaponNotFound
+	}
+
+	return weapon, nil
+}
+
+func (s *service) GetAll(ctx context.Context) ([]entity.Weapon, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *service) Update(ctx context.Context, id int64, patch entity.WeaponPatch) (entity.Weapon, error) {
+	var weapon entity.Weapon
+
+	err := s.repo.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		weapon, err = s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if weapon.ID == 0 {
+			return errWeaponNotFound
+		}
+
+		if patch.Name != nil {
+			existing, err := s.repo.GetByName(ctx, *patch.Name)
+			if err != nil {
+				return err
+			}
+
+			if existing.ID != 0 && existing.ID != weapon.ID {
+				return errWeaponExists
+			}
+		}
+
+		weapon = patch.Apply(weapon)
+		return s.repo.Update(ctx, weapon)
+	})
+	if err != nil {
+		return entity.Weapon{}, err
+	}
+
+	return weapon, nil
+}
+
+// Delete removes a weapon from the catalog, unless armaments still use it.
+func (s *service) Delete(ctx context.Context, id int64) error {
+	return s.repo.WithTx(ctx, func(ctx context.Context) error {
+		weapon, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if weapon.ID == 0 {
+			return errWeaponNotFound
+		}
+
+		count, err := s.repo.CountArmaments(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return errWeaponInUse
+		}
+
+		return s.repo.Delete(ctx, id)
+	})
+}
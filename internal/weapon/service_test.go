@@ -0,0 +1,391 @@
+package weapon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+	mock_repo "github.com/wndisra/galactic-svc/internal/repository/database/mocks/weapon"
+)
+
+func setupMockLogger() kitlog.Logger {
+	return kitlog.NewNopLogger()
+}
+
+// runTx lets a mocked WithTx() call run the given function in place.
+func runTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestNewService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockLogger := setupMockLogger()
+	mockRepo := mock_repo.NewMockWeaponRepository(ctrl)
+
+	expected := &service{
+		repo:   mockRepo,
+		logger: mockLogger,
+	}
+
+	got := NewService(mockRepo, mockLogger)
+	assert.NotNil(t, got)
+	assert.Equal(t, expected, got)
+}
+
+func TestService_Create(t *testing.T) {
+	req := entity.Weapon{Name: "Turbo Laser", Damage: 500, Range: 20.5, Cost: 1000}
+
+	existing := entity.Weapon{Name: "turbo-laser"}
+	existing.ID = 1
+
+	created := req
+	created.ID = 4
+	created.NormalizedName = "turbolaser"
+
+	deleted := entity.Weapon{Name: "turbo-laser"}
+	deleted.ID = 1
+	deleted.DeletedAt = gorm.DeletedAt{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true}
+
+	restored := req
+	restored.ID = 1
+	restored.NormalizedName = "turbolaser"
+
+	tests := []struct {
+		name    string
+		mocks   func(repo *mock_repo.MockWeaponRepository)
+		want    entity.Weapon
+		wantErr error
+	}{
+		{
+			name: "Got GetByName() repo error, should return non-nil error",
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByName(context.Background(), "Turbo Laser").Return(entity.Weapon{}, assert.AnError)
+			},
+			want:    entity.Weapon{},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given name of an existing weapon, should return conflict error",
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByName(context.Background(), "Turbo Laser").Return(existing, nil)
+			},
+			want:    entity.Weapon{},
+			wantErr: errWeaponExists,
+		},
+		{
+			name: "Got Insert() repo error, should return non-nil error",
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByName(context.Background(), "Turbo Laser").Return(entity.Weapon{}, nil)
+				repo.EXPECT().Insert(context.Background(), req).Return(entity.Weapon{}, assert.AnError)
+			},
+			want:    entity.Weapon{},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Got repo success, should return the created weapon with nil error",
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByName(context.Background(), "Turbo Laser").Return(entity.Weapon{}, nil)
+				repo.EXPECT().Insert(context.Background(), req).Return(created, nil)
+			},
+			want:    created,
+			wantErr: nil,
+		},
+		{
+			name: "Got Restore() repo error, should return non-nil error",
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				want := req
+				want.ID = 1
+
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByName(context.Background(), "Turbo Laser").Return(deleted, nil)
+				repo.EXPECT().Restore(context.Background(), want).Return(assert.AnError)
+			},
+			want:    entity.Weapon{},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given name of a deleted weapon, should restore it under its former ID",
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				want := req
+				want.ID = 1
+
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByName(context.Background(), "Turbo Laser").Return(deleted, nil)
+				repo.EXPECT().Restore(context.Background(), want).Return(nil)
+				repo.EXPECT().GetByID(context.Background(), int64(1)).Return(restored, nil)
+			},
+			want:    restored,
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repo.NewMockWeaponRepository(ctrl)
+			mockLogger := setupMockLogger()
+
+			s := &service{
+				repo:   mockRepo,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mockRepo)
+
+			got, err := s.Create(context.Background(), req)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_GetByID(t *testing.T) {
+	weapon := entity.Weapon{Name: "Turbo Laser"}
+	weapon.ID = 1
+
+	tests := []struct {
+		name    string
+		id      int64
+		mocks   func(repo *mock_repo.MockWeaponRepository)
+		want    entity.Weapon
+		wantErr error
+	}{
+		{
+			name: "Got repo error, should return non-nil error",
+			id:   1,
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().GetByID(context.Background(), int64(1)).Return(entity.Weapon{}, assert.AnError)
+			},
+			want:    entity.Weapon{},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Got repo success but not found, should return not found error",
+			id:   3,
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().GetByID(context.Background(), int64(3)).Return(entity.Weapon{}, nil)
+			},
+			want:    entity.Weapon{},
+			wantErr: errWeaponNotFound,
+		},
+		{
+			name: "Got repo success, should return the weapon with nil error",
+			id:   1,
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().GetByID(context.Background(), int64(1)).Return(weapon, nil)
+			},
+			want:    weapon,
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repo.NewMockWeaponRepository(ctrl)
+			mockLogger := setupMockLogger()
+
+			s := &service{
+				repo:   mockRepo,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mockRepo)
+
+			got, err := s.GetByID(context.Background(), tt.id)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_Update(t *testing.T) {
+	weapon := entity.Weapon{Name: "Turbo Laser", NormalizedName: "turbolaser", Damage: 500}
+	weapon.ID = 1
+
+	other := entity.Weapon{Name: "Ion Cannon", NormalizedName: "ioncannon"}
+	other.ID = 2
+
+	deleted := entity.Weapon{Name: "Ion Cannon", NormalizedName: "ioncannon"}
+	deleted.ID = 3
+	deleted.DeletedAt = gorm.DeletedAt{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true}
+
+	renamed := "Ion Cannon"
+	respelled := "Turbo-Laser"
+	damage := int64(0)
+
+	tests := []struct {
+		name    string
+		patch   entity.WeaponPatch
+		mocks   func(repo *mock_repo.MockWeaponRepository)
+		want    entity.Weapon
+		wantErr error
+	}{
+		{
+			name:  "Got GetByID() repo success but not found, should return not found error",
+			patch: entity.WeaponPatch{Damage: &damage},
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(1)).Return(entity.Weapon{}, nil)
+			},
+			want:    entity.Weapon{},
+			wantErr: errWeaponNotFound,
+		},
+		{
+			name:  "Given name of another weapon, should return conflict error",
+			patch: entity.WeaponPatch{Name: &renamed},
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(1)).Return(weapon, nil)
+				repo.EXPECT().GetByName(context.Background(), "Ion Cannon").Return(other, nil)
+			},
+			want:    entity.Weapon{},
+			wantErr: errWeaponExists,
+		},
+		{
+			name:  "Given name of a deleted weapon, should return conflict error",
+			patch: entity.WeaponPatch{Name: &renamed},
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(1)).Return(weapon, nil)
+				repo.EXPECT().GetByName(context.Background(), "Ion Cannon").Return(deleted, nil)
+			},
+			want:    entity.Weapon{},
+			wantErr: errWeaponExists,
+		},
+		{
+			name:  "Given new spelling of its own name, should update the weapon",
+			patch: entity.WeaponPatch{Name: &respelled},
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(1)).Return(weapon, nil)
+				repo.EXPECT().GetByName(context.Background(), "Turbo-Laser").Return(weapon, nil)
+
+				want := weapon
+				want.Name = "Turbo-Laser"
+				repo.EXPECT().Update(context.Background(), want).Return(nil)
+			},
+			want: func() entity.Weapon {
+				want := weapon
+				want.Name = "Turbo-Laser"
+				return want
+			}(),
+			wantErr: nil,
+		},
+		{
+			name:  "Got Update() repo error, should return non-nil error",
+			patch: entity.WeaponPatch{Damage: &damage},
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(1)).Return(weapon, nil)
+
+				want := weapon
+				want.Damage = 0
+				repo.EXPECT().Update(context.Background(), want).Return(assert.AnError)
+			},
+			want:    entity.Weapon{},
+			wantErr: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repo.NewMockWeaponRepository(ctrl)
+			mockLogger := setupMockLogger()
+
+			s := &service{
+				repo:   mockRepo,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mockRepo)
+
+			got, err := s.Update(context.Background(), 1, tt.patch)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_Delete(t *testing.T) {
+	weapon := entity.Weapon{Name: "Turbo Laser"}
+	weapon.ID = 1
+
+	tests := []struct {
+		name    string
+		mocks   func(repo *mock_repo.MockWeaponRepository)
+		wantErr error
+	}{
+		{
+			name: "Got GetByID() repo success but not found, should return not found error",
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(1)).Return(entity.Weapon{}, nil)
+			},
+			wantErr: errWeaponNotFound,
+		},
+		{
+			name: "Given weapon still used by armaments, should return conflict error",
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(1)).Return(weapon, nil)
+				repo.EXPECT().CountArmaments(context.Background(), int64(1)).Return(int64(2), nil)
+			},
+			wantErr: errWeaponInUse,
+		},
+		{
+			name: "Got Delete() repo error, should return non-nil error",
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(1)).Return(weapon, nil)
+				repo.EXPECT().CountArmaments(context.Background(), int64(1)).Return(int64(0), nil)
+				repo.EXPECT().Delete(context.Background(), int64(1)).Return(assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "Given unused weapon, should return nil error",
+			mocks: func(repo *mock_repo.MockWeaponRepository) {
+				repo.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(runTx)
+				repo.EXPECT().GetByID(context.Background(), int64(1)).Return(weapon, nil)
+				repo.EXPECT().CountArmaments(context.Background(), int64(1)).Return(int64(0), nil)
+				repo.EXPECT().Delete(context.Background(), int64(1)).Return(nil)
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repo.NewMockWeaponRepository(ctrl)
+			mockLogger := setupMockLogger()
+
+			s := &service{
+				repo:   mockRepo,
+				logger: mockLogger,
+			}
+
+			tt.mocks(mockRepo)
+
+			err := s.Delete(context.Background(), 1)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package weapon
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/wndisra/galactic-svc/internal/entity"
+	"github.com/wndisra/galactic-svc/internal/tracing"
+)
+
+// tracingService traces the calls to a Service, each in a span carrying the
+// weapon ID it was called with.
+type tracingService struct {
+	next Service
+}
+
+// NewTracingService returns s with its calls traced.
+func NewTracingService(s Service) Service {
+	return tracingService{next: s}
+}
+
+const weaponIDKey = attribute.Key("weapon.id")
+
+func (t tracingService) Create(ctx context.Context, req entity.Weapon) (res entity.Weapon, err error) {
+	ctx, span := tracing.Start(ctx, "weapon.Service.Create")
+	defer func() {
+		span.SetAttributes(weaponIDKey.Int64(int64(res.ID)))
+		tracing.End(span, err)
+	}()
+
+	return t.next.Create(ctx, req)
+}
+
+func (t tracingService) GetByID(ctx context.Context, id int64) (res entity.Weapon, err error) {
+	ctx, span := tracing.Start(ctx, "weapon.Service.GetByID", weaponIDKey.Int64(id))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.GetByID(ctx, id)
+}
+
+func (t tracingService) GetAll(ctx context.Context) (res []entity.Weapon, err error) {
+	ctx, span := tracing.Start(ctx, "weapon.Service.GetAll")
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.GetAll(ctx)
+}
+
+func (t tracingService) Update(ctx context.Context, id int64, patch entity.WeaponPatch) (res entity.Weapon, err error) {
+	ctx, span := tracing.Start(ctx, "weapon.Service.Update", weaponIDKey.Int64(id))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.Update(ctx, id, patch)
+}
+
+func (t tracingService) Delete(ctx context.Context, id int64) (err error) {
+	ctx, span := tracing.Start(ctx, "weapon.Service.Delete", weaponIDKey.Int64(id))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.Delete(ctx, id)
+}
@@ -0,0 +1,243 @@
+package weapon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	ht "github.com/go-kit/kit/transport/http"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/wndisra/galactic-svc/internal/helpers"
+	"github.com/wndisra/galactic-svc/internal/tracing"
+)
+
+func RegisterRoutes(router *httprouter.Router, s Service) {
+	opts := []ht.ServerOption{
+		ht.ServerErrorEncoder(helpers.EncodeError),
+	}
+
+	// Each handler traces its requests in a span of its own, continuing the
+	// trace of the caller.
+	traced := func(name string, opts []ht.ServerOption) []ht.ServerOption {
+		return append(tracing.ServerOptions(name), opts...)
+	}
+
+	createHandler := ht.NewServer(
+		MakeEndpointCreate(s),
+		decodeCreateRequest,
+		encodeCreateResponse,
+		traced("weapon.create", opts)...,
+	)
+
+	getByIDHandler := ht.NewServer(
+		MakeEndpointGetByID(s),
+		decodeGetByIDRequest,
+		encodeGetByIDResponse,
+		traced("weapon.get_by_id", opts)...,
+	)
+
+	getAllHandler := ht.NewServer(
+		MakeEndpointGetAll(s),
+		decodeGetAllRequest,
+		encodeGetAllResponse,
+		traced("weapon.get_all", opts)...,
+	)
+
+	updateHandler := ht.NewServer(
+		MakeEndpointUpdate(s),
+		decodeUpdateRequest,
+		encodeUpdateResponse,
+		traced("weapon.update", opts)...,
+	)
+
+	deleteByIDHandler := ht.NewServer(
+		MakeEndpointDeleteByID(s),
+		decodeDeleteByIDRequest,
+		encodeDeleteByIDResponse,
+		traced("weapon.delete_by_id", opts)...,
+	)
+
+	router.Handler(http.MethodPost, "/weapon", createHandler)
+	router.Handler(http.MethodGet, "/weapon", getAllHandler)
+	router.Handler(http.MethodGet, "/weapon/:id", getByIDHandler)
+	router.Handler(http.MethodPatch, "/weapon/:id", updateHandler)
+	router.Handler(http.MethodDelete, "/weapon/:id", deleteByIDHandler)
+}
+
+type createRequest struct {
+	Name   string  `json:"name"`
+	Damage int64   `json:"damage"`
+	Range  float64 `json:"range"`
+	Cost   float64 `json:"cost"`
+}
+
+type updateRequest struct {
+	Name   *string  `json:"name"`
+	Damage *int64   `json:"damage"`
+	Range  *float64 `json:"range"`
+	Cost   *float64 `json:"cost"`
+}
+
+type weaponResponse struct {
+	ID     int64   `json:"id"`
+	Name   string  `json:"name"`
+	Damage int64   `json:"damage"`
+	Range  float64 `json:"range"`
+	Cost   float64 `json:"cost"`
+}
+
+func decodeCreateRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var req createRequest
+	if err := helpers.DecodeJSON(r.Body, &req); err != nil {
+		return nil, err
+	}
+
+	model := CreateRequestModel(req)
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func encodeCreateResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res, ok := response.(CreateResponseModel)
+	if !ok {
+		return fmt.Errorf("encodeCreateResponse(): failed cast response")
+	}
+
+	formatted := formatCreateResponse(res)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	return json.NewEncoder(w).Encode(formatted)
+}
+
+func decodeGetByIDRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	id, err := decodeIDParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetByIDRequestModel{
+		ID: id,
+	}, nil
+}
+
+func encodeGetByIDResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res, ok := response.(GetByIDResponseModel)
+	if !ok {
+		return fmt.Errorf("encodeGetByIDResponse(): failed cast response")
+	}
+
+	formatted := formatGetByIDResponse(res)
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(formatted)
+}
+
+func decodeGetAllRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	return GetAllRequestModel{}, nil
+}
+
+func encodeGetAllResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res, ok := response.(GetAllResponseModel)
+	if !ok {
+		return fmt.Errorf("encodeGetAllResponse(): failed cast response")
+	}
+
+	formatted := formatGetAllResponse(res)
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(formatted)
+}
+
+func decodeUpdateRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	id, err := decodeIDParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req updateRequest
+	nulls, err := helpers.DecodeMergePatch(r, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Removing an attribute resets it to its zero value.
+	for _, member := range nulls {
+		switch member {
+		case "name":
+			req.Name = new(string)
+		case "damage":
+			req.Damage = new(int64)
+		case "range":
+			req.Range = new(float64)
+		case "cost":
+			req.Cost = new(float64)
+		}
+	}
+
+	model := UpdateRequestModel{
+		ID:     id,
+		Name:   req.Name,
+		Damage: req.Damage,
+		Range:  req.Range,
+		Cost:   req.Cost,
+	}
+
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func encodeUpdateResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res, ok := response.(UpdateResponseModel)
+	if !ok {
+		return fmt.Errorf("encodeUpdateResponse(): failed cast response")
+	}
+
+	formatted := formatUpdateResponse(res)
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(formatted)
+}
+
+func decodeDeleteByIDRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	id, err := decodeIDParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeleteByIDRequestModel{
+		ID: id,
+	}, nil
+}
+
+func encodeDeleteByIDResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res, ok := response.(DeleteByIDResponseModel)
+	if !ok {
+		return fmt.Errorf("encodeDeleteByIDResponse(): failed cast response")
+	}
+
+	formatted := formatDeleteByIDResponse(res)
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(formatted)
+}
+
+func decodeIDParam(ctx context.Context) (int64, error) {
+	params := httprouter.ParamsFromContext(ctx)
+
+	id, err := strconv.ParseInt(params.ByName("id"), 10, 64)
+	if err != nil {
+		return 0, helpers.ErrInvalidPathParam
+	}
+
+	return id, nil
+}
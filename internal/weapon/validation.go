@@ -0,0 +1,5 @@
+package weapon
+
+import "github.com/wndisra/galactic-svc/internal/helpers"
+
+var validate = helpers.NewValidator()
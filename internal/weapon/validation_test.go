@@ -0,0 +1,89 @@
+package weapon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wndisra/galactic-svc/internal/helpers"
+)
+
+func TestCreateRequestModel_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        CreateRequestModel
+		wantFields []helpers.FieldError
+	}{
+		{
+			name:       "Given valid request, should return nil error",
+			req:        CreateRequestModel{Name: "Turbo Laser", Damage: 500, Range: 20.5, Cost: 1000},
+			wantFields: nil,
+		},
+		{
+			name: "Given invalid fields, should report each of them",
+			req:  CreateRequestModel{Damage: -1, Range: -0.5, Cost: -2},
+			wantFields: []helpers.FieldError{
+				{Field: "name", Message: "is required"},
+				{Field: "damage", Message: "must be 0 or greater"},
+				{Field: "range", Message: "must be 0 or greater"},
+				{Field: "cost", Message: "must be 0 or greater"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantFields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var domainErr *helpers.Error
+			assert.True(t, errors.As(err, &domainErr))
+			assert.ErrorIs(t, err, helpers.ErrValidation)
+			assert.Equal(t, tt.wantFields, domainErr.Fields)
+		})
+	}
+}
+
+func TestUpdateRequestModel_Validate(t *testing.T) {
+	empty := ""
+	cost := -1.0
+
+	tests := []struct {
+		name       string
+		req        UpdateRequestModel
+		wantFields []helpers.FieldError
+	}{
+		{
+			name:       "Given empty patch, should return nil error",
+			req:        UpdateRequestModel{ID: 1},
+			wantFields: nil,
+		},
+		{
+			name: "Given invalid fields, should only report the patched ones",
+			req:  UpdateRequestModel{ID: 1, Name: &empty, Cost: &cost},
+			wantFields: []helpers.FieldError{
+				{Field: "name", Message: "is required"},
+				{Field: "cost", Message: "must be 0 or greater"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantFields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var domainErr *helpers.Error
+			assert.True(t, errors.As(err, &domainErr))
+			assert.ErrorIs(t, err, helpers.ErrValidation)
+			assert.Equal(t, tt.wantFields, domainErr.Fields)
+		})
+	}
+}